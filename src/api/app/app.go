@@ -1,8 +1,19 @@
 package app
 
 import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
 	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/stores/blueprint_store"
+	"github.com/dmolina79/golang-github-api/src/api/stores/delivery_store"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
 	"github.com/gin-gonic/gin"
+	"io/ioutil"
 )
 
 var (
@@ -14,6 +25,63 @@ func init() {
 }
 
 func StartApp() {
+	restclient.Configure(restclient.Config{
+		Timeout: config.GetHttpClientTimeout(),
+		Retry: restclient.RetryPolicy{
+			MaxAttempts: config.GetHttpRetryMaxAttempts(),
+			BaseBackoff: config.GetHttpRetryBackoff(),
+		},
+	})
+	if appId := config.GetGithubAppId(); appId != 0 {
+		key, err := ioutil.ReadFile(config.GetGithubAppPrivateKeyFile())
+		if err != nil {
+			panic(err)
+		}
+		if err := github_provider.ConfigureApp(appId, key, config.GetGithubAppInstallationId()); err != nil {
+			panic(err)
+		}
+		log.Info("authenticating as github app", fmt.Sprintf("app_id:%d", appId))
+	}
+	blueprints := blueprint_store.NewMemoryBlueprintStore(nil)
+	if path := config.GetBlueprintsFile(); path != "" {
+		var err error
+		if blueprints, err = blueprint_store.LoadBlueprintStore(path); err != nil {
+			panic(err)
+		}
+		log.Info("repository blueprints loaded", fmt.Sprintf("file:%s", path))
+	}
+	managed := managed_store.NewMemoryManagedRepoStore()
+	if path := config.GetManagedReposDb(); path != "" {
+		var err error
+		if managed, err = managed_store.OpenBoltManagedRepoStore(path); err != nil {
+			panic(err)
+		}
+		log.Info("managed repositories opened", fmt.Sprintf("file:%s", path))
+	}
+	services.RepositoryService = services.NewRepositoryService(blueprints, managed)
+	services.MetadataService = services.NewMetadataService(managed)
+	services.DriftService = services.NewDriftService(managed)
+	deliveries := delivery_store.NewMemoryDeliveryStore()
+	if path := config.GetWebhookDeliveriesDb(); path != "" {
+		var err error
+		if deliveries, err = delivery_store.OpenBoltDeliveryStore(path); err != nil {
+			panic(err)
+		}
+		log.Info("webhook delivery log opened", fmt.Sprintf("file:%s", path))
+	}
+	services.WebhookService = services.NewWebhookService(config.GetGithubWebhookSecret(), deliveries, webhooks.RetryPolicy{
+		MaxAttempts: config.GetWebhookMaxAttempts(),
+		Backoff:     config.GetWebhookRetryBackoff(),
+	})
+	services.WebhookService.Register(github.EventRepository, "managed_repos", services.NewManagedRepoHandler(managed))
+	if interval := config.GetWebhookRetryInterval(); interval > 0 {
+		services.StartWebhookRetries(interval, config.GetWebhookDeliveryRetention())
+		log.Info("webhook retries scheduled", fmt.Sprintf("interval:%s", interval))
+	}
+	if interval := config.GetDriftCheckInterval(); interval > 0 {
+		services.StartDriftChecks(interval)
+		log.Info("drift checks scheduled", fmt.Sprintf("interval:%s", interval))
+	}
 	log.Info("setting up routes...")
 	setupRoutes()
 	log.Info("routes setup completed")
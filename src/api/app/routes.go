@@ -1,12 +1,49 @@
 package app
 
 import (
+	"github.com/dmolina79/golang-github-api/src/api/controllers/access"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/drift"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/hooks"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/jobs"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/metadata"
 	"github.com/dmolina79/golang-github-api/src/api/controllers/polo"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/ratelimit"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/reconcile"
 	"github.com/dmolina79/golang-github-api/src/api/controllers/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/controllers/webhooks"
 )
 
 func setupRoutes() {
 	router.POST("/repo", repositories.CreateRepo)
 	router.POST("/repos", repositories.CreateRepos)
+	router.GET("/repos", repositories.ListRepos)
+	router.GET("/repos/:owner/:name", repositories.GetRepo)
+	router.PATCH("/repos/:owner/:name", repositories.UpdateRepo)
+	router.DELETE("/repos/:owner/:name", repositories.DeleteRepo)
+	router.POST("/repos/:owner/:name/archive", repositories.ArchiveRepo)
+	router.POST("/repos/:owner/:name/forks", repositories.ForkRepo)
+	router.POST("/repos/:owner/:name/seed", repositories.SeedRepo)
+	router.PUT("/repos/:owner/:name/topics", metadata.SetTopics)
+	router.PUT("/repos/:owner/:name/labels", metadata.SyncLabels)
+	router.POST("/repos/:owner/:name/milestones", metadata.CreateMilestones)
+	router.PUT("/repos/:owner/:name/collaborators/:user", access.AddCollaborator)
+	router.DELETE("/repos/:owner/:name/collaborators/:user", access.RemoveCollaborator)
+	router.GET("/repos/:owner/:name/invitations", access.ListInvitations)
+	router.PUT("/repos/:owner/:name/teams/:team", access.SetTeamPermission)
+	router.DELETE("/repos/:owner/:name/teams/:team", access.RemoveTeam)
+	router.POST("/repos/:owner/:name/hooks", hooks.CreateHook)
+	router.GET("/repos/:owner/:name/hooks", hooks.ListHooks)
+	router.PATCH("/repos/:owner/:name/hooks/:id", hooks.UpdateHook)
+	router.DELETE("/repos/:owner/:name/hooks/:id", hooks.DeleteHook)
+	router.POST("/repos/:owner/:name/hooks/:id/pings", hooks.PingHook)
+	router.GET("/jobs/:id", jobs.GetJob)
+	router.DELETE("/jobs/:id", jobs.CancelJob)
+	router.GET("/rate_limit", ratelimit.GetRateLimit)
+	router.POST("/reconcile", reconcile.Reconcile)
+	router.GET("/drift", drift.GetDrift)
+	router.POST("/webhooks/github", webhooks.ReceiveGithub)
+	router.GET("/webhooks/deliveries", webhooks.ListDeliveries)
+	router.GET("/webhooks/deliveries/:id", webhooks.GetDelivery)
+	router.POST("/webhooks/deliveries/:id/replay", webhooks.ReplayDelivery)
 	router.GET("/marco", polo.Marco)
 }
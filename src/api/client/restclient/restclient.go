@@ -2,15 +2,32 @@ package restclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout             = 30 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
 )
 
 var (
 	enabledMocks = false
 	mocks        = make(map[string]*Mock)
+
+	clientMutex sync.RWMutex
+	client      = newClient(DefaultConfig())
+	retryPolicy = DefaultRetryPolicy()
 )
 
 type Mock struct {
@@ -20,6 +37,79 @@ type Mock struct {
 	Err        error
 }
 
+// Config holds the settings of the shared http client used for every outgoing call.
+type Config struct {
+	Timeout             time.Duration
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	Retry               RetryPolicy
+}
+
+type timeoutKey struct{}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:             defaultTimeout,
+		MaxIdleConns:        defaultMaxIdleConns,
+		MaxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     defaultIdleConnTimeout,
+		Retry:               DefaultRetryPolicy(),
+	}
+}
+
+// Configure replaces the shared client. Zero values fall back to the defaults.
+func Configure(cfg Config) {
+	defaults := DefaultConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = defaults.IdleConnTimeout
+	}
+
+	clientMutex.Lock()
+	client = newClient(cfg)
+	retryPolicy = cfg.Retry.withDefaults()
+	clientMutex.Unlock()
+}
+
+func newClient(cfg Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+	}
+}
+
+func getClient() *http.Client {
+	clientMutex.RLock()
+	defer clientMutex.RUnlock()
+	return client
+}
+
+func getRetryPolicy() RetryPolicy {
+	clientMutex.RLock()
+	defer clientMutex.RUnlock()
+	return retryPolicy
+}
+
+// WithTimeout returns a context that limits the next call made with it to the given
+// duration, on top of the global client timeout.
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
 func getMockId(httpMethod string, url string) string {
 	return fmt.Sprintf("%s %s", httpMethod, url)
 }
@@ -40,9 +130,39 @@ func AddMockUp(m Mock) {
 	mocks[getMockId(m.HttpMethod, m.Url)] = &m
 }
 
-func Post(url string, body interface{}, headers http.Header) (*http.Response, error) {
+func Get(ctx context.Context, url string, headers http.Header) (*http.Response, error) {
+	return Do(ctx, http.MethodGet, url, nil, headers)
+}
+
+func Post(ctx context.Context, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return Do(ctx, http.MethodPost, url, body, headers)
+}
+
+func Put(ctx context.Context, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return Do(ctx, http.MethodPut, url, body, headers)
+}
+
+func Patch(ctx context.Context, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return Do(ctx, http.MethodPatch, url, body, headers)
+}
+
+func Delete(ctx context.Context, url string, headers http.Header) (*http.Response, error) {
+	return Do(ctx, http.MethodDelete, url, nil, headers)
+}
+
+// Do sends body encoded as json (when not nil) using the shared client, retrying
+// transient failures according to the configured RetryPolicy. The call is cancelled
+// as soon as ctx is done.
+func Do(ctx context.Context, httpMethod string, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	if enabledMocks {
-		mock := mocks[getMockId(http.MethodPost, url)]
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mock := mocks[getMockId(httpMethod, url)]
 		if mock == nil {
 			return nil, errors.New("no mockup found for given url request")
 		}
@@ -50,15 +170,95 @@ func Post(url string, body interface{}, headers http.Header) (*http.Response, er
 		return mock.Response, mock.Err
 	}
 
-	jsonBytes, err := json.Marshal(body)
+	var jsonBytes []byte
+	if body != nil {
+		var err error
+		if jsonBytes, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	policy := getRetryPolicy()
+	idempotent := isIdempotent(ctx, httpMethod)
 
+	for attempt := 1; ; attempt++ {
+		response, err := send(ctx, httpMethod, url, jsonBytes, headers)
+
+		if attempt < policy.MaxAttempts && ctx.Err() == nil {
+			retry := false
+			if err != nil {
+				retry = policy.shouldRetryError(err, idempotent)
+			} else {
+				retry = policy.shouldRetryResponse(response, idempotent)
+			}
+
+			if retry {
+				wait := policy.backoff(attempt, response)
+				status := "error"
+				if response != nil {
+					status = strconv.Itoa(response.StatusCode)
+					discard(response)
+				}
+				log.Info("retrying request", fmt.Sprintf("method:%s", httpMethod), fmt.Sprintf("attempt:%d", attempt), fmt.Sprintf("status:%s", status), fmt.Sprintf("wait:%s", wait))
+
+				if err := sleep(ctx, wait); err != nil {
+					cancel()
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			if attempt > 1 {
+				log.Error("request failed after retries", err, fmt.Sprintf("method:%s", httpMethod), fmt.Sprintf("attempts:%d", attempt))
+			}
+			cancel()
+			return nil, err
+		}
+
+		if attempt > 1 {
+			log.Info("request completed after retries", fmt.Sprintf("method:%s", httpMethod), fmt.Sprintf("attempts:%d", attempt), fmt.Sprintf("status:%d", response.StatusCode))
+		}
+
+		// the per call timeout must outlive Do so the caller can still read the body
+		response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+		return response, nil
+	}
+}
+
+func send(ctx context.Context, httpMethod string, url string, jsonBytes []byte, headers http.Header) (*http.Response, error) {
+	var reader io.Reader
+	if jsonBytes != nil {
+		reader = bytes.NewReader(jsonBytes)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, httpMethod, url, reader)
 	if err != nil {
 		return nil, err
 	}
-	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(jsonBytes))
-	request.Header = headers
+	if headers != nil {
+		request.Header = headers.Clone()
+	}
+	if reader != nil && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
 
-	client := http.Client{}
+	return getClient().Do(request)
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
 
-	return client.Do(request)
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
 }
@@ -0,0 +1,109 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDoSendsJsonBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.EqualValues(t, http.MethodPatch, r.Method)
+		assert.EqualValues(t, "application/json", r.Header.Get("Content-Type"))
+		assert.EqualValues(t, "token abc", r.Header.Get("Authorization"))
+		assert.EqualValues(t, `{"name":"repo"}`, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("Authorization", "token abc")
+	resp, err := Patch(context.Background(), server.URL, map[string]string{"name": "repo"}, headers)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestDoWithoutBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, http.MethodGet, r.Method)
+		assert.EqualValues(t, "", r.Header.Get("Content-Type"))
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	resp, err := Get(context.Background(), server.URL, nil)
+
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.EqualValues(t, "ok", string(body))
+}
+
+func TestDoPerCallTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx := WithTimeout(context.Background(), 10*time.Millisecond)
+	resp, err := Get(ctx, server.URL, nil)
+
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+}
+
+func TestDoCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := Delete(ctx, "http://localhost", nil)
+
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestDoInvalidUrl(t *testing.T) {
+	resp, err := Get(context.Background(), "://bad-url", nil)
+
+	assert.Nil(t, resp)
+	assert.NotNil(t, err)
+}
+
+func TestDoMockups(t *testing.T) {
+	StartMockups()
+	defer StopMockups()
+	FlushMockups()
+	AddMockUp(Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: http.StatusNoContent},
+	})
+
+	resp, err := Put(context.Background(), "https://api.github.com/user/repos", nil, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = Get(context.Background(), "https://api.github.com/user/repos", nil)
+	assert.Nil(t, resp)
+	assert.EqualValues(t, "no mockup found for given url request", err.Error())
+}
+
+func TestConfigureDefaults(t *testing.T) {
+	Configure(Config{Timeout: 5 * time.Second})
+	defer Configure(DefaultConfig())
+
+	c := getClient()
+	assert.EqualValues(t, 5*time.Second, c.Timeout)
+	assert.EqualValues(t, defaultMaxIdleConnsPerHost, c.Transport.(*http.Transport).MaxIdleConnsPerHost)
+}
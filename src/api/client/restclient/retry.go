@@ -0,0 +1,172 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxAttempts   = 3
+	defaultBaseBackoff   = 200 * time.Millisecond
+	defaultMaxBackoff    = 5 * time.Second
+	defaultJitter        = 0.2
+	defaultMaxRetryAfter = 60 * time.Second
+)
+
+// RetryPolicy decides if and when a failed call is sent again.
+type RetryPolicy struct {
+	MaxAttempts       int
+	BaseBackoff       time.Duration
+	MaxBackoff        time.Duration
+	Jitter            float64
+	MaxRetryAfter     time.Duration
+	RetryableStatuses map[int]bool
+}
+
+type idempotentKey struct{}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   defaultMaxAttempts,
+		BaseBackoff:   defaultBaseBackoff,
+		MaxBackoff:    defaultMaxBackoff,
+		Jitter:        defaultJitter,
+		MaxRetryAfter: defaultMaxRetryAfter,
+		RetryableStatuses: map[int]bool{
+			http.StatusTooManyRequests:    true,
+			http.StatusBadGateway:         true,
+			http.StatusServiceUnavailable: true,
+			http.StatusGatewayTimeout:     true,
+		},
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.BaseBackoff <= 0 {
+		p.BaseBackoff = defaults.BaseBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = defaults.Jitter
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = defaults.MaxRetryAfter
+	}
+	if p.RetryableStatuses == nil {
+		p.RetryableStatuses = defaults.RetryableStatuses
+	}
+	return p
+}
+
+// WithIdempotent marks the next call made with ctx as safe to repeat even if its
+// http method is not idempotent, e.g. a POST guarded by an idempotency key.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(ctx context.Context, httpMethod string) bool {
+	switch httpMethod {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	marked, _ := ctx.Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// shouldRetryError reports if err is transient. Requests that may have reached github
+// are only repeated when idempotent; a failed dial never left this host so it is always safe.
+func (p RetryPolicy) shouldRetryError(err error, idempotent bool) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// shouldRetryResponse reports if resp is transient. Non idempotent requests are only
+// repeated when github explicitly told us to come back later, meaning it did not process them.
+func (p RetryPolicy) shouldRetryResponse(resp *http.Response, idempotent bool) bool {
+	if !p.RetryableStatuses[resp.StatusCode] {
+		return false
+	}
+	if idempotent {
+		return true
+	}
+	return resp.Header.Get("Retry-After") != "" &&
+		(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable)
+}
+
+// backoff returns the wait before the given attempt (1 based), honoring Retry-After when present.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	wait := p.BaseBackoff << uint(attempt-1)
+	if wait <= 0 || wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delta := int64(float64(wait) * p.Jitter)
+		if delta > 0 {
+			wait += time.Duration(rand.Int63n(2*delta+1) - delta)
+		}
+	}
+
+	if resp != nil {
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > wait {
+			wait = retryAfter
+			if wait > p.MaxRetryAfter {
+				wait = p.MaxRetryAfter
+			}
+		}
+	}
+	return wait
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func discard(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,130 @@
+package restclient
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetries(t *testing.T) {
+	Configure(Config{Retry: RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}})
+	t.Cleanup(func() { Configure(DefaultConfig()) })
+}
+
+func TestRetryIdempotentOnBadGateway(t *testing.T) {
+	fastRetries(t)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := Get(context.Background(), server.URL, nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	fastRetries(t)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	resp, err := Delete(context.Background(), server.URL, nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestRetryPostNotRetriedWithoutRetryAfter(t *testing.T) {
+	fastRetries(t)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := Post(context.Background(), server.URL, map[string]string{}, nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadGateway, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRetryPostWithRetryAfter(t *testing.T) {
+	fastRetries(t)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	resp, err := Post(context.Background(), server.URL, map[string]string{}, nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestRetryPostMarkedIdempotent(t *testing.T) {
+	fastRetries(t)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	resp, err := Post(WithIdempotent(context.Background()), server.URL, map[string]string{}, nil)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, MaxRetryAfter: 10 * time.Second}
+
+	assert.EqualValues(t, 100*time.Millisecond, policy.backoff(1, nil))
+	assert.EqualValues(t, 400*time.Millisecond, policy.backoff(3, nil))
+	assert.EqualValues(t, time.Second, policy.backoff(10, nil))
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "5")
+	assert.EqualValues(t, 5*time.Second, policy.backoff(1, resp))
+
+	resp.Header.Set("Retry-After", "120")
+	assert.EqualValues(t, 10*time.Second, policy.backoff(1, resp))
+}
+
+func TestRetryBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 20; i++ {
+		wait := policy.backoff(1, nil)
+		assert.True(t, wait >= 50*time.Millisecond && wait <= 150*time.Millisecond)
+	}
+}
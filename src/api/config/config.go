@@ -1,9 +1,17 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -11,11 +19,75 @@ const (
 	LogLevel             = "LOG_LEVEL"
 	goEnvironment        = "GO_ENVIRONMENT"
 	production           = "production"
+	httpClientTimeout    = "HTTP_CLIENT_TIMEOUT"
+	githubRequestTimeout = "GITHUB_REQUEST_TIMEOUT"
+	httpRetryMaxAttempts = "HTTP_RETRY_MAX_ATTEMPTS"
+	httpRetryBackoff     = "HTTP_RETRY_BACKOFF"
+	githubRateLimitWait  = "GITHUB_RATE_LIMIT_MAX_WAIT"
+	batchMaxSize         = "BATCH_MAX_SIZE"
+	batchMaxConcurrency  = "BATCH_MAX_CONCURRENCY"
+	batchItemDelay       = "BATCH_ITEM_DELAY"
+	forkWaitTimeout      = "FORK_WAIT_TIMEOUT"
+	jobRetention         = "JOB_RETENTION"
+	blueprintsFile       = "BLUEPRINTS_FILE"
+	driftCheckInterval   = "DRIFT_CHECK_INTERVAL"
+	managedReposDb       = "MANAGED_REPOS_DB"
+	seedTemplatesDir     = "SEED_TEMPLATES_DIR"
+	ciWebhookUrl         = "CI_WEBHOOK_URL"
+	ciWebhookSecret      = "CI_WEBHOOK_SECRET"
+	ciWebhookEvents      = "CI_WEBHOOK_EVENTS"
+	githubWebhookSecret  = "GITHUB_WEBHOOK_SECRET"
+	webhookDeliveriesDb  = "WEBHOOK_DELIVERIES_DB"
+	webhookMaxAttempts   = "WEBHOOK_MAX_ATTEMPTS"
+	webhookRetryBackoff  = "WEBHOOK_RETRY_BACKOFF"
+	webhookRetryInterval = "WEBHOOK_RETRY_INTERVAL"
+	webhookRetention     = "WEBHOOK_DELIVERY_RETENTION"
+	githubAppId          = "GITHUB_APP_ID"
+	githubAppKeyFile     = "GITHUB_APP_PRIVATE_KEY_FILE"
+	githubAppInstall     = "GITHUB_APP_INSTALLATION_ID"
+
+	defaultRateLimitWait       = 30 * time.Second
+	defaultForkWaitTimeout     = 60 * time.Second
+	defaultJobRetention        = 24 * time.Hour
+	defaultDriftCheckInterval  = time.Hour
+	defaultBatchMaxSize        = 100
+	defaultBatchMaxConcurrency = 5
+	defaultCiWebhookEvents     = "push,pull_request"
+	defaultWebhookMaxAttempts  = 5
+	defaultWebhookBackoff      = time.Minute
+	defaultWebhookRetryTick    = 30 * time.Second
+	defaultWebhookRetention    = 7 * 24 * time.Hour
 )
 
 var (
 	githubAccessToken string
 	logLevel          string
+	clientTimeout     time.Duration
+	requestTimeout    time.Duration
+	retryMaxAttempts  int
+	retryBackoff      time.Duration
+	rateLimitMaxWait  time.Duration
+	maxBatchSize      int
+	maxConcurrency    int
+	itemDelay         time.Duration
+	forkWait          time.Duration
+	jobsRetention     time.Duration
+	blueprintsPath    string
+	driftInterval     time.Duration
+	managedPath       string
+	seedTemplatesPath string
+	ciHookUrl         string
+	ciHookSecret      string
+	ciHookEvents      []string
+	webhookSecret     string
+	deliveriesPath    string
+	hookMaxAttempts   int
+	hookRetryBackoff  time.Duration
+	hookRetryInterval time.Duration
+	deliveryRetention time.Duration
+	appId             int64
+	appKeyPath        string
+	appInstallationId int64
 )
 
 func init() {
@@ -26,6 +98,76 @@ func init() {
 
 	githubAccessToken = os.Getenv(apiGithubAccessToken)
 	logLevel = os.Getenv(LogLevel)
+	clientTimeout = getDuration(httpClientTimeout, 0)
+	requestTimeout = getDuration(githubRequestTimeout, 0)
+	retryMaxAttempts = getInt(httpRetryMaxAttempts, 0)
+	retryBackoff = getDuration(httpRetryBackoff, 0)
+	rateLimitMaxWait = getDuration(githubRateLimitWait, defaultRateLimitWait)
+	maxBatchSize = getInt(batchMaxSize, defaultBatchMaxSize)
+	maxConcurrency = getInt(batchMaxConcurrency, defaultBatchMaxConcurrency)
+	itemDelay = getDuration(batchItemDelay, 0)
+	forkWait = getDuration(forkWaitTimeout, defaultForkWaitTimeout)
+	jobsRetention = getDuration(jobRetention, defaultJobRetention)
+	blueprintsPath = os.Getenv(blueprintsFile)
+	driftInterval = getDuration(driftCheckInterval, defaultDriftCheckInterval)
+	managedPath = os.Getenv(managedReposDb)
+	seedTemplatesPath = os.Getenv(seedTemplatesDir)
+	ciHookUrl = os.Getenv(ciWebhookUrl)
+	ciHookSecret = os.Getenv(ciWebhookSecret)
+	ciHookEvents = getList(ciWebhookEvents, defaultCiWebhookEvents)
+	webhookSecret = os.Getenv(githubWebhookSecret)
+	deliveriesPath = os.Getenv(webhookDeliveriesDb)
+	hookMaxAttempts = getInt(webhookMaxAttempts, defaultWebhookMaxAttempts)
+	hookRetryBackoff = getDuration(webhookRetryBackoff, defaultWebhookBackoff)
+	hookRetryInterval = getDuration(webhookRetryInterval, defaultWebhookRetryTick)
+	deliveryRetention = getDuration(webhookRetention, defaultWebhookRetention)
+	appId = int64(getInt(githubAppId, 0))
+	appKeyPath = os.Getenv(githubAppKeyFile)
+	appInstallationId = int64(getInt(githubAppInstall, 0))
+}
+
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid number for %s: %s", key, value)
+		return defaultValue
+	}
+	return number
+}
+
+// getList splits the comma separated value of key, dropping the empty items.
+func getList(key string, defaultValue string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		value = defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %s", key, value)
+		return defaultValue
+	}
+	return duration
 }
 
 func GetGithubAccessToken() string {
@@ -36,6 +178,164 @@ func GetLogLevel() string {
 	return logLevel
 }
 
+// GetHttpClientTimeout is the global timeout for every outgoing call, zero means the client default.
+func GetHttpClientTimeout() time.Duration {
+	return clientTimeout
+}
+
+// GetGithubRequestTimeout is the timeout applied to each single call to github, zero means none.
+func GetGithubRequestTimeout() time.Duration {
+	return requestTimeout
+}
+
+// GetHttpRetryMaxAttempts is the max number of attempts per outgoing call, zero means the client default.
+func GetHttpRetryMaxAttempts() int {
+	return retryMaxAttempts
+}
+
+// GetHttpRetryBackoff is the wait before the first retry, zero means the client default.
+func GetHttpRetryBackoff() time.Duration {
+	return retryBackoff
+}
+
+// GetGithubRateLimitMaxWait is how long a call may be paused waiting for the github
+// rate limit to reset before failing with 429.
+func GetGithubRateLimitMaxWait() time.Duration {
+	return rateLimitMaxWait
+}
+
+// GetBatchMaxSize is the max number of repositories accepted in a single batch.
+func GetBatchMaxSize() int {
+	return maxBatchSize
+}
+
+// GetBatchMaxConcurrency is the max number of repositories of a batch created at the same time.
+func GetBatchMaxConcurrency() int {
+	return maxConcurrency
+}
+
+// GetBatchItemDelay is the pause each batch worker takes between two repositories.
+func GetBatchItemDelay() time.Duration {
+	return itemDelay
+}
+
+// GetForkWaitTimeout is how long a fork request asking to wait polls github until the fork is ready.
+func GetForkWaitTimeout() time.Duration {
+	return forkWait
+}
+
+// GetJobRetention is how long the finished batch jobs can still be read, zero keeping
+// them until restart.
+func GetJobRetention() time.Duration {
+	return jobsRetention
+}
+
+// GetBlueprintsFile is the path of the yaml or json file declaring the repository
+// blueprints, empty when there are none.
+func GetBlueprintsFile() string {
+	return blueprintsPath
+}
+
+// GetDriftCheckInterval is the pause between two drift checks of the managed
+// repositories, zero disables the periodic checks.
+func GetDriftCheckInterval() time.Duration {
+	return driftInterval
+}
+
+// GetManagedReposDb is the path of the database recording the desired state of the
+// managed repositories, empty keeping it in memory only, lost on restart.
+func GetManagedReposDb() string {
+	return managedPath
+}
+
+// GetSeedTemplatesDir is the directory holding a sub directory per seed template,
+// empty when there are none.
+func GetSeedTemplatesDir() string {
+	return seedTemplatesPath
+}
+
+// GetCiWebhookUrl is where the CI webhook registered on new repositories delivers,
+// empty when no CI webhook is registered.
+func GetCiWebhookUrl() string {
+	return ciHookUrl
+}
+
+func GetCiWebhookSecret() string {
+	return ciHookSecret
+}
+
+func GetCiWebhookEvents() []string {
+	return ciHookEvents
+}
+
+// GetGithubWebhookSecret is the secret github signs the deliveries to /webhooks/github
+// with, no delivery is accepted while it is empty.
+func GetGithubWebhookSecret() string {
+	return webhookSecret
+}
+
+// GetWebhookDeliveriesDb is the path of the database logging the webhook deliveries,
+// empty keeping them in memory only.
+func GetWebhookDeliveriesDb() string {
+	return deliveriesPath
+}
+
+// GetWebhookMaxAttempts is how many attempts a failed delivery gets before it is dead
+// lettered.
+func GetWebhookMaxAttempts() int {
+	return hookMaxAttempts
+}
+
+// GetWebhookRetryBackoff is the pause before the first retry of a failed delivery,
+// doubled on each following retry.
+func GetWebhookRetryBackoff() time.Duration {
+	return hookRetryBackoff
+}
+
+// GetWebhookRetryInterval is how often the failed deliveries due are retried, zero
+// disables the retries.
+func GetWebhookRetryInterval() time.Duration {
+	return hookRetryInterval
+}
+
+// GetWebhookDeliveryRetention is how long the deliveries stay in the log.
+func GetWebhookDeliveryRetention() time.Duration {
+	return deliveryRetention
+}
+
+// GetGithubAppId is the id of the github app this api authenticates as, zero when it
+// uses the personal access token only.
+func GetGithubAppId() int64 {
+	return appId
+}
+
+// GetGithubAppPrivateKeyFile is the path of the pem private key of the github app.
+func GetGithubAppPrivateKeyFile() string {
+	return appKeyPath
+}
+
+// GetGithubAppInstallationId is the installation used for the calls that do not target
+// an owner, such as the rate limit, zero when there is none.
+func GetGithubAppInstallationId() int64 {
+	return appInstallationId
+}
+
+// LoadFile decodes the yaml (.yaml, .yml) or json (.json) file at path into target.
+func LoadFile(path string, target interface{}) error {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(bytes, target)
+	case ".json":
+		return json.Unmarshal(bytes, target)
+	}
+	return fmt.Errorf("unsupported config file format %s", path)
+}
+
 func IsProduction() bool {
 	return os.Getenv(goEnvironment) == production
 }
@@ -0,0 +1,78 @@
+package access
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AddCollaborator answers 201 when the user was invited and 200 when it got access right away.
+func AddCollaborator(c *gin.Context) {
+	var request repositories.CollaboratorRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			apiErr := errors.NewBadRequestError("invalid json body")
+			http_utils.RespondError(c, apiErr)
+			return
+		}
+	}
+
+	res, err := services.AccessService.AddCollaborator(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("user"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	if res.Status == repositories.CollaboratorInvited {
+		c.JSON(http.StatusCreated, res)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func RemoveCollaborator(c *gin.Context) {
+	if err := services.AccessService.RemoveCollaborator(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("user")); err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func ListInvitations(c *gin.Context) {
+	res, err := services.AccessService.ListInvitations(c.Request.Context(), c.Param("owner"), c.Param("name"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func SetTeamPermission(c *gin.Context) {
+	var request repositories.TeamPermissionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	if err := services.AccessService.SetTeamPermission(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("team"), request); err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func RemoveTeam(c *gin.Context) {
+	if err := services.AccessService.RemoveTeam(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("team")); err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,114 @@
+package access
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type accessServiceMock struct {
+	collaborator *repositories.CollaboratorResponse
+	invitations  []repositories.Invitation
+	err          errors.ApiError
+	permission   string
+}
+
+func (m *accessServiceMock) AddCollaborator(ctx context.Context, owner string, name string, user string, request repositories.CollaboratorRequest) (*repositories.CollaboratorResponse, errors.ApiError) {
+	m.permission = request.Permission
+	return m.collaborator, m.err
+}
+
+func (m *accessServiceMock) RemoveCollaborator(ctx context.Context, owner string, name string, user string) errors.ApiError {
+	return m.err
+}
+
+func (m *accessServiceMock) ListInvitations(ctx context.Context, owner string, name string) ([]repositories.Invitation, errors.ApiError) {
+	return m.invitations, m.err
+}
+
+func (m *accessServiceMock) SetTeamPermission(ctx context.Context, owner string, name string, team string, request repositories.TeamPermissionRequest) errors.ApiError {
+	m.permission = request.Permission
+	return m.err
+}
+
+func (m *accessServiceMock) RemoveTeam(ctx context.Context, owner string, name string, team string) errors.ApiError {
+	return m.err
+}
+
+func TestAddCollaborator_Invited(t *testing.T) {
+	mockService := &accessServiceMock{collaborator: &repositories.CollaboratorResponse{User: "octocat", Permission: "pull", Status: repositories.CollaboratorInvited, InvitationId: 7}}
+	services.AccessService = mockService
+
+	request, _ := http.NewRequest(http.MethodPut, "/repos/my-org/my-repo/collaborators/octocat", strings.NewReader(`{"permission": "pull"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "my-repo"}, {Key: "user", Value: "octocat"}}
+
+	AddCollaborator(c)
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	assert.EqualValues(t, "pull", mockService.permission)
+	var result repositories.CollaboratorResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 7, result.InvitationId)
+}
+
+func TestAddCollaborator_AddedWithoutBody(t *testing.T) {
+	services.AccessService = &accessServiceMock{collaborator: &repositories.CollaboratorResponse{User: "octocat", Permission: "push", Status: repositories.CollaboratorAdded}}
+
+	request, _ := http.NewRequest(http.MethodPut, "/repos/my-org/my-repo/collaborators/octocat", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	AddCollaborator(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+}
+
+func TestRemoveCollaborator_Error(t *testing.T) {
+	services.AccessService = &accessServiceMock{err: errors.NewNotFoundError("Not Found")}
+
+	request, _ := http.NewRequest(http.MethodDelete, "/repos/my-org/my-repo/collaborators/octocat", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	RemoveCollaborator(c)
+
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+}
+
+func TestSetTeamPermission_InvalidJson(t *testing.T) {
+	services.AccessService = &accessServiceMock{}
+
+	request, _ := http.NewRequest(http.MethodPut, "/repos/my-org/my-repo/teams/platform", strings.NewReader(``))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	SetTeamPermission(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestSetTeamPermission_Success(t *testing.T) {
+	mockService := &accessServiceMock{}
+	services.AccessService = mockService
+
+	request, _ := http.NewRequest(http.MethodPut, "/repos/my-org/my-repo/teams/platform", strings.NewReader(`{"permission": "maintain"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	SetTeamPermission(c)
+
+	assert.EqualValues(t, http.StatusNoContent, c.Writer.Status())
+	assert.EqualValues(t, "maintain", mockService.permission)
+}
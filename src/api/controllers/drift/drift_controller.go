@@ -0,0 +1,19 @@
+package drift
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetDrift returns the last drift report, refresh=true checks every managed repository first.
+func GetDrift(c *gin.Context) {
+	res, err := services.DriftService.GetDrift(c.Request.Context(), c.Query("refresh") == "true")
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
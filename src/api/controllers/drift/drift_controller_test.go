@@ -0,0 +1,65 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type driftServiceMock struct {
+	refresh bool
+	report  *drift.Report
+	err     errors.ApiError
+}
+
+func (m *driftServiceMock) CheckDrift(ctx context.Context) (*drift.Report, errors.ApiError) {
+	return m.report, m.err
+}
+
+func (m *driftServiceMock) GetDrift(ctx context.Context, refresh bool) (*drift.Report, errors.ApiError) {
+	m.refresh = refresh
+	return m.report, m.err
+}
+
+func TestGetDrift_Success(t *testing.T) {
+	mockService := &driftServiceMock{report: &drift.Report{
+		Summary: drift.Summary{Total: 1, Drifted: 1},
+		Repos:   []drift.RepoDrift{{Owner: "my-org", Name: "api", Drifted: true}},
+	}}
+	services.DriftService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/drift?refresh=true", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	GetDrift(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.True(t, mockService.refresh)
+	var result drift.Report
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Summary.Drifted)
+	assert.EqualValues(t, "api", result.Repos[0].Name)
+}
+
+func TestGetDrift_Error(t *testing.T) {
+	mockService := &driftServiceMock{err: errors.NewInternalServerError("store unavailable")}
+	services.DriftService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/drift", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	GetDrift(c)
+
+	assert.EqualValues(t, http.StatusInternalServerError, response.Code)
+	assert.False(t, mockService.refresh)
+}
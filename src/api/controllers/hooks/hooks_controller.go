@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func CreateHook(c *gin.Context) {
+	var request repositories.HookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.HooksService.CreateHook(c.Request.Context(), c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func ListHooks(c *gin.Context) {
+	res, err := services.HooksService.ListHooks(c.Request.Context(), c.Param("owner"), c.Param("name"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func UpdateHook(c *gin.Context) {
+	var request repositories.UpdateHookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.HooksService.UpdateHook(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("id"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// PingHook answers 204 as soon as github accepted the ping, the delivery is async.
+func PingHook(c *gin.Context) {
+	if err := services.HooksService.PingHook(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("id")); err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func DeleteHook(c *gin.Context) {
+	if err := services.HooksService.DeleteHook(c.Request.Context(), c.Param("owner"), c.Param("name"), c.Param("id")); err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
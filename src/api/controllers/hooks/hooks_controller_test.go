@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type hooksServiceMock struct {
+	hook   *repositories.Webhook
+	hooks  []repositories.Webhook
+	err    errors.ApiError
+	hookId string
+}
+
+func (m *hooksServiceMock) CreateHook(ctx context.Context, owner string, name string, request repositories.HookRequest) (*repositories.Webhook, errors.ApiError) {
+	return m.hook, m.err
+}
+
+func (m *hooksServiceMock) ListHooks(ctx context.Context, owner string, name string) ([]repositories.Webhook, errors.ApiError) {
+	return m.hooks, m.err
+}
+
+func (m *hooksServiceMock) UpdateHook(ctx context.Context, owner string, name string, hookId string, request repositories.UpdateHookRequest) (*repositories.Webhook, errors.ApiError) {
+	m.hookId = hookId
+	return m.hook, m.err
+}
+
+func (m *hooksServiceMock) PingHook(ctx context.Context, owner string, name string, hookId string) errors.ApiError {
+	m.hookId = hookId
+	return m.err
+}
+
+func (m *hooksServiceMock) DeleteHook(ctx context.Context, owner string, name string, hookId string) errors.ApiError {
+	m.hookId = hookId
+	return m.err
+}
+
+func newContext(method string, url string, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	request, _ := http.NewRequest(method, url, strings.NewReader(body))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = params
+	return c, response
+}
+
+func TestCreateHook_InvalidJsonRequest(t *testing.T) {
+	c, response := newContext(http.MethodPost, "/repos/my-org/my-repo/hooks", ``, nil)
+
+	CreateHook(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestCreateHook_Created(t *testing.T) {
+	services.HooksService = &hooksServiceMock{hook: &repositories.Webhook{Id: 7, Url: "https://ci.example.com/hook", Secret: "generated"}}
+	c, response := newContext(http.MethodPost, "/repos/my-org/my-repo/hooks", `{"url": "https://ci.example.com/hook", "generate_secret": true}`,
+		gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "my-repo"}})
+
+	CreateHook(c)
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	var result repositories.Webhook
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.EqualValues(t, "generated", result.Secret)
+}
+
+func TestPingHook_NoContent(t *testing.T) {
+	mock := &hooksServiceMock{}
+	services.HooksService = mock
+	c, _ := newContext(http.MethodPost, "/repos/my-org/my-repo/hooks/7/pings", ``,
+		gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "my-repo"}, {Key: "id", Value: "7"}})
+
+	PingHook(c)
+
+	assert.EqualValues(t, http.StatusNoContent, c.Writer.Status())
+	assert.EqualValues(t, "7", mock.hookId)
+}
+
+func TestDeleteHook_NotFound(t *testing.T) {
+	services.HooksService = &hooksServiceMock{err: errors.NewNotFoundError("Not Found")}
+	c, response := newContext(http.MethodDelete, "/repos/my-org/my-repo/hooks/7", ``,
+		gin.Params{{Key: "owner", Value: "my-org"}, {Key: "name", Value: "my-repo"}, {Key: "id", Value: "7"}})
+
+	DeleteHook(c)
+
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+}
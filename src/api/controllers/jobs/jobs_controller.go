@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetJob(c *gin.Context) {
+	job, err := services.JobsService.GetJob(c.Param("id"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func CancelJob(c *gin.Context) {
+	job, err := services.JobsService.CancelJob(c.Param("id"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
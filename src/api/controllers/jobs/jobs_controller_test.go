@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/jobs"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type jobsServiceMock struct {
+	job *jobs.Job
+	err errors.ApiError
+}
+
+func (m *jobsServiceMock) CreateReposJob(request []repositories.CreateRepoRequest, atomic bool) (*jobs.Job, errors.ApiError) {
+	return m.job, m.err
+}
+
+func (m *jobsServiceMock) GetJob(id string) (*jobs.Job, errors.ApiError) {
+	return m.job, m.err
+}
+
+func (m *jobsServiceMock) CancelJob(id string) (*jobs.Job, errors.ApiError) {
+	return m.job, m.err
+}
+
+func TestGetJob_Success(t *testing.T) {
+	services.JobsService = &jobsServiceMock{job: &jobs.Job{Id: "abc", Status: jobs.StatusRunning, Total: 3, Processed: 1}}
+
+	request, _ := http.NewRequest(http.MethodGet, "/jobs/abc", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	GetJob(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result jobs.Job
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "abc", result.Id)
+	assert.EqualValues(t, 1, result.Processed)
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	services.JobsService = &jobsServiceMock{err: errors.NewNotFoundError("job not found")}
+
+	request, _ := http.NewRequest(http.MethodGet, "/jobs/abc", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	GetJob(c)
+
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+}
+
+func TestCancelJob_Success(t *testing.T) {
+	services.JobsService = &jobsServiceMock{job: &jobs.Job{Id: "abc", Status: jobs.StatusCancelling}}
+
+	request, _ := http.NewRequest(http.MethodDelete, "/jobs/abc", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CancelJob(c)
+
+	assert.EqualValues(t, http.StatusAccepted, response.Code)
+	var result jobs.Job
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, jobs.StatusCancelling, result.Status)
+}
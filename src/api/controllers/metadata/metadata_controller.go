@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func SetTopics(c *gin.Context) {
+	var request repositories.TopicsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.MetadataService.SetTopics(c.Request.Context(), c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// SyncLabels answers 200 even when some labels failed, each result tells its own outcome.
+func SyncLabels(c *gin.Context) {
+	var request repositories.LabelsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.MetadataService.SyncLabels(c.Request.Context(), c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateMilestones answers 201 when every milestone was created, otherwise 200 along
+// with the error of each failed one.
+func CreateMilestones(c *gin.Context) {
+	var request repositories.MilestonesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.MetadataService.CreateMilestones(c.Request.Context(), c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	for _, result := range res.Results {
+		if !result.Applied {
+			c.JSON(http.StatusOK, res)
+			return
+		}
+	}
+	c.JSON(http.StatusCreated, res)
+}
@@ -0,0 +1,91 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type metadataServiceMock struct {
+	topics     *repositories.TopicsResponse
+	labels     *repositories.LabelsResponse
+	milestones *repositories.MilestonesResponse
+	err        errors.ApiError
+}
+
+func (m *metadataServiceMock) SetTopics(ctx context.Context, owner string, name string, request repositories.TopicsRequest) (*repositories.TopicsResponse, errors.ApiError) {
+	return m.topics, m.err
+}
+
+func (m *metadataServiceMock) SyncLabels(ctx context.Context, owner string, name string, request repositories.LabelsRequest) (*repositories.LabelsResponse, errors.ApiError) {
+	return m.labels, m.err
+}
+
+func (m *metadataServiceMock) CreateMilestones(ctx context.Context, owner string, name string, request repositories.MilestonesRequest) (*repositories.MilestonesResponse, errors.ApiError) {
+	return m.milestones, m.err
+}
+
+func TestSetTopics_Success(t *testing.T) {
+	services.MetadataService = &metadataServiceMock{topics: &repositories.TopicsResponse{Topics: []string{"go"}}}
+
+	request, _ := http.NewRequest(http.MethodPut, "/repos/my-org/my-repo/topics", strings.NewReader(`{"topics": ["go"]}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	SetTopics(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result repositories.TopicsResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go"}, result.Topics)
+}
+
+func TestSyncLabels_Error(t *testing.T) {
+	services.MetadataService = &metadataServiceMock{err: errors.NewBadRequestError("Label bug is listed more than once")}
+
+	request, _ := http.NewRequest(http.MethodPut, "/repos/my-org/my-repo/labels", strings.NewReader(`{"labels": []}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	SyncLabels(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestCreateMilestones_InvalidJson(t *testing.T) {
+	services.MetadataService = &metadataServiceMock{}
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos/my-org/my-repo/milestones", strings.NewReader(`{`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateMilestones(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestCreateMilestones_Status(t *testing.T) {
+	for status, results := range map[int][]repositories.MilestoneResult{
+		http.StatusCreated: {{Title: "v1", Number: 1, Applied: true}},
+		http.StatusOK:      {{Title: "v1", Number: 1, Applied: true}, {Title: "v2", Error: errors.NewApiError(http.StatusUnprocessableEntity, "Validation Failed")}},
+	} {
+		services.MetadataService = &metadataServiceMock{milestones: &repositories.MilestonesResponse{Results: results}}
+
+		request, _ := http.NewRequest(http.MethodPost, "/repos/my-org/my-repo/milestones", strings.NewReader(`{"milestones": [{"title": "v1"}]}`))
+		response := httptest.NewRecorder()
+		c := test_utils.GetMockContext(request, response)
+
+		CreateMilestones(c)
+
+		assert.EqualValues(t, status, response.Code)
+	}
+}
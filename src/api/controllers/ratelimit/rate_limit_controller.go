@@ -0,0 +1,18 @@
+package ratelimit
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetRateLimit(c *gin.Context) {
+	res, err := services.RateLimitService.GetRateLimit(c.Request.Context())
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
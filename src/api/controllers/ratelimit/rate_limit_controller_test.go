@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/ratelimit"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type rateLimitServiceMock struct {
+	response *ratelimit.RateLimitResponse
+	err      errors.ApiError
+}
+
+func (r *rateLimitServiceMock) GetRateLimit(ctx context.Context) (*ratelimit.RateLimitResponse, errors.ApiError) {
+	return r.response, r.err
+}
+
+func TestGetRateLimit_Success(t *testing.T) {
+	services.RateLimitService = &rateLimitServiceMock{
+		response: &ratelimit.RateLimitResponse{Budget: ratelimit.Budget{Limit: 5000, Remaining: 42}},
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "/rate_limit", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	GetRateLimit(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result ratelimit.RateLimitResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5000, result.Limit)
+	assert.EqualValues(t, 42, result.Remaining)
+	assert.Nil(t, result.PausedUntil)
+}
+
+func TestGetRateLimit_HandleError(t *testing.T) {
+	services.RateLimitService = &rateLimitServiceMock{
+		err: errors.NewApiError(http.StatusUnauthorized, "Bad credentials"),
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, "/rate_limit", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	GetRateLimit(c)
+
+	assert.EqualValues(t, http.StatusUnauthorized, response.Code)
+	apiErr, err := errors.NewApiErrFromBody(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Bad credentials", apiErr.Message())
+}
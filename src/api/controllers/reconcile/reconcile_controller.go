@@ -0,0 +1,36 @@
+package reconcile
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Reconcile only plans unless dry_run=false is explicitly sent.
+func Reconcile(c *gin.Context) {
+	var manifest reconcile.Manifest
+	if err := c.ShouldBindJSON(&manifest); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	if len(manifest.Repos) > config.GetBatchMaxSize() {
+		apiErr := errors.NewBadRequestError(fmt.Sprintf("Manifest size exceeds the maximum of %d repositories", config.GetBatchMaxSize()))
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.ReconcileService.Reconcile(c.Request.Context(), manifest, c.Query("dry_run") != "false")
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,77 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type reconcileServiceMock struct {
+	mock.Mock
+}
+
+func (r *reconcileServiceMock) Reconcile(ctx context.Context, manifest reconcile.Manifest, dryRun bool) (*reconcile.ReconcileResponse, errors.ApiError) {
+	args := r.Called(manifest, dryRun)
+	if args.Error(1) != nil {
+		return nil, args.Error(1).(errors.ApiError)
+	}
+
+	return args.Get(0).(*reconcile.ReconcileResponse), nil
+}
+
+func TestReconcile_InvalidJson(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/reconcile", strings.NewReader(`{`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	Reconcile(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestReconcile_DryRunByDefault(t *testing.T) {
+	manifest := reconcile.Manifest{Org: "my-org", Repos: []reconcile.DesiredRepo{{Name: "api"}}}
+	mockService := new(reconcileServiceMock)
+	mockService.On("Reconcile", manifest, true).Return(&reconcile.ReconcileResponse{
+		DryRun: true,
+		Plan:   []reconcile.Action{{Type: reconcile.ActionCreate, Name: "api"}},
+	}, nil)
+	services.ReconcileService = mockService
+
+	request, _ := http.NewRequest(http.MethodPost, "/reconcile", strings.NewReader(`{"org": "my-org", "repos": [{"name": "api"}]}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	Reconcile(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result reconcile.ReconcileResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.True(t, result.DryRun)
+	assert.EqualValues(t, "api", result.Plan[0].Name)
+}
+
+func TestReconcile_Apply(t *testing.T) {
+	manifest := reconcile.Manifest{Repos: []reconcile.DesiredRepo{{Name: "api"}}}
+	mockService := new(reconcileServiceMock)
+	mockService.On("Reconcile", manifest, false).Return(nil, errors.NewApiError(http.StatusUnauthorized, "Bad credentials"))
+	services.ReconcileService = mockService
+
+	request, _ := http.NewRequest(http.MethodPost, "/reconcile?dry_run=false", strings.NewReader(`{"repos": [{"name": "api"}]}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	Reconcile(c)
+
+	assert.EqualValues(t, http.StatusUnauthorized, response.Code)
+}
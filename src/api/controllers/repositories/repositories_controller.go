@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
 	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
 	"github.com/dmolina79/golang-github-api/src/api/services"
 	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -12,13 +15,13 @@ func CreateRepo(c *gin.Context) {
 	var request repositories.CreateRepoRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		apiErr := errors.NewBadRequestError("invalid json body")
-		c.JSON(apiErr.Status(), apiErr)
+		http_utils.RespondError(c, apiErr)
 		return
 	}
 
-	res, err := services.RepositoryService.CreateRepo(request)
+	res, err := services.RepositoryService.CreateRepo(c.Request.Context(), request)
 	if err != nil {
-		c.JSON(err.Status(), err)
+		http_utils.RespondError(c, err)
 		return
 	}
 
@@ -29,15 +32,161 @@ func CreateRepos(c *gin.Context) {
 	var requests []repositories.CreateRepoRequest
 	if err := c.ShouldBindJSON(&requests); err != nil {
 		apiErr := errors.NewBadRequestError("invalid json body")
-		c.JSON(apiErr.Status(), apiErr)
+		http_utils.RespondError(c, apiErr)
 		return
 	}
 
-	res, err := services.RepositoryService.CreateRepos(requests)
+	if len(requests) > config.GetBatchMaxSize() {
+		apiErr := errors.NewBadRequestError(fmt.Sprintf("Batch size exceeds the maximum of %d repositories", config.GetBatchMaxSize()))
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	atomic := c.Query("atomic") == "true"
+	if c.Query("async") == "true" {
+		job, err := services.JobsService.CreateReposJob(requests, atomic)
+		if err != nil {
+			http_utils.RespondError(c, err)
+			return
+		}
+
+		c.Header("Location", fmt.Sprintf("/jobs/%s", job.Id))
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	if format := getStreamFormat(c); format != "" {
+		streamRepos(c, format, requests, atomic)
+		return
+	}
+
+	res, err := services.RepositoryService.CreateReposWithOptions(c.Request.Context(), requests, services.CreateReposOptions{Atomic: atomic})
 	if err != nil {
-		c.JSON(err.Status(), err)
+		http_utils.RespondError(c, err)
 		return
 	}
 
 	c.JSON(res.StatusCode, res)
 }
+
+// ListRepos returns a single page, with the next one announced in the body and in a
+// Link header, or every page as a stream when all=true.
+func ListRepos(c *gin.Context) {
+	var request repositories.ListReposRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid query params")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	if request.All {
+		format := getStreamFormat(c)
+		if format == "" {
+			format = streamNdjson
+		}
+		streamAllRepos(c, format, request)
+		return
+	}
+
+	res, err := services.RepositoryService.ListRepos(c.Request.Context(), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	if res.NextCursor != "" {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set("cursor", res.NextCursor)
+		next.RawQuery = query.Encode()
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func GetRepo(c *gin.Context) {
+	res, err := services.RepositoryService.GetRepo(c.Request.Context(), c.Param("owner"), c.Param("name"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func UpdateRepo(c *gin.Context) {
+	var request repositories.UpdateRepoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.RepositoryService.UpdateRepo(c.Request.Context(), c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func ArchiveRepo(c *gin.Context) {
+	res, err := services.RepositoryService.ArchiveRepo(c.Request.Context(), c.Param("owner"), c.Param("name"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ForkRepo answers 201 once the fork is ready, or 202 while github is still copying it.
+func ForkRepo(c *gin.Context) {
+	var request repositories.ForkRepoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			apiErr := errors.NewBadRequestError("invalid json body")
+			http_utils.RespondError(c, apiErr)
+			return
+		}
+	}
+
+	res, err := services.RepositoryService.ForkRepo(c.Request.Context(), c.Param("owner"), c.Param("name"), request, c.Query("wait") == "true")
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	if res.Ready {
+		c.JSON(http.StatusCreated, res)
+		return
+	}
+	c.JSON(http.StatusAccepted, res)
+}
+
+func SeedRepo(c *gin.Context) {
+	var request repositories.SeedRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apiErr := errors.NewBadRequestError("invalid json body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, err := services.SeedService.SeedRepo(c.Request.Context(), c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func DeleteRepo(c *gin.Context) {
+	if err := services.RepositoryService.DeleteRepo(c.Request.Context(), c.Param("owner"), c.Param("name")); err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
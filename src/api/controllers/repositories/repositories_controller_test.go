@@ -1,11 +1,15 @@
 package repositories
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/jobs"
 	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
 	"github.com/dmolina79/golang-github-api/src/api/services"
 	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
 	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
@@ -19,7 +23,7 @@ type repoServiceMock struct {
 }
 
 // stubs for mock
-func (r repoServiceMock) CreateRepo(request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+func (r repoServiceMock) CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
 	args := r.Called(request)
 	if args.Error(1) != nil {
 		return nil, args.Error(1).(errors.ApiError)
@@ -29,18 +33,116 @@ func (r repoServiceMock) CreateRepo(request repositories.CreateRepoRequest) (*re
 
 }
 
-func (r repoServiceMock) CreateRepos(request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError) {
+func (r repoServiceMock) CreateRepos(ctx context.Context, request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError) {
 	return repositories.CreateReposResponse{}, nil
 }
 
+func (r repoServiceMock) CreateReposWithOptions(ctx context.Context, request []repositories.CreateRepoRequest, options services.CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError) {
+	if len(request) == 0 {
+		return repositories.CreateReposResponse{}, errors.NewBadRequestError("No repositories to create")
+	}
+
+	var response repositories.CreateReposResponse
+	failed := false
+	for index, current := range request {
+		result := repositories.CreateReposResult{Index: index, Name: current.Name, Response: &repositories.CreateRepoResponse{Repository: repositories.Repository{Name: current.Name}}}
+		if current.Name == "" {
+			result.Response = nil
+			result.Error = errors.NewBadRequestError("Invalid repository name")
+			failed = true
+		}
+		if options.Progress != nil {
+			options.Progress(result)
+		}
+		response.Results = append(response.Results, result)
+	}
+	if options.Atomic && failed {
+		for i := range response.Results {
+			if response.Results[i].Response != nil {
+				response.Results[i].Compensation = &repositories.CompensationResult{RolledBack: true}
+			}
+		}
+	}
+	response.Summarize()
+	return response, nil
+}
+
+func (r repoServiceMock) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	args := r.Called(owner, name)
+	if args.Error(1) != nil {
+		return nil, args.Error(1).(errors.ApiError)
+	}
+
+	return args.Get(0).(*repositories.Repository), nil
+}
+
+func (r repoServiceMock) UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	args := r.Called(owner, name, request)
+	if args.Error(1) != nil {
+		return nil, args.Error(1).(errors.ApiError)
+	}
+
+	return args.Get(0).(*repositories.Repository), nil
+}
+
+func (r repoServiceMock) ArchiveRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	args := r.Called(owner, name)
+	if args.Error(1) != nil {
+		return nil, args.Error(1).(errors.ApiError)
+	}
+
+	return args.Get(0).(*repositories.Repository), nil
+}
+
+func (r repoServiceMock) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	args := r.Called(owner, name)
+	if args.Error(0) != nil {
+		return args.Error(0).(errors.ApiError)
+	}
+
+	return nil
+}
+
+func (r repoServiceMock) ListRepos(ctx context.Context, request repositories.ListReposRequest) (*repositories.ListReposResponse, errors.ApiError) {
+	args := r.Called(request)
+	if args.Error(1) != nil {
+		return nil, args.Error(1).(errors.ApiError)
+	}
+
+	return args.Get(0).(*repositories.ListReposResponse), nil
+}
+
+func (r repoServiceMock) ListAllRepos(ctx context.Context, request repositories.ListReposRequest, page func([]repositories.Repository)) errors.ApiError {
+	args := r.Called(request)
+	if pages, ok := args.Get(0).([][]repositories.Repository); ok {
+		for _, p := range pages {
+			page(p)
+		}
+	}
+	if args.Error(1) != nil {
+		return args.Error(1).(errors.ApiError)
+	}
+
+	return nil
+}
+
+func (r repoServiceMock) ForkRepo(ctx context.Context, owner string, name string, request repositories.ForkRepoRequest, wait bool) (*repositories.ForkRepoResponse, errors.ApiError) {
+	args := r.Called(owner, name, request, wait)
+	if args.Error(1) != nil {
+		return nil, args.Error(1).(errors.ApiError)
+	}
+
+	return args.Get(0).(*repositories.ForkRepoResponse), nil
+}
+
 func TestCreateRepo_Success(t *testing.T) {
 	mockService := new(repoServiceMock)
 	mockService.On("CreateRepo", mock.Anything).Return(
-		&repositories.CreateRepoResponse{
+		&repositories.CreateRepoResponse{Repository: repositories.Repository{
 			Id:    321,
 			Owner: "vbuterin",
 			Name:  "github-repo-test-mock",
-		},
+		}},
 		nil)
 
 	services.RepositoryService = mockService
@@ -81,3 +183,359 @@ func TestCreateRepo_HandleError(t *testing.T) {
 	assert.EqualValues(t, http.StatusBadRequest, apiErr.Status())
 	assert.EqualValues(t, "error on request", apiErr.Message())
 }
+
+func TestCreateRepos_BatchTooBig(t *testing.T) {
+	items := make([]string, config.GetBatchMaxSize()+1)
+	for i := range items {
+		items[i] = `{"name": "github-repo"}`
+	}
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos", strings.NewReader("["+strings.Join(items, ",")+"]"))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateRepos(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrFromBody(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Batch size exceeds the maximum of 100 repositories", apiErr.Message())
+}
+
+type jobsServiceMock struct{}
+
+func (m jobsServiceMock) CreateReposJob(request []repositories.CreateRepoRequest, atomic bool) (*jobs.Job, errors.ApiError) {
+	return &jobs.Job{Id: "abc", Status: jobs.StatusRunning, Total: len(request)}, nil
+}
+
+func (m jobsServiceMock) GetJob(id string) (*jobs.Job, errors.ApiError) {
+	return nil, nil
+}
+
+func (m jobsServiceMock) CancelJob(id string) (*jobs.Job, errors.ApiError) {
+	return nil, nil
+}
+
+func TestCreateRepos_Async(t *testing.T) {
+	services.JobsService = jobsServiceMock{}
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos?async=true", strings.NewReader(`[{ "name": "github-repo"}, { "name": "other-repo"}]`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateRepos(c)
+
+	assert.EqualValues(t, http.StatusAccepted, response.Code)
+	assert.EqualValues(t, "/jobs/abc", response.Header().Get("Location"))
+	var result jobs.Job
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "abc", result.Id)
+	assert.EqualValues(t, 2, result.Total)
+}
+
+func TestCreateRepos_StreamNdjson(t *testing.T) {
+	services.RepositoryService = new(repoServiceMock)
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos?stream=ndjson", strings.NewReader(`[{ "name": "github-repo"}, { "name": "other-repo"}]`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateRepos(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+	assert.EqualValues(t, 3, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"event":"result","data":{"index":0,"name":"github-repo",`))
+	assert.EqualValues(t, `{"event":"summary","data":{"status":201,"summary":{"total":2,"succeeded":2,"failed":0,"skipped":0}}}`, lines[2])
+}
+
+func TestCreateRepos_StreamSse(t *testing.T) {
+	services.RepositoryService = new(repoServiceMock)
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos", strings.NewReader(`[{ "name": "github-repo"}]`))
+	request.Header.Set("Accept", "text/event-stream")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateRepos(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "text/event-stream", response.Header().Get("Content-Type"))
+	events := strings.Split(strings.TrimSpace(response.Body.String()), "\n\n")
+	assert.EqualValues(t, 2, len(events))
+	assert.True(t, strings.HasPrefix(events[0], "event: result\ndata: {\"index\":0"))
+	assert.EqualValues(t, `event: summary
+data: {"status":201,"summary":{"total":1,"succeeded":1,"failed":0,"skipped":0}}`, events[1])
+}
+
+func TestCreateRepos_StreamAtomicRollback(t *testing.T) {
+	services.RepositoryService = new(repoServiceMock)
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos?stream=ndjson&atomic=true", strings.NewReader(`[{ "name": "github-repo"}, { "name": ""}]`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateRepos(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+	assert.EqualValues(t, 4, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"event":"result","data":{"index":0,"name":"github-repo",`))
+	assert.True(t, strings.HasPrefix(lines[1], `{"event":"result","data":{"index":1,"name":"",`))
+	assert.True(t, strings.HasPrefix(lines[2], `{"event":"compensation","data":{"index":0,"name":"github-repo",`))
+	assert.True(t, strings.HasSuffix(lines[2], `"compensation":{"rolled_back":true}}}`))
+	assert.EqualValues(t, `{"event":"summary","data":{"status":400,"summary":{"total":2,"succeeded":0,"failed":1,"skipped":0,"rolled_back":1}}}`, lines[3])
+}
+
+func TestCreateRepos_StreamErrorBeforeFirstEvent(t *testing.T) {
+	services.RepositoryService = new(repoServiceMock)
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos?stream=ndjson", strings.NewReader(`[]`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	CreateRepos(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrFromBody(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "No repositories to create", apiErr.Message())
+}
+
+func TestGetRepo_Success(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("GetRepo", "dmolina79", "github-repo").Return(
+		&repositories.Repository{Id: 321, Owner: "dmolina79", Name: "github-repo"}, nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/repos/dmolina79/github-repo", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	GetRepo(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result repositories.Repository
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 321, result.Id)
+}
+
+func TestUpdateRepo_InvalidJson(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPatch, "/repos/dmolina79/github-repo", strings.NewReader(`{`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	UpdateRepo(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestUpdateRepo_Success(t *testing.T) {
+	description := "new description"
+	mockService := new(repoServiceMock)
+	mockService.On("UpdateRepo", "dmolina79", "github-repo", repositories.UpdateRepoRequest{Description: &description}).Return(
+		&repositories.Repository{Id: 321, Description: description}, nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodPatch, "/repos/dmolina79/github-repo", strings.NewReader(`{"description": "new description"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	UpdateRepo(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result repositories.Repository
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "new description", result.Description)
+}
+
+func TestArchiveRepo_HandleError(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ArchiveRepo", "dmolina79", "github-repo").Return(nil, errors.NewNotFoundError("Not Found"))
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos/dmolina79/github-repo/archive", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	ArchiveRepo(c)
+
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+}
+
+func TestDeleteRepo_Success(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("DeleteRepo", "dmolina79", "github-repo").Return(nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodDelete, "/repos/dmolina79/github-repo", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	DeleteRepo(c)
+
+	assert.EqualValues(t, http.StatusNoContent, c.Writer.Status())
+}
+
+func TestListRepos_NextPage(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ListRepos", repositories.ListReposRequest{Sort: "updated"}).Return(
+		&repositories.ListReposResponse{Repos: []repositories.Repository{{Id: 1, Name: "one"}}, NextCursor: "Mg"}, nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/repos?sort=updated", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	ListRepos(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, `</repos?cursor=Mg&sort=updated>; rel="next"`, response.Header().Get("Link"))
+	var result repositories.ListReposResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(result.Repos))
+	assert.EqualValues(t, "Mg", result.NextCursor)
+}
+
+func TestListRepos_HandleError(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ListRepos", repositories.ListReposRequest{Sort: "stars"}).Return(nil, errors.NewBadRequestError("Invalid sort"))
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/repos?sort=stars", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	ListRepos(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestListRepos_All(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ListAllRepos", repositories.ListReposRequest{All: true}).Return([][]repositories.Repository{
+		{{Id: 1, Name: "one"}, {Id: 2, Name: "two"}},
+		{{Id: 3, Name: "three"}},
+	}, nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/repos?all=true", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	ListRepos(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, contentTypeNdjson, response.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+	assert.EqualValues(t, 4, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"event":"repository"`))
+	assert.EqualValues(t, `{"event":"summary","data":{"total":3}}`, lines[3])
+}
+
+func TestListRepos_AllFailsMidway(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ListAllRepos", repositories.ListReposRequest{All: true}).Return([][]repositories.Repository{
+		{{Id: 1, Name: "one"}},
+	}, errors.NewApiError(http.StatusUnauthorized, "Bad credentials"))
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodGet, "/repos?all=true", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	ListRepos(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+	assert.EqualValues(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[1], `{"event":"error"`))
+}
+
+func TestForkRepo_Accepted(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ForkRepo", "dmolina79", "github-repo", repositories.ForkRepoRequest{}, false).Return(
+		&repositories.ForkRepoResponse{Repository: repositories.Repository{Id: 2, Owner: "my-user", Name: "github-repo"}}, nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos/dmolina79/github-repo/forks", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	ForkRepo(c)
+
+	assert.EqualValues(t, http.StatusAccepted, response.Code)
+	var result repositories.ForkRepoResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-user", result.Owner)
+	assert.False(t, result.Ready)
+}
+
+func TestForkRepo_WaitReady(t *testing.T) {
+	mockService := new(repoServiceMock)
+	mockService.On("ForkRepo", "dmolina79", "github-repo", repositories.ForkRepoRequest{Org: "my-org", DefaultBranchOnly: true}, true).Return(
+		&repositories.ForkRepoResponse{Repository: repositories.Repository{Id: 2, Owner: "my-org", Name: "github-repo"}, Ready: true}, nil)
+	services.RepositoryService = mockService
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos/dmolina79/github-repo/forks?wait=true", strings.NewReader(`{"org": "my-org", "default_branch_only": true}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	ForkRepo(c)
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+}
+
+type seedServiceMock struct {
+	mock.Mock
+}
+
+func (s seedServiceMock) SeedRepo(ctx context.Context, owner string, name string, request repositories.SeedRequest) (*repositories.SeedResponse, errors.ApiError) {
+	args := s.Called(owner, name, request)
+	if args.Get(1) != nil {
+		return nil, args.Get(1).(errors.ApiError)
+	}
+	return args.Get(0).(*repositories.SeedResponse), nil
+}
+
+func TestSeedRepo_InvalidJsonRequest(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/repos/dmolina79/github-repo/seed", strings.NewReader(``))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+
+	SeedRepo(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestSeedRepo_Created(t *testing.T) {
+	mockService := new(seedServiceMock)
+	mockService.On("SeedRepo", "dmolina79", "github-repo", repositories.SeedRequest{Template: "go-service", Variables: map[string]string{"team": "platform"}}).Return(
+		&repositories.SeedResponse{Branch: "main", Commit: "seed-sha", Files: []string{"README.md"}}, nil)
+	services.SeedService = mockService
+
+	request, _ := http.NewRequest(http.MethodPost, "/repos/dmolina79/github-repo/seed", strings.NewReader(`{"template": "go-service", "variables": {"team": "platform"}}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "dmolina79"}, {Key: "name", Value: "github-repo"}}
+
+	SeedRepo(c)
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	var result repositories.SeedResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "seed-sha", result.Commit)
+}
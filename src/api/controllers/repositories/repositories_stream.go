@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+const (
+	streamNdjson      = "ndjson"
+	streamSse         = "sse"
+	contentTypeNdjson = "application/x-ndjson"
+	contentTypeSse    = "text/event-stream"
+	eventResult       = "result"
+	eventRepository   = "repository"
+	eventSummary      = "summary"
+	eventCompensation = "compensation"
+	eventError        = "error"
+)
+
+type streamEvent struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+type streamOutcome struct {
+	response repositories.CreateReposResponse
+	err      errors.ApiError
+}
+
+// getStreamFormat picks the streaming format from the stream query param, falling
+// back to the Accept header. An empty result means the client wants a plain response.
+func getStreamFormat(c *gin.Context) string {
+	switch c.Query("stream") {
+	case streamNdjson:
+		return streamNdjson
+	case streamSse:
+		return streamSse
+	}
+
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, contentTypeNdjson):
+		return streamNdjson
+	case strings.Contains(accept, contentTypeSse):
+		return streamSse
+	}
+	return ""
+}
+
+// streamRepos writes every batch result as soon as a worker produces it, then a final
+// summary event carrying the aggregate status code. An atomic batch that got rolled
+// back writes the result of every repository it deleted, or failed to, in between.
+func streamRepos(c *gin.Context, format string, requests []repositories.CreateRepoRequest, atomic bool) {
+	events := make(chan repositories.CreateReposResult)
+	done := make(chan streamOutcome, 1)
+
+	go func() {
+		res, err := services.RepositoryService.CreateReposWithOptions(c.Request.Context(), requests, services.CreateReposOptions{
+			Atomic: atomic,
+			Progress: func(result repositories.CreateReposResult) {
+				events <- result
+			},
+		})
+		close(events)
+		done <- streamOutcome{response: res, err: err}
+	}()
+
+	started := false
+	for result := range events {
+		if !started {
+			startStream(c, format)
+			started = true
+		}
+		writeStreamEvent(c, format, eventResult, result)
+	}
+
+	outcome := <-done
+	if outcome.err != nil && !started {
+		http_utils.RespondError(c, outcome.err)
+		return
+	}
+	if !started {
+		startStream(c, format)
+	}
+	if outcome.err != nil {
+		writeStreamEvent(c, format, eventError, outcome.err)
+		return
+	}
+
+	for _, result := range outcome.response.Results {
+		if result.Compensation != nil {
+			writeStreamEvent(c, format, eventCompensation, result)
+		}
+	}
+	writeStreamEvent(c, format, eventSummary, repositories.CreateReposStreamSummary{
+		StatusCode: outcome.response.StatusCode,
+		Summary:    outcome.response.Summary,
+	})
+}
+
+// streamAllRepos writes every listed repository as its page arrives, then a summary
+// event with the total. Failures before the first page are answered as plain json.
+func streamAllRepos(c *gin.Context, format string, request repositories.ListReposRequest) {
+	started := false
+	total := 0
+	err := services.RepositoryService.ListAllRepos(c.Request.Context(), request, func(page []repositories.Repository) {
+		if !started {
+			startStream(c, format)
+			started = true
+		}
+		for _, repo := range page {
+			writeStreamEvent(c, format, eventRepository, repo)
+		}
+		total += len(page)
+	})
+
+	if err != nil && !started {
+		http_utils.RespondError(c, err)
+		return
+	}
+	if !started {
+		startStream(c, format)
+	}
+	if err != nil {
+		writeStreamEvent(c, format, eventError, err)
+		return
+	}
+
+	writeStreamEvent(c, format, eventSummary, repositories.ListReposSummary{Total: total})
+}
+
+func startStream(c *gin.Context, format string) {
+	if format == streamSse {
+		c.Header("Content-Type", contentTypeSse)
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+	} else {
+		c.Header("Content-Type", contentTypeNdjson)
+	}
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+}
+
+func writeStreamEvent(c *gin.Context, format string, event string, data interface{}) {
+	var payload []byte
+	var err error
+	if format == streamSse {
+		if payload, err = json.Marshal(data); err == nil {
+			payload = []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+		}
+	} else {
+		if payload, err = json.Marshal(streamEvent{Event: event, Data: data}); err == nil {
+			payload = append(payload, '\n')
+		}
+	}
+	if err != nil {
+		log.Error("error when trying to marshal stream event", err, fmt.Sprintf("event:%s", event))
+		return
+	}
+
+	if _, err := c.Writer.Write(payload); err != nil {
+		log.Error("error when trying to write stream event", err, fmt.Sprintf("event:%s", event))
+		return
+	}
+	c.Writer.Flush()
+}
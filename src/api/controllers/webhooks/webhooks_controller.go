@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
+	"github.com/dmolina79/golang-github-api/src/api/services"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/http_utils"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxPayloadBytes is the largest payload github delivers.
+	maxPayloadBytes = 25 << 20
+
+	githubHeaderPrefix = "X-Github-"
+	hubHeaderPrefix    = "X-Hub-"
+)
+
+// ReceiveGithub answers 200 once the delivery is logged, whatever the outcome of its
+// handlers: a failed delivery is retried by this api, not by github.
+func ReceiveGithub(c *gin.Context) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPayloadBytes))
+	if err != nil {
+		apiErr := errors.NewBadRequestError("invalid body")
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	res, apiErr := services.WebhookService.Receive(c.Request.Context(), services.InboundWebhook{
+		Event:       c.GetHeader(webhooks.HeaderEvent),
+		DeliveryId:  c.GetHeader(webhooks.HeaderDelivery),
+		Signature:   c.GetHeader(webhooks.HeaderSignature),
+		ContentType: c.ContentType(),
+		Headers:     deliveryHeaders(c.Request.Header),
+		Body:        body,
+	})
+	if apiErr != nil {
+		http_utils.RespondError(c, apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ListDeliveries filters the deliveries on the status and event query parameters,
+// returning up to limit of them.
+func ListDeliveries(c *gin.Context) {
+	filter := webhooks.DeliveryFilter{Status: c.Query("status"), Event: c.Query("event")}
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			apiErr := errors.NewBadRequestError("invalid limit")
+			http_utils.RespondError(c, apiErr)
+			return
+		}
+	}
+
+	res, err := services.WebhookService.ListDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func GetDelivery(c *gin.Context) {
+	res, err := services.WebhookService.GetDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ReplayDelivery answers 200 along with the delivery, its last attempt telling how
+// the replay went.
+func ReplayDelivery(c *gin.Context) {
+	res, err := services.WebhookService.ReplayDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		http_utils.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// deliveryHeaders keeps the headers github sets on a delivery.
+func deliveryHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for key := range header {
+		if strings.HasPrefix(key, githubHeaderPrefix) || strings.HasPrefix(key, hubHeaderPrefix) || key == "User-Agent" || key == "Content-Type" {
+			headers[key] = header.Get(key)
+		}
+	}
+	return headers
+}
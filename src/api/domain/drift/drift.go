@@ -0,0 +1,100 @@
+package drift
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"time"
+)
+
+// ManagedRepo is the desired state recorded for a repository created, or since then
+// changed, through this api. An empty ProtectionPolicy means protection is not managed.
+type ManagedRepo struct {
+	Owner            string    `json:"owner"`
+	Name             string    `json:"name"`
+	Visibility       string    `json:"visibility"`
+	DefaultBranch    string    `json:"default_branch,omitempty"`
+	HasIssues        bool      `json:"has_issues"`
+	HasProjects      bool      `json:"has_projects"`
+	HasWiki          bool      `json:"has_wiki"`
+	Topics           []string  `json:"topics"`
+	ProtectionPolicy string    `json:"protection_policy,omitempty"`
+	RecordedAt       time.Time `json:"recorded_at"`
+}
+
+// Apply records the settings of update that drift detection watches.
+func (m *ManagedRepo) Apply(update repositories.UpdateRepoRequest) {
+	if update.Name != nil {
+		m.Name = *update.Name
+	}
+	if update.Visibility != nil {
+		m.Visibility = *update.Visibility
+	} else if update.Private != nil {
+		m.Visibility = repositories.VisibilityPublic
+		if *update.Private {
+			m.Visibility = repositories.VisibilityPrivate
+		}
+	}
+	if update.DefaultBranch != nil {
+		m.DefaultBranch = *update.DefaultBranch
+	}
+	if update.HasIssues != nil {
+		m.HasIssues = *update.HasIssues
+	}
+	if update.HasProjects != nil {
+		m.HasProjects = *update.HasProjects
+	}
+	if update.HasWiki != nil {
+		m.HasWiki = *update.HasWiki
+	}
+	m.RecordedAt = time.Now().UTC()
+}
+
+// Copy returns a managed repository that shares no topics slice with m.
+func (m ManagedRepo) Copy() *ManagedRepo {
+	m.Topics = append([]string{}, m.Topics...)
+	return &m
+}
+
+// LiveRepo is what github currently reports for a managed repository. Protection is
+// nil when its default branch is not protected.
+type LiveRepo struct {
+	Repository repositories.Repository
+	Topics     []string
+	Protection *repositories.ProtectionPolicy
+}
+
+// RepoDrift lists the settings of a managed repository that no longer match the
+// recorded ones. Error is set when the repository could not be checked.
+type RepoDrift struct {
+	Owner     string             `json:"owner"`
+	Name      string             `json:"name"`
+	Drifted   bool               `json:"drifted"`
+	Changes   []reconcile.Change `json:"changes,omitempty"`
+	Error     errors.ApiError    `json:"error,omitempty"`
+	CheckedAt time.Time          `json:"checked_at"`
+}
+
+type Summary struct {
+	Total   int `json:"total"`
+	Drifted int `json:"drifted"`
+	Failed  int `json:"failed"`
+}
+
+type Report struct {
+	CheckedAt time.Time   `json:"checked_at"`
+	Summary   Summary     `json:"summary"`
+	Repos     []RepoDrift `json:"repos"`
+}
+
+// AddRepo appends the result of a single repository check, keeping the summary up to date.
+func (r *Report) AddRepo(repo RepoDrift) {
+	r.Repos = append(r.Repos, repo)
+	r.Summary.Total++
+	switch {
+	case repo.Error != nil:
+		r.Summary.Failed++
+	case repo.Drifted:
+		r.Summary.Drifted++
+	}
+}
@@ -0,0 +1,44 @@
+package drift
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestManagedRepo_Apply(t *testing.T) {
+	name := "renamed"
+	private := true
+	disabled := false
+	repo := ManagedRepo{Name: "my-repo", Visibility: repositories.VisibilityPublic, HasIssues: true, HasWiki: true}
+
+	repo.Apply(repositories.UpdateRepoRequest{Name: &name, Private: &private, HasIssues: &disabled})
+
+	assert.EqualValues(t, "renamed", repo.Name)
+	assert.EqualValues(t, repositories.VisibilityPrivate, repo.Visibility)
+	assert.False(t, repo.HasIssues)
+	assert.True(t, repo.HasWiki)
+	assert.False(t, repo.RecordedAt.IsZero())
+}
+
+func TestManagedRepo_ApplyVisibilityWins(t *testing.T) {
+	internal := repositories.VisibilityInternal
+	private := true
+	repo := ManagedRepo{Visibility: repositories.VisibilityPublic}
+
+	repo.Apply(repositories.UpdateRepoRequest{Visibility: &internal, Private: &private})
+
+	assert.EqualValues(t, repositories.VisibilityInternal, repo.Visibility)
+}
+
+func TestReport_AddRepo(t *testing.T) {
+	report := Report{}
+
+	report.AddRepo(RepoDrift{Name: "api"})
+	report.AddRepo(RepoDrift{Name: "web", Drifted: true})
+	report.AddRepo(RepoDrift{Name: "gone", Error: errors.NewNotFoundError("Not Found")})
+
+	assert.EqualValues(t, Summary{Total: 3, Drifted: 1, Failed: 1}, report.Summary)
+	assert.EqualValues(t, 3, len(report.Repos))
+}
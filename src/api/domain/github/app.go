@@ -0,0 +1,10 @@
+package github
+
+import "time"
+
+// InstallationToken is the access token github hands out to an app for one of its
+// installations, valid for an hour.
+type InstallationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
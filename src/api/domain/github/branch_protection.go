@@ -0,0 +1,40 @@
+package github
+
+// BranchProtectionRequest replaces every protection rule of a branch. Github requires
+// the nullable rules to be present, nil turns them off.
+type BranchProtectionRequest struct {
+	RequiredStatusChecks       *RequiredStatusChecks       `json:"required_status_checks"`
+	EnforceAdmins              bool                        `json:"enforce_admins"`
+	RequiredPullRequestReviews *RequiredPullRequestReviews `json:"required_pull_request_reviews"`
+	Restrictions               *BranchRestrictions         `json:"restrictions"`
+	RequiredLinearHistory      bool                        `json:"required_linear_history"`
+}
+
+type RequiredStatusChecks struct {
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+}
+
+type RequiredPullRequestReviews struct {
+	DismissStaleReviews          bool `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+	RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+}
+
+type BranchRestrictions struct {
+	Users []string `json:"users"`
+	Teams []string `json:"teams"`
+}
+
+// BranchProtection is the representation github returns for the rules of a branch.
+type BranchProtection struct {
+	Url                        string                      `json:"url"`
+	RequiredStatusChecks       *RequiredStatusChecks       `json:"required_status_checks"`
+	RequiredPullRequestReviews *RequiredPullRequestReviews `json:"required_pull_request_reviews"`
+	EnforceAdmins              EnabledSetting              `json:"enforce_admins"`
+	RequiredLinearHistory      EnabledSetting              `json:"required_linear_history"`
+}
+
+type EnabledSetting struct {
+	Enabled bool `json:"enabled"`
+}
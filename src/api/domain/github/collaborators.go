@@ -0,0 +1,17 @@
+package github
+
+// CollaboratorRequest grants a user one of the pull, triage, push, maintain or admin
+// permissions, github defaults to push when empty.
+type CollaboratorRequest struct {
+	Permission string `json:"permission,omitempty"`
+}
+
+// Invitation is sent to a user who is not yet a collaborator, the access is only
+// granted once accepted.
+type Invitation struct {
+	Id          int64     `json:"id"`
+	Invitee     RepoOwner `json:"invitee"`
+	Permissions string    `json:"permissions"`
+	HtmlUrl     string    `json:"html_url"`
+	CreatedAt   string    `json:"created_at"`
+}
@@ -1,32 +1,33 @@
 package github
 
 type CreateRepoRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Homepage    string `json:"homepage"`
-	Private     bool   `json:"private"`
-	HasIssues   bool   `json:"has_issues"`
-	HasProjects bool   `json:"has_projects"`
-	HasWiki     bool   `json:"has_wiki"`
+	Name                string `json:"name"`
+	Description         string `json:"description"`
+	Homepage            string `json:"homepage"`
+	Private             bool   `json:"private"`
+	HasIssues           *bool  `json:"has_issues,omitempty"`
+	HasProjects         *bool  `json:"has_projects,omitempty"`
+	HasWiki             *bool  `json:"has_wiki,omitempty"`
+	Visibility          string `json:"visibility,omitempty"`
+	IsTemplate          bool   `json:"is_template,omitempty"`
+	TeamId              int64  `json:"team_id,omitempty"`
+	AutoInit            bool   `json:"auto_init,omitempty"`
+	GitignoreTemplate   string `json:"gitignore_template,omitempty"`
+	LicenseTemplate     string `json:"license_template,omitempty"`
+	AllowSquashMerge    *bool  `json:"allow_squash_merge,omitempty"`
+	AllowMergeCommit    *bool  `json:"allow_merge_commit,omitempty"`
+	AllowRebaseMerge    *bool  `json:"allow_rebase_merge,omitempty"`
+	DeleteBranchOnMerge *bool  `json:"delete_branch_on_merge,omitempty"`
 }
 
-type CreateRepoResponse struct {
-	Id          int64           `json:"id"`
-	Name        string          `json:"name"`
-	FullName    string          `json:"full_name"`
-	Owner       RepoOwner       `json:"owner"`
-	Permissions RepoPermissions `json:"permissions"`
-}
-
-type RepoPermissions struct {
-	IsAdmin bool `json:"admin"`
-	HasPush bool `json:"push"`
-	HasPull bool `json:pull`
-}
+type CreateRepoResponse = Repository
 
-type RepoOwner struct {
-	Id      int64  `json:"id"`
-	Login   string `json:"login"`
-	Url     string `json:"url"`
-	HtmlUrl string `json:"html_url"`
+// GenerateRepoRequest creates a repository out of a template repository. An empty
+// Owner generates it for the authenticated user.
+type GenerateRepoRequest struct {
+	Owner              string `json:"owner,omitempty"`
+	Name               string `json:"name"`
+	Description        string `json:"description,omitempty"`
+	IncludeAllBranches bool   `json:"include_all_branches"`
+	Private            bool   `json:"private"`
 }
@@ -7,14 +7,16 @@ import (
 )
 
 func TestCreateRepoRequestAsJson(t *testing.T) {
+	enabled := true
+	disabled := false
 	request := CreateRepoRequest{
 		Name:        "golang introduction",
 		Description: "a golang intro repo",
 		Homepage:    "https://github.com",
 		Private:     true,
-		HasIssues:   false,
-		HasProjects: true,
-		HasWiki:     false,
+		HasIssues:   &disabled,
+		HasProjects: &enabled,
+		HasWiki:     &disabled,
 	}
 
 	bytes, err := json.Marshal(request)
@@ -31,5 +33,56 @@ func TestCreateRepoRequestAsJson(t *testing.T) {
 	assert.Nil(t, err)
 
 	assert.EqualValues(t, target.Name, request.Name)
-	assert.EqualValues(t, target.HasIssues, request.HasIssues)
+	assert.EqualValues(t, *target.HasIssues, *request.HasIssues)
+}
+
+func TestCreateRepoRequestAsJsonLeavesFeaturesToGithub(t *testing.T) {
+	request := CreateRepoRequest{Name: "golang-introduction"}
+
+	bytes, err := json.Marshal(request)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"name":"golang-introduction","description":"","homepage":"","private":false}`, string(bytes))
+}
+
+func TestCreateRepoRequestAsJsonWithSettings(t *testing.T) {
+	allow := false
+	request := CreateRepoRequest{
+		Name:              "golang-introduction",
+		Visibility:        "private",
+		AutoInit:          true,
+		GitignoreTemplate: "Go",
+		LicenseTemplate:   "mit",
+		AllowMergeCommit:  &allow,
+	}
+
+	bytes, err := json.Marshal(request)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t,
+		`{"name":"golang-introduction","description":"","homepage":"","private":false,"visibility":"private","auto_init":true,"gitignore_template":"Go","license_template":"mit","allow_merge_commit":false}`,
+		string(bytes))
+}
+
+func TestUpdateRepoRequestAsJson(t *testing.T) {
+	archived := true
+	description := ""
+	request := UpdateRepoRequest{Archived: &archived, Description: &description}
+
+	bytes, err := json.Marshal(request)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"description":"","archived":true}`, string(bytes))
+}
+
+func TestBranchProtectionRequestAsJson(t *testing.T) {
+	request := BranchProtectionRequest{
+		RequiredPullRequestReviews: &RequiredPullRequestReviews{RequiredApprovingReviewCount: 1},
+		RequiredLinearHistory:      true,
+	}
+
+	bytes, err := json.Marshal(request)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"required_status_checks":null,"enforce_admins":false,"required_pull_request_reviews":{"dismiss_stale_reviews":false,"require_code_owner_reviews":false,"required_approving_review_count":1},"restrictions":null,"required_linear_history":true}`, string(bytes))
 }
@@ -0,0 +1,119 @@
+package github
+
+// The events github delivers to webhooks, named after the X-GitHub-Event header.
+const (
+	EventPing         = "ping"
+	EventRepository   = "repository"
+	EventPush         = "push"
+	EventPullRequest  = "pull_request"
+	EventInstallation = "installation"
+)
+
+// EventRepo is the repository an event happened in. Its owner is a user or an
+// organization.
+type EventRepo struct {
+	Id            int64     `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	Private       bool      `json:"private"`
+	Archived      bool      `json:"archived"`
+	Owner         RepoOwner `json:"owner"`
+	HtmlUrl       string    `json:"html_url"`
+	DefaultBranch string    `json:"default_branch"`
+}
+
+// InstallationRef is the github app installation the event was delivered for, only
+// set for the webhooks of an app.
+type InstallationRef struct {
+	Id int64 `json:"id"`
+}
+
+type PingEvent struct {
+	Zen    string `json:"zen"`
+	HookId int64  `json:"hook_id"`
+}
+
+// RepositoryEvent tells a repository was created, deleted, archived, renamed...
+type RepositoryEvent struct {
+	Action       string           `json:"action"`
+	Repository   EventRepo        `json:"repository"`
+	Sender       RepoOwner        `json:"sender"`
+	Installation *InstallationRef `json:"installation,omitempty"`
+}
+
+type PushCommit struct {
+	Id        string `json:"id"`
+	Message   string `json:"message"`
+	Url       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+}
+
+type Pusher struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// PushEvent is sent for every push to a branch or a tag, Ref being the full ref name
+// such as refs/heads/main.
+type PushEvent struct {
+	Ref          string           `json:"ref"`
+	Before       string           `json:"before"`
+	After        string           `json:"after"`
+	Created      bool             `json:"created"`
+	Deleted      bool             `json:"deleted"`
+	Forced       bool             `json:"forced"`
+	Commits      []PushCommit     `json:"commits"`
+	HeadCommit   *PushCommit      `json:"head_commit"`
+	Repository   EventRepo        `json:"repository"`
+	Pusher       Pusher           `json:"pusher"`
+	Sender       RepoOwner        `json:"sender"`
+	Installation *InstallationRef `json:"installation,omitempty"`
+}
+
+type PullRequestRef struct {
+	Ref string `json:"ref"`
+	Sha string `json:"sha"`
+}
+
+type PullRequest struct {
+	Id      int64          `json:"id"`
+	Number  int            `json:"number"`
+	Title   string         `json:"title"`
+	State   string         `json:"state"`
+	Draft   bool           `json:"draft"`
+	Merged  bool           `json:"merged"`
+	HtmlUrl string         `json:"html_url"`
+	User    RepoOwner      `json:"user"`
+	Head    PullRequestRef `json:"head"`
+	Base    PullRequestRef `json:"base"`
+}
+
+type PullRequestEvent struct {
+	Action       string           `json:"action"`
+	Number       int              `json:"number"`
+	PullRequest  PullRequest      `json:"pull_request"`
+	Repository   EventRepo        `json:"repository"`
+	Sender       RepoOwner        `json:"sender"`
+	Installation *InstallationRef `json:"installation,omitempty"`
+}
+
+type Installation struct {
+	Id      int64     `json:"id"`
+	AppId   int64     `json:"app_id"`
+	Account RepoOwner `json:"account"`
+}
+
+type InstallationRepo struct {
+	Id       int64  `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Private  bool   `json:"private"`
+}
+
+// InstallationEvent tells a github app was installed on, or removed from, an account.
+type InstallationEvent struct {
+	Action       string             `json:"action"`
+	Installation Installation       `json:"installation"`
+	Repositories []InstallationRepo `json:"repositories"`
+	Sender       RepoOwner          `json:"sender"`
+}
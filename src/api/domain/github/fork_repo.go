@@ -0,0 +1,8 @@
+package github
+
+// ForkRepoRequest forks into the authenticated user account unless Organization is set.
+type ForkRepoRequest struct {
+	Organization      string `json:"organization,omitempty"`
+	Name              string `json:"name,omitempty"`
+	DefaultBranchOnly bool   `json:"default_branch_only,omitempty"`
+}
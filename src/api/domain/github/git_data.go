@@ -0,0 +1,57 @@
+package github
+
+const (
+	BlobEncodingBase64 = "base64"
+	TreeModeFile       = "100644"
+	TreeModeExecutable = "100755"
+	TreeTypeBlob       = "blob"
+)
+
+type CreateBlobRequest struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// GitObject is the reference github returns to a blob, tree or commit.
+type GitObject struct {
+	Sha  string `json:"sha"`
+	Type string `json:"type,omitempty"`
+	Url  string `json:"url,omitempty"`
+}
+
+type TreeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Sha  string `json:"sha"`
+}
+
+// CreateTreeRequest builds a tree out of Tree, on top of the BaseTree entries when set.
+type CreateTreeRequest struct {
+	BaseTree string      `json:"base_tree,omitempty"`
+	Tree     []TreeEntry `json:"tree"`
+}
+
+// CreateCommitRequest creates a root commit when Parents is empty.
+type CreateCommitRequest struct {
+	Message string   `json:"message"`
+	Tree    string   `json:"tree"`
+	Parents []string `json:"parents"`
+}
+
+type Commit struct {
+	Sha     string    `json:"sha"`
+	HtmlUrl string    `json:"html_url"`
+	Tree    GitObject `json:"tree"`
+}
+
+type Ref struct {
+	Ref    string    `json:"ref"`
+	Object GitObject `json:"object"`
+}
+
+// UpdateRefRequest moves a ref to Sha, Force allowing it to drop the commits it pointed to.
+type UpdateRefRequest struct {
+	Sha   string `json:"sha"`
+	Force bool   `json:"force"`
+}
@@ -12,6 +12,7 @@ type GithubErrorResponse struct {
 	Message          string        `json:"message"`
 	DocumentationUrl string        `json:"documentation_url"`
 	Errors           []GithubError `json:"errors"`
+	RateLimitReset   int64         `json:"rate_limit_reset,omitempty"`
 }
 
 func (r GithubErrorResponse) Error() string {
@@ -0,0 +1,44 @@
+package github
+
+const (
+	HookName = "web"
+)
+
+// HookConfig is where and how github delivers the events. InsecureSsl is "0" or "1",
+// github answering a string. Updating it only changes what is set, Url included.
+type HookConfig struct {
+	Url         string `json:"url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Secret      string `json:"secret,omitempty"`
+	InsecureSsl string `json:"insecure_ssl,omitempty"`
+}
+
+type CreateHookRequest struct {
+	Name   string     `json:"name"`
+	Active bool       `json:"active"`
+	Events []string   `json:"events"`
+	Config HookConfig `json:"config"`
+}
+
+// UpdateHookRequest only changes what is set, the config has its own call.
+type UpdateHookRequest struct {
+	Active *bool    `json:"active,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+type HookResponse struct {
+	Code    int    `json:"code"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type Hook struct {
+	Id           int64        `json:"id"`
+	Name         string       `json:"name"`
+	Active       bool         `json:"active"`
+	Events       []string     `json:"events"`
+	Config       HookConfig   `json:"config"`
+	LastResponse HookResponse `json:"last_response"`
+	CreatedAt    string       `json:"created_at"`
+	UpdatedAt    string       `json:"updated_at"`
+}
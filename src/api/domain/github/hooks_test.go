@@ -0,0 +1,14 @@
+package github
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHookConfigAsJsonWithoutUrl(t *testing.T) {
+	bytes, err := json.Marshal(HookConfig{Secret: "s3cr3t"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"secret":"s3cr3t"}`, string(bytes))
+}
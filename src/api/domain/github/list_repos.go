@@ -0,0 +1,19 @@
+package github
+
+// ListReposRequest holds the filters github accepts when listing repositories,
+// empty values are left to the github defaults.
+type ListReposRequest struct {
+	Type       string
+	Visibility string
+	Sort       string
+	Direction  string
+	PerPage    int
+	Page       int
+}
+
+// ReposPage is a single page of repositories. NextUrl is the page github links
+// as rel="next", empty on the last page.
+type ReposPage struct {
+	Repos   []Repository
+	NextUrl string
+}
@@ -0,0 +1,12 @@
+package github
+
+// Milestone is both the request creating a milestone and the representation github
+// returns for it, DueOn being an ISO 8601 timestamp.
+type Milestone struct {
+	Number      int    `json:"number,omitempty"`
+	Title       string `json:"title"`
+	State       string `json:"state,omitempty"`
+	Description string `json:"description,omitempty"`
+	DueOn       string `json:"due_on,omitempty"`
+	HtmlUrl     string `json:"html_url,omitempty"`
+}
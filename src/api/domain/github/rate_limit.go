@@ -0,0 +1,13 @@
+package github
+
+type RateLimit struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Used      int   `json:"used"`
+	Reset     int64 `json:"reset"`
+}
+
+type RateLimitResponse struct {
+	Resources map[string]RateLimit `json:"resources"`
+	Rate      RateLimit            `json:"rate"`
+}
@@ -0,0 +1,30 @@
+package github
+
+type Topics struct {
+	Names []string `json:"names"`
+}
+
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description,omitempty"`
+}
+
+// TeamRepoRequest grants a team one of the pull, triage, push, maintain or admin permissions.
+type TeamRepoRequest struct {
+	Permission string `json:"permission"`
+}
+
+// CreateFileRequest commits a single file, Content being base64 encoded. An empty
+// Branch commits to the default branch.
+type CreateFileRequest struct {
+	Message string `json:"message"`
+	Content string `json:"content"`
+	Branch  string `json:"branch,omitempty"`
+}
+
+type UpdateLabelRequest struct {
+	NewName     string `json:"new_name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
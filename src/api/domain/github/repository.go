@@ -0,0 +1,57 @@
+package github
+
+// Repository is the representation github returns from every repository call.
+type Repository struct {
+	Id                  int64           `json:"id"`
+	Name                string          `json:"name"`
+	FullName            string          `json:"full_name"`
+	Description         string          `json:"description"`
+	Homepage            string          `json:"homepage"`
+	HtmlUrl             string          `json:"html_url"`
+	DefaultBranch       string          `json:"default_branch"`
+	Private             bool            `json:"private"`
+	Visibility          string          `json:"visibility"`
+	Archived            bool            `json:"archived"`
+	HasIssues           bool            `json:"has_issues"`
+	HasProjects         bool            `json:"has_projects"`
+	HasWiki             bool            `json:"has_wiki"`
+	IsTemplate          bool            `json:"is_template"`
+	AllowSquashMerge    bool            `json:"allow_squash_merge"`
+	AllowMergeCommit    bool            `json:"allow_merge_commit"`
+	AllowRebaseMerge    bool            `json:"allow_rebase_merge"`
+	DeleteBranchOnMerge bool            `json:"delete_branch_on_merge"`
+	Owner               RepoOwner       `json:"owner"`
+	Permissions         RepoPermissions `json:"permissions"`
+}
+
+type RepoPermissions struct {
+	IsAdmin bool `json:"admin"`
+	HasPush bool `json:"push"`
+	HasPull bool `json:"pull"`
+}
+
+type RepoOwner struct {
+	Id      int64  `json:"id"`
+	Login   string `json:"login"`
+	Url     string `json:"url"`
+	HtmlUrl string `json:"html_url"`
+}
+
+// UpdateRepoRequest only sends the fields that are set.
+type UpdateRepoRequest struct {
+	Name                *string `json:"name,omitempty"`
+	Description         *string `json:"description,omitempty"`
+	Homepage            *string `json:"homepage,omitempty"`
+	Private             *bool   `json:"private,omitempty"`
+	Visibility          *string `json:"visibility,omitempty"`
+	DefaultBranch       *string `json:"default_branch,omitempty"`
+	HasIssues           *bool   `json:"has_issues,omitempty"`
+	HasProjects         *bool   `json:"has_projects,omitempty"`
+	HasWiki             *bool   `json:"has_wiki,omitempty"`
+	IsTemplate          *bool   `json:"is_template,omitempty"`
+	AllowSquashMerge    *bool   `json:"allow_squash_merge,omitempty"`
+	AllowMergeCommit    *bool   `json:"allow_merge_commit,omitempty"`
+	AllowRebaseMerge    *bool   `json:"allow_rebase_merge,omitempty"`
+	DeleteBranchOnMerge *bool   `json:"delete_branch_on_merge,omitempty"`
+	Archived            *bool   `json:"archived,omitempty"`
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"sort"
+	"time"
+)
+
+const (
+	StatusRunning    = "running"
+	StatusCancelling = "cancelling"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+)
+
+// Job tracks a batch of repositories created in the background.
+type Job struct {
+	Id         string                           `json:"id"`
+	Status     string                           `json:"status"`
+	CreatedAt  time.Time                        `json:"created_at"`
+	UpdatedAt  time.Time                        `json:"updated_at"`
+	Total      int                              `json:"total"`
+	Processed  int                              `json:"processed"`
+	StatusCode int                              `json:"status_code,omitempty"`
+	Summary    repositories.CreateReposSummary  `json:"summary"`
+	Results    []repositories.CreateReposResult `json:"results"`
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusCancelled
+}
+
+// AddResult records a single item outcome keeping results in input order.
+func (j *Job) AddResult(result repositories.CreateReposResult) {
+	j.Results = append(j.Results, result)
+	sort.Slice(j.Results, func(a, b int) bool {
+		return j.Results[a].Index < j.Results[b].Index
+	})
+	j.Processed = len(j.Results)
+	j.UpdatedAt = time.Now().UTC()
+}
+
+// Copy returns a job that shares no results slice with j.
+func (j Job) Copy() *Job {
+	j.Results = append([]repositories.CreateReposResult(nil), j.Results...)
+	return &j
+}
@@ -0,0 +1,16 @@
+package ratelimit
+
+import "time"
+
+type Budget struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+type RateLimitResponse struct {
+	Budget
+	PausedUntil *time.Time        `json:"paused_until,omitempty"`
+	Resources   map[string]Budget `json:"resources,omitempty"`
+}
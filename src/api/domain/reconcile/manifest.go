@@ -0,0 +1,119 @@
+package reconcile
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+)
+
+// Manifest is the desired state of the repositories of Org, or of the authenticated user
+// when Org is empty. Prune archives the live repositories the manifest does not list.
+type Manifest struct {
+	Org   string        `json:"org"`
+	Prune bool          `json:"prune"`
+	Repos []DesiredRepo `json:"repos"`
+}
+
+// DesiredRepo lists the settings managed for a repository, the ones left nil are not
+// looked at. Blueprint is only used when the repository has to be created.
+type DesiredRepo struct {
+	Name                string  `json:"name"`
+	Description         *string `json:"description"`
+	Homepage            *string `json:"homepage"`
+	Visibility          *string `json:"visibility"`
+	DefaultBranch       *string `json:"default_branch"`
+	HasIssues           *bool   `json:"has_issues"`
+	HasProjects         *bool   `json:"has_projects"`
+	HasWiki             *bool   `json:"has_wiki"`
+	IsTemplate          *bool   `json:"is_template"`
+	AllowSquashMerge    *bool   `json:"allow_squash_merge"`
+	AllowMergeCommit    *bool   `json:"allow_merge_commit"`
+	AllowRebaseMerge    *bool   `json:"allow_rebase_merge"`
+	DeleteBranchOnMerge *bool   `json:"delete_branch_on_merge"`
+	Archived            bool    `json:"archived"`
+	Blueprint           string  `json:"blueprint"`
+}
+
+func (m *Manifest) Validate() errors.ApiError {
+	m.Org = strings.TrimSpace(m.Org)
+	if m.Org != "" {
+		if err := repositories.ValidateOwner(m.Org); err != nil {
+			return err
+		}
+	}
+	if len(m.Repos) == 0 {
+		return errors.NewBadRequestError("Manifest has no repositories")
+	}
+
+	names := make(map[string]bool)
+	for i := range m.Repos {
+		desired := &m.Repos[i]
+		settings := desired.UpdateRequest()
+		if err := settings.Validate(); err != nil {
+			return errors.NewBadRequestError(fmt.Sprintf("Repository %s: %s", desired.Name, err.Message()))
+		}
+		desired.Name = *settings.Name
+		desired.Description = settings.Description
+		desired.Homepage = settings.Homepage
+		desired.Visibility = settings.Visibility
+		desired.DefaultBranch = settings.DefaultBranch
+		desired.Blueprint = strings.TrimSpace(desired.Blueprint)
+
+		key := strings.ToLower(desired.Name)
+		if names[key] {
+			return errors.NewBadRequestError(fmt.Sprintf("Repository %s is listed more than once", desired.Name))
+		}
+		names[key] = true
+	}
+
+	return nil
+}
+
+// UpdateRequest holds every setting managed by the desired repository.
+func (d *DesiredRepo) UpdateRequest() repositories.UpdateRepoRequest {
+	name := d.Name
+	return repositories.UpdateRepoRequest{
+		Name:                &name,
+		Description:         d.Description,
+		Homepage:            d.Homepage,
+		Visibility:          d.Visibility,
+		DefaultBranch:       d.DefaultBranch,
+		HasIssues:           d.HasIssues,
+		HasProjects:         d.HasProjects,
+		HasWiki:             d.HasWiki,
+		IsTemplate:          d.IsTemplate,
+		AllowSquashMerge:    d.AllowSquashMerge,
+		AllowMergeCommit:    d.AllowMergeCommit,
+		AllowRebaseMerge:    d.AllowRebaseMerge,
+		DeleteBranchOnMerge: d.DeleteBranchOnMerge,
+	}
+}
+
+// CreateRequest is the request creating the desired repository in org. The default
+// branch can only be changed once the repository exists.
+func (d *DesiredRepo) CreateRequest(org string) repositories.CreateRepoRequest {
+	request := repositories.CreateRepoRequest{
+		Org:                 org,
+		Name:                d.Name,
+		IsTemplate:          d.IsTemplate != nil && *d.IsTemplate,
+		AllowSquashMerge:    d.AllowSquashMerge,
+		AllowMergeCommit:    d.AllowMergeCommit,
+		AllowRebaseMerge:    d.AllowRebaseMerge,
+		DeleteBranchOnMerge: d.DeleteBranchOnMerge,
+		Blueprint:           d.Blueprint,
+	}
+	if d.Description != nil {
+		request.Description = *d.Description
+	}
+	if d.Homepage != nil {
+		request.Homepage = *d.Homepage
+	}
+	if d.Visibility != nil {
+		request.Visibility = *d.Visibility
+	}
+	request.HasIssues = d.HasIssues
+	request.HasProjects = d.HasProjects
+	request.HasWiki = d.HasWiki
+	return request
+}
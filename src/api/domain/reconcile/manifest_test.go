@@ -0,0 +1,63 @@
+package reconcile
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestManifest_Validate_NoRepos(t *testing.T) {
+	manifest := Manifest{Org: "my-org"}
+
+	err := manifest.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Manifest has no repositories", err.Message())
+}
+
+func TestManifest_Validate_Duplicated(t *testing.T) {
+	manifest := Manifest{Repos: []DesiredRepo{{Name: "my-repo"}, {Name: " My-Repo "}}}
+
+	err := manifest.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Repository My-Repo is listed more than once", err.Message())
+}
+
+func TestManifest_Validate_InvalidSettings(t *testing.T) {
+	visibility := "secret"
+	manifest := Manifest{Repos: []DesiredRepo{{Name: "my-repo", Visibility: &visibility}}}
+
+	err := manifest.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "Repository my-repo: Invalid repository visibility", err.Message())
+}
+
+func TestManifest_Validate_Normalizes(t *testing.T) {
+	visibility := " Private "
+	manifest := Manifest{Org: " my-org ", Repos: []DesiredRepo{{Name: " my-repo ", Visibility: &visibility}}}
+
+	err := manifest.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org", manifest.Org)
+	assert.EqualValues(t, "my-repo", manifest.Repos[0].Name)
+	assert.EqualValues(t, "private", *manifest.Repos[0].Visibility)
+}
+
+func TestDesiredRepo_CreateRequest(t *testing.T) {
+	description := "my repo"
+	enabled := true
+	desired := DesiredRepo{Name: "my-repo", Description: &description, HasIssues: &enabled, Blueprint: "go-service"}
+
+	request := desired.CreateRequest("my-org")
+
+	assert.EqualValues(t, "my-org", request.Org)
+	assert.EqualValues(t, "my-repo", request.Name)
+	assert.EqualValues(t, "my repo", request.Description)
+	assert.True(t, *request.HasIssues)
+	assert.Nil(t, request.HasWiki)
+	assert.EqualValues(t, "go-service", request.Blueprint)
+}
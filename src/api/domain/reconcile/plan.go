@@ -0,0 +1,46 @@
+package reconcile
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionArchive = "archive"
+)
+
+// Action is a single step bringing a live repository to its desired state. Create and
+// Update hold the request applying it.
+type Action struct {
+	Type    string   `json:"type"`
+	Owner   string   `json:"owner,omitempty"`
+	Name    string   `json:"name"`
+	Changes []Change `json:"changes,omitempty"`
+
+	Create *repositories.CreateRepoRequest `json:"-"`
+	Update *repositories.UpdateRepoRequest `json:"-"`
+}
+
+// Change is a setting whose live value differs from the desired one.
+type Change struct {
+	Field   string      `json:"field"`
+	Live    interface{} `json:"live"`
+	Desired interface{} `json:"desired"`
+}
+
+type ActionResult struct {
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Applied bool            `json:"applied"`
+	Error   errors.ApiError `json:"error,omitempty"`
+}
+
+// ReconcileResponse is the plan, and when it was not a dry run the outcome of each of
+// its actions in the same order.
+type ReconcileResponse struct {
+	DryRun  bool           `json:"dry_run"`
+	Plan    []Action       `json:"plan"`
+	Results []ActionResult `json:"results,omitempty"`
+}
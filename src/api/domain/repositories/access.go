@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+)
+
+const (
+	CollaboratorAdded   = "added"
+	CollaboratorInvited = "invited"
+
+	defaultCollaboratorPermission = "push"
+)
+
+var (
+	permissions = map[string]bool{"pull": true, "triage": true, "push": true, "maintain": true, "admin": true}
+)
+
+// AccessGrant gives a Team of the organization, or a User, one of the pull, triage,
+// push, maintain or admin permissions on a new repository.
+type AccessGrant struct {
+	Team       string `json:"team,omitempty"`
+	User       string `json:"user,omitempty"`
+	Permission string `json:"permission"`
+}
+
+// CollaboratorRequest defaults to the push permission, as github does.
+type CollaboratorRequest struct {
+	Permission string `json:"permission"`
+}
+
+type TeamPermissionRequest struct {
+	Permission string `json:"permission"`
+}
+
+// CollaboratorResponse tells if User got access right away or was sent an invitation
+// that is pending until accepted.
+type CollaboratorResponse struct {
+	User         string `json:"user"`
+	Permission   string `json:"permission"`
+	Status       string `json:"status"`
+	InvitationId int64  `json:"invitation_id,omitempty"`
+	HtmlUrl      string `json:"html_url,omitempty"`
+}
+
+// Invitation is an invitation to collaborate that was not accepted yet.
+type Invitation struct {
+	Id         int64  `json:"id"`
+	User       string `json:"user"`
+	Permission string `json:"permission"`
+	HtmlUrl    string `json:"html_url,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+func ValidatePermission(permission string) errors.ApiError {
+	if !permissions[permission] {
+		return errors.NewBadRequestError(fmt.Sprintf("Invalid permission %s, it must be one of pull, triage, push, maintain or admin", permission))
+	}
+
+	return nil
+}
+
+// ValidateLogin checks the login of a github user.
+func ValidateLogin(login string) errors.ApiError {
+	if !isValidOwner(login) {
+		return errors.NewBadRequestError("Invalid user login")
+	}
+
+	return nil
+}
+
+func (r *CollaboratorRequest) Validate() errors.ApiError {
+	r.Permission = strings.ToLower(strings.TrimSpace(r.Permission))
+	if r.Permission == "" {
+		r.Permission = defaultCollaboratorPermission
+	}
+
+	return ValidatePermission(r.Permission)
+}
+
+func (r *TeamPermissionRequest) Validate() errors.ApiError {
+	r.Permission = strings.ToLower(strings.TrimSpace(r.Permission))
+	return ValidatePermission(r.Permission)
+}
+
+func (g *AccessGrant) Validate() errors.ApiError {
+	g.Team = strings.TrimSpace(g.Team)
+	g.User = strings.TrimSpace(g.User)
+	if (g.Team == "") == (g.User == "") {
+		return errors.NewBadRequestError("Access must be granted to either a team or a user")
+	}
+	if g.User != "" {
+		if err := ValidateLogin(g.User); err != nil {
+			return err
+		}
+	}
+
+	g.Permission = strings.ToLower(strings.TrimSpace(g.Permission))
+	return ValidatePermission(g.Permission)
+}
+
+// validateAccess checks the initial access list, teams only exist within organizations.
+func (r *CreateRepoRequest) validateAccess() errors.ApiError {
+	for i := range r.Access {
+		if err := r.Access[i].Validate(); err != nil {
+			return err
+		}
+		if r.Access[i].Team != "" && r.Org == "" {
+			return errors.NewBadRequestError(fmt.Sprintf("Access for team %s needs an organization repository", r.Access[i].Team))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCollaboratorRequest_Validate_DefaultPermission(t *testing.T) {
+	request := CollaboratorRequest{}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "push", request.Permission)
+}
+
+func TestTeamPermissionRequest_Validate_InvalidPermission(t *testing.T) {
+	for _, permission := range []string{"", "write", "owner"} {
+		request := TeamPermissionRequest{Permission: permission}
+
+		err := request.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "Invalid permission "+permission+", it must be one of pull, triage, push, maintain or admin", err.Message())
+	}
+}
+
+func TestAccessGrant_Validate_TeamOrUser(t *testing.T) {
+	for _, grant := range []AccessGrant{{Permission: "push"}, {Team: "platform", User: "octocat", Permission: "push"}} {
+		err := grant.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, "Access must be granted to either a team or a user", err.Message())
+	}
+}
+
+func TestAccessGrant_Validate_InvalidUser(t *testing.T) {
+	grant := AccessGrant{User: "-octocat", Permission: "push"}
+
+	err := grant.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid user login", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_TeamAccessNeedsOrg(t *testing.T) {
+	request := CreateRepoRequest{Name: "my-repo", Access: []AccessGrant{{Team: "platform", Permission: "Maintain"}}}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Access for team platform needs an organization repository", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_Access(t *testing.T) {
+	request := CreateRepoRequest{Org: "my-org", Name: "my-repo", Access: []AccessGrant{
+		{Team: " platform ", Permission: "Maintain"},
+		{User: "octocat", Permission: "pull"},
+	}}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, AccessGrant{Team: "platform", Permission: "maintain"}, request.Access[0])
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	StepTopics           = "topics"
+	StepLabel            = "label"
+	StepLabelDelete      = "label_delete"
+	StepMilestone        = "milestone"
+	StepTeam             = "team"
+	StepCollaborator     = "collaborator"
+	StepBranchProtection = "branch_protection"
+
+	maxTopics      = 20
+	maxTopicLength = 50
+)
+
+var (
+	validTopic      = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	validLabelColor = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+)
+
+// BlueprintsFile is the layout of the blueprints configuration file. The protection
+// policies it declares can be referenced by name from blueprints and requests.
+type BlueprintsFile struct {
+	ProtectionPolicies map[string]ProtectionPolicy `json:"protection_policies" yaml:"protection_policies"`
+	Blueprints         map[string]Blueprint        `json:"blueprints" yaml:"blueprints"`
+}
+
+// Blueprint describes how a kind of repository is set up, so callers only have to pick
+// a name. Its settings are the defaults of the requests created from it. Files are
+// committed along with the files of Seed, which win on the same path.
+type Blueprint struct {
+	Description         string       `json:"description" yaml:"description"`
+	Homepage            string       `json:"homepage" yaml:"homepage"`
+	Private             bool         `json:"private" yaml:"private"`
+	Visibility          string       `json:"visibility" yaml:"visibility"`
+	HasIssues           bool         `json:"has_issues" yaml:"has_issues"`
+	HasProjects         bool         `json:"has_projects" yaml:"has_projects"`
+	HasWiki             bool         `json:"has_wiki" yaml:"has_wiki"`
+	AutoInit            bool         `json:"auto_init" yaml:"auto_init"`
+	GitignoreTemplate   string       `json:"gitignore_template" yaml:"gitignore_template"`
+	LicenseTemplate     string       `json:"license_template" yaml:"license_template"`
+	AllowSquashMerge    *bool        `json:"allow_squash_merge" yaml:"allow_squash_merge"`
+	AllowMergeCommit    *bool        `json:"allow_merge_commit" yaml:"allow_merge_commit"`
+	AllowRebaseMerge    *bool        `json:"allow_rebase_merge" yaml:"allow_rebase_merge"`
+	DeleteBranchOnMerge *bool        `json:"delete_branch_on_merge" yaml:"delete_branch_on_merge"`
+	ProtectionPolicy    string       `json:"protection_policy" yaml:"protection_policy"`
+	Topics              []string     `json:"topics" yaml:"topics"`
+	Labels              []Label      `json:"labels" yaml:"labels"`
+	PruneLabels         bool         `json:"prune_labels" yaml:"prune_labels"`
+	Milestones          []Milestone  `json:"milestones" yaml:"milestones"`
+	Teams               []TeamAccess `json:"teams" yaml:"teams"`
+	Files               []SeedFile   `json:"files" yaml:"files"`
+	Seed                *SeedRequest `json:"seed" yaml:"seed"`
+}
+
+// Label is created on the repository, or updated when github already created it.
+type Label struct {
+	Name        string `json:"name" yaml:"name"`
+	Color       string `json:"color" yaml:"color"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// TeamAccess grants an organization team one of the pull, triage, push, maintain or admin permissions.
+type TeamAccess struct {
+	Team       string `json:"team" yaml:"team"`
+	Permission string `json:"permission" yaml:"permission"`
+}
+
+func (b *Blueprint) Validate() errors.ApiError {
+	switch strings.ToLower(b.Visibility) {
+	case "", VisibilityPublic, VisibilityPrivate, VisibilityInternal:
+	default:
+		return errors.NewBadRequestError("Invalid repository visibility")
+	}
+
+	if b.ProtectionPolicy != "" {
+		if _, err := GetProtectionPolicy(b.ProtectionPolicy); err != nil {
+			return err
+		}
+		if !b.AutoInit && b.Seed == nil && len(b.Files) == 0 {
+			return errors.NewBadRequestError("Protection policy needs auto_init, files or a seed, github cannot protect a branch of an empty repository")
+		}
+	}
+
+	if err := ValidateTopics(b.Topics); err != nil {
+		return err
+	}
+	if err := validateLabels(b.Labels); err != nil {
+		return err
+	}
+	if err := validateMilestones(b.Milestones); err != nil {
+		return err
+	}
+	for _, access := range b.Teams {
+		if strings.TrimSpace(access.Team) == "" || !permissions[access.Permission] {
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid access for team %s", access.Team))
+		}
+	}
+	for _, file := range b.Files {
+		if !isValidFilePath(file.Path) {
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid file path %s", file.Path))
+		}
+	}
+	if b.Seed != nil {
+		if err := b.Seed.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateTopics follows the github rules: lowercase letters, numbers and hyphens,
+// at most 50 characters each and 20 per repository.
+func ValidateTopics(topics []string) errors.ApiError {
+	if len(topics) > maxTopics {
+		return errors.NewBadRequestError("A repository can not have more than 20 topics")
+	}
+	for _, topic := range topics {
+		if len(topic) > maxTopicLength || !validTopic.MatchString(topic) {
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid topic %s", topic))
+		}
+	}
+
+	return nil
+}
+
+func isValidFilePath(path string) bool {
+	if path == "" || strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") {
+		return false
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns request completed with the blueprint settings. The request values win
+// when set, false included, so a request can turn off a feature the blueprint enables.
+// The settings github cannot apply to a template are left out for template requests.
+func (b *Blueprint) Apply(request CreateRepoRequest) CreateRepoRequest {
+	merged := request
+	if merged.Description == "" {
+		merged.Description = b.Description
+	}
+	if merged.Visibility == "" && !merged.Private {
+		merged.Visibility = b.Visibility
+		merged.Private = b.Private
+	}
+	merged.HasIssues = defaultFlag(merged.HasIssues, b.HasIssues)
+	merged.HasProjects = defaultFlag(merged.HasProjects, b.HasProjects)
+	merged.HasWiki = defaultFlag(merged.HasWiki, b.HasWiki)
+	if merged.ProtectionPolicy == "" {
+		merged.ProtectionPolicy = b.ProtectionPolicy
+	}
+	if len(merged.Topics) == 0 {
+		merged.Topics = append([]string(nil), b.Topics...)
+	}
+	merged.Labels = MergeLabels(b.Labels, merged.Labels)
+	merged.PruneLabels = merged.PruneLabels || b.PruneLabels
+	merged.Milestones = append(append([]Milestone(nil), b.Milestones...), merged.Milestones...)
+	if b.Seed != nil || len(b.Files) > 0 {
+		seed := b.seed()
+		if merged.Seed != nil {
+			seed = merged.Seed.Merge(seed)
+		}
+		merged.Seed = &seed
+	}
+	if merged.Template != nil {
+		return merged
+	}
+
+	if merged.Homepage == "" {
+		merged.Homepage = b.Homepage
+	}
+	merged.AutoInit = defaultFlag(merged.AutoInit, b.AutoInit)
+	if merged.GitignoreTemplate == "" {
+		merged.GitignoreTemplate = b.GitignoreTemplate
+	}
+	if merged.LicenseTemplate == "" {
+		merged.LicenseTemplate = b.LicenseTemplate
+	}
+	if merged.AllowSquashMerge == nil {
+		merged.AllowSquashMerge = b.AllowSquashMerge
+	}
+	if merged.AllowMergeCommit == nil {
+		merged.AllowMergeCommit = b.AllowMergeCommit
+	}
+	if merged.AllowRebaseMerge == nil {
+		merged.AllowRebaseMerge = b.AllowRebaseMerge
+	}
+	if merged.DeleteBranchOnMerge == nil {
+		merged.DeleteBranchOnMerge = b.DeleteBranchOnMerge
+	}
+
+	return merged
+}
+
+// defaultFlag returns flag, or the blueprint value when the request leaves it unset.
+func defaultFlag(flag *bool, blueprint bool) *bool {
+	if flag != nil || !blueprint {
+		return flag
+	}
+	return &blueprint
+}
+
+// seed folds Files into Seed, for them to land in the same commit.
+func (b *Blueprint) seed() SeedRequest {
+	var seed SeedRequest
+	if b.Seed != nil {
+		seed = *b.Seed
+	}
+	seed.Files = MergeSeedFiles(b.Files, seed.Files)
+	return seed
+}
+
+// Copy returns a blueprint that shares no slice with b.
+func (b Blueprint) Copy() Blueprint {
+	b.Topics = append([]string(nil), b.Topics...)
+	b.Labels = append([]Label(nil), b.Labels...)
+	b.Milestones = append([]Milestone(nil), b.Milestones...)
+	b.Teams = append([]TeamAccess(nil), b.Teams...)
+	b.Files = append([]SeedFile(nil), b.Files...)
+	if b.Seed != nil {
+		seed := b.Seed.Merge(SeedRequest{})
+		b.Seed = &seed
+	}
+	return b
+}
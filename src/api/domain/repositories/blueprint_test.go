@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBlueprint_Validate_Invalid(t *testing.T) {
+	blueprints := map[string]Blueprint{
+		"Invalid repository visibility":     {Visibility: "secret"},
+		"Unknown protection policy unknown": {ProtectionPolicy: "unknown", AutoInit: true},
+		"Protection policy needs auto_init, files or a seed, github cannot protect a branch of an empty repository": {ProtectionPolicy: "strict"},
+		"Invalid topic Go": {Topics: []string{"Go"}},
+		"Invalid label bug, it needs a name and a 6 digit hex color": {Labels: []Label{{Name: "bug", Color: "red"}}},
+		"Invalid access for team platform":                           {Teams: []TeamAccess{{Team: "platform", Permission: "write"}}},
+		"Invalid file path ../secrets":                               {Files: []SeedFile{{Path: "../secrets"}}},
+	}
+
+	for message, blueprint := range blueprints {
+		err := blueprint.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, message, err.Message())
+	}
+}
+
+func TestBlueprint_Validate(t *testing.T) {
+	blueprint := Blueprint{
+		Visibility:       VisibilityPrivate,
+		AutoInit:         true,
+		ProtectionPolicy: "reviewed",
+		Topics:           []string{"go", "micro-service"},
+		Labels:           []Label{{Name: "bug", Color: "d73a4a"}},
+		Teams:            []TeamAccess{{Team: "platform", Permission: "maintain"}},
+		Files:            []SeedFile{{Path: ".github/CODEOWNERS", Content: "* @my-org/platform"}},
+	}
+
+	assert.Nil(t, blueprint.Validate())
+}
+
+func TestBlueprint_Apply(t *testing.T) {
+	disabled := false
+	enabled := true
+	blueprint := Blueprint{
+		Description:      "a go service",
+		Visibility:       VisibilityPrivate,
+		HasIssues:        true,
+		AutoInit:         true,
+		LicenseTemplate:  "mit",
+		AllowMergeCommit: &disabled,
+		ProtectionPolicy: "reviewed",
+	}
+
+	merged := blueprint.Apply(CreateRepoRequest{Name: "my-service", Description: "my service", AllowMergeCommit: &enabled})
+
+	assert.EqualValues(t, "my-service", merged.Name)
+	assert.EqualValues(t, "my service", merged.Description)
+	assert.EqualValues(t, VisibilityPrivate, merged.Visibility)
+	assert.True(t, *merged.HasIssues)
+	assert.Nil(t, merged.HasWiki)
+	assert.True(t, *merged.AutoInit)
+	assert.EqualValues(t, "mit", merged.LicenseTemplate)
+	assert.True(t, *merged.AllowMergeCommit)
+	assert.EqualValues(t, "reviewed", merged.ProtectionPolicy)
+}
+
+func TestBlueprint_Apply_RequestTurnsFeaturesOff(t *testing.T) {
+	disabled := false
+	blueprint := Blueprint{HasIssues: true, HasWiki: true, AutoInit: true}
+
+	merged := blueprint.Apply(CreateRepoRequest{Name: "my-service", HasWiki: &disabled, AutoInit: &disabled})
+
+	assert.True(t, *merged.HasIssues)
+	assert.False(t, *merged.HasWiki)
+	assert.False(t, *merged.AutoInit)
+}
+
+func TestBlueprint_Apply_FilesJoinTheSeed(t *testing.T) {
+	blueprint := Blueprint{
+		Files: []SeedFile{{Path: "CODEOWNERS", Content: "* @my-org/platform"}, {Path: "README.md", Content: "# service"}},
+		Seed:  &SeedRequest{Template: "go-service", Files: []SeedFile{{Path: "README.md", Content: "# go service"}}},
+	}
+
+	merged := blueprint.Apply(CreateRepoRequest{Name: "my-service", Seed: &SeedRequest{Files: []SeedFile{{Path: "CODEOWNERS", Content: "* @my-org/payments"}}}})
+
+	assert.EqualValues(t, "go-service", merged.Seed.Template)
+	assert.EqualValues(t, []SeedFile{{Path: "README.md", Content: "# go service"}, {Path: "CODEOWNERS", Content: "* @my-org/payments"}}, merged.Seed.Files)
+
+	merged = (&Blueprint{Files: blueprint.Files}).Apply(CreateRepoRequest{Name: "my-service"})
+
+	assert.EqualValues(t, blueprint.Files, merged.Seed.Files)
+	assert.EqualValues(t, 1, len(blueprint.Seed.Files))
+}
+
+func TestBlueprint_Apply_Metadata(t *testing.T) {
+	blueprint := Blueprint{
+		Topics:      []string{"go"},
+		Labels:      []Label{{Name: "bug", Color: "d73a4a"}, {Name: "ops", Color: "000000"}},
+		PruneLabels: true,
+		Milestones:  []Milestone{{Title: "v1"}},
+	}
+
+	merged := blueprint.Apply(CreateRepoRequest{
+		Name:       "my-service",
+		Labels:     []Label{{Name: "Bug", Color: "ffffff"}},
+		Milestones: []Milestone{{Title: "v2"}},
+	})
+
+	assert.EqualValues(t, []string{"go"}, merged.Topics)
+	assert.EqualValues(t, []Label{{Name: "ops", Color: "000000"}, {Name: "Bug", Color: "ffffff"}}, merged.Labels)
+	assert.True(t, merged.PruneLabels)
+	assert.EqualValues(t, []Milestone{{Title: "v1"}, {Title: "v2"}}, merged.Milestones)
+}
+
+func TestBlueprint_Apply_Template(t *testing.T) {
+	blueprint := Blueprint{AutoInit: true, LicenseTemplate: "mit", HasWiki: true}
+
+	merged := blueprint.Apply(CreateRepoRequest{Name: "my-service", Template: &TemplateSource{Owner: "my-org", Name: "template"}})
+
+	assert.Nil(t, merged.AutoInit)
+	assert.EqualValues(t, "", merged.LicenseTemplate)
+	assert.True(t, *merged.HasWiki)
+	assert.Nil(t, merged.Validate())
+}
+
+func TestBlueprint_Copy(t *testing.T) {
+	blueprint := Blueprint{Topics: []string{"go"}}
+
+	copied := blueprint.Copy()
+	copied.Topics[0] = "java"
+
+	assert.EqualValues(t, "go", blueprint.Topics[0])
+}
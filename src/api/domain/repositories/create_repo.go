@@ -1,36 +1,391 @@
 package repositories
 
 import (
+	"fmt"
 	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 )
 
+const (
+	VisibilityPublic   = "public"
+	VisibilityPrivate  = "private"
+	VisibilityInternal = "internal"
+
+	maxNameLength        = 100
+	maxOrgLength         = 39
+	maxDescriptionLength = 350
+)
+
+var (
+	validName            = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	validOrg             = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	validLicenseTemplate = regexp.MustCompile(`^[a-z0-9.-]+$`)
+)
+
 type CreateRepoRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Org                 string `json:"org"`
+	TeamId              int64  `json:"team_id"`
+	Name                string `json:"name"`
+	Description         string `json:"description"`
+	Homepage            string `json:"homepage"`
+	Private             bool   `json:"private"`
+	Visibility          string `json:"visibility"`
+	HasIssues           *bool  `json:"has_issues"`
+	HasProjects         *bool  `json:"has_projects"`
+	HasWiki             *bool  `json:"has_wiki"`
+	IsTemplate          bool   `json:"is_template"`
+	AutoInit            *bool  `json:"auto_init"`
+	GitignoreTemplate   string `json:"gitignore_template"`
+	LicenseTemplate     string `json:"license_template"`
+	AllowSquashMerge    *bool  `json:"allow_squash_merge"`
+	AllowMergeCommit    *bool  `json:"allow_merge_commit"`
+	AllowRebaseMerge    *bool  `json:"allow_rebase_merge"`
+	DeleteBranchOnMerge *bool  `json:"delete_branch_on_merge"`
+
+	// Template generates the repository out of a template repository instead of
+	// creating it empty. The feature settings (issues, wiki...) are copied from it.
+	Template *TemplateSource `json:"template"`
+
+	// ProtectionPolicy names the branch protection applied to the default branch
+	// once the repository exists, it needs the repository to have content.
+	ProtectionPolicy string `json:"protection_policy"`
+
+	// Blueprint names the server side blueprint the repository is created from, the
+	// other fields override the blueprint settings when set.
+	Blueprint string `json:"blueprint"`
+
+	// Topics, Labels and Milestones are set up once the repository exists. PruneLabels
+	// deletes the labels github creates by default that are not in Labels.
+	Topics      []string    `json:"topics"`
+	Labels      []Label     `json:"labels"`
+	PruneLabels bool        `json:"prune_labels"`
+	Milestones  []Milestone `json:"milestones"`
+
+	// Seed commits the initial content of the repository in a single commit, replacing
+	// the one github creates for auto_init.
+	Seed *SeedRequest `json:"seed"`
+
+	// Access is granted once the repository exists, after the teams of the blueprint.
+	// Users who are not members of the organization are sent an invitation.
+	Access []AccessGrant `json:"access"`
+
+	// CiWebhook registers the CI webhook of this api on the repository. It is done by
+	// default when one is configured, false opts out.
+	CiWebhook *bool `json:"ci_webhook"`
+}
+
+type TemplateSource struct {
+	Owner              string `json:"owner"`
+	Name               string `json:"name"`
+	IncludeAllBranches bool   `json:"include_all_branches"`
+}
+
+func ValidateRepoName(name string) errors.ApiError {
+	if name == "" || len(name) > maxNameLength || !validName.MatchString(name) || name == "." || name == ".." {
+		return errors.NewBadRequestError("Invalid repository name")
+	}
+
+	return nil
+}
+
+// ValidateOwner checks the login of the user or organization owning a repository.
+func ValidateOwner(owner string) errors.ApiError {
+	if !isValidOwner(owner) {
+		return errors.NewBadRequestError("Invalid repository owner")
+	}
+
+	return nil
+}
+
+func isValidOwner(owner string) bool {
+	return len(owner) <= maxOrgLength && validOrg.MatchString(owner)
+}
+
+func validateHomepage(homepage string) errors.ApiError {
+	if homepage == "" {
+		return nil
+	}
+
+	u, err := url.ParseRequestURI(homepage)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewBadRequestError("Invalid repository homepage")
+	}
+
+	return nil
 }
 
 func (r *CreateRepoRequest) Validate() errors.ApiError {
 	r.Name = strings.TrimSpace(r.Name)
-	if r.Name == "" {
-		return errors.NewBadRequestError("Invalid repository name")
+	if err := ValidateRepoName(r.Name); err != nil {
+		return err
+	}
+
+	if err := r.validateOrg(); err != nil {
+		return err
+	}
+
+	r.Description = strings.TrimSpace(r.Description)
+	if len(r.Description) > maxDescriptionLength {
+		return errors.NewBadRequestError("Invalid repository description")
+	}
+
+	r.Homepage = strings.TrimSpace(r.Homepage)
+	if err := validateHomepage(r.Homepage); err != nil {
+		return err
+	}
+
+	if err := r.validateVisibility(); err != nil {
+		return err
+	}
+
+	r.GitignoreTemplate = strings.TrimSpace(r.GitignoreTemplate)
+	if strings.ContainsAny(r.GitignoreTemplate, " /\\") {
+		return errors.NewBadRequestError("Invalid gitignore template")
+	}
+
+	r.LicenseTemplate = strings.ToLower(strings.TrimSpace(r.LicenseTemplate))
+	if r.LicenseTemplate != "" && !validLicenseTemplate.MatchString(r.LicenseTemplate) {
+		return errors.NewBadRequestError("Invalid license template")
+	}
+
+	if !r.allowsAnyMergeMethod() {
+		return errors.NewBadRequestError("At least one merge method must be allowed")
+	}
+
+	if err := r.validateTemplate(); err != nil {
+		return err
+	}
+
+	if err := ValidateTopics(r.Topics); err != nil {
+		return err
+	}
+	if err := validateLabels(r.Labels); err != nil {
+		return err
+	}
+	if err := validateMilestones(r.Milestones); err != nil {
+		return err
+	}
+
+	if err := r.validateAccess(); err != nil {
+		return err
+	}
+	if r.Seed != nil {
+		if err := r.Seed.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return r.validateProtectionPolicy()
+}
+
+func (r *CreateRepoRequest) validateProtectionPolicy() errors.ApiError {
+	r.ProtectionPolicy = strings.TrimSpace(r.ProtectionPolicy)
+	if r.ProtectionPolicy == "" {
+		return nil
+	}
+
+	if _, err := GetProtectionPolicy(r.ProtectionPolicy); err != nil {
+		return err
+	}
+	if (r.AutoInit == nil || !*r.AutoInit) && r.Template == nil && r.Seed == nil {
+		return errors.NewBadRequestError("Protection policy needs auto_init, a seed or a template, github cannot protect a branch of an empty repository")
 	}
 
 	return nil
 }
 
+// validateTemplate rejects the settings github cannot apply when generating a repository.
+func (r *CreateRepoRequest) validateTemplate() errors.ApiError {
+	if r.Template == nil {
+		return nil
+	}
+
+	r.Template.Owner = strings.TrimSpace(r.Template.Owner)
+	if !isValidOwner(r.Template.Owner) {
+		return errors.NewBadRequestError("Invalid template owner")
+	}
+	r.Template.Name = strings.TrimSpace(r.Template.Name)
+	if ValidateRepoName(r.Template.Name) != nil {
+		return errors.NewBadRequestError("Invalid template name")
+	}
+
+	unsupported := []struct {
+		setting string
+		set     bool
+	}{
+		{"team_id", r.TeamId != 0},
+		{"homepage", r.Homepage != ""},
+		{"visibility internal", r.Visibility == VisibilityInternal},
+		{"is_template", r.IsTemplate},
+		{"auto_init", r.AutoInit != nil && *r.AutoInit},
+		{"gitignore_template", r.GitignoreTemplate != ""},
+		{"license_template", r.LicenseTemplate != ""},
+		{"merge settings", r.AllowSquashMerge != nil || r.AllowMergeCommit != nil || r.AllowRebaseMerge != nil || r.DeleteBranchOnMerge != nil},
+	}
+	for _, option := range unsupported {
+		if option.set {
+			return errors.NewBadRequestError(fmt.Sprintf("Setting %s cannot be combined with a template", option.setting))
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateRepoRequest) validateOrg() errors.ApiError {
+	r.Org = strings.TrimSpace(r.Org)
+	if r.Org == "" {
+		if r.TeamId != 0 {
+			return errors.NewBadRequestError("Team id is only allowed for organization repositories")
+		}
+		return nil
+	}
+
+	if !isValidOwner(r.Org) {
+		return errors.NewBadRequestError("Invalid organization name")
+	}
+	if r.TeamId < 0 {
+		return errors.NewBadRequestError("Invalid team id")
+	}
+
+	return nil
+}
+
+func (r *CreateRepoRequest) validateVisibility() errors.ApiError {
+	r.Visibility = strings.ToLower(strings.TrimSpace(r.Visibility))
+	switch r.Visibility {
+	case "":
+		return nil
+	case VisibilityPublic:
+		if r.Private {
+			return errors.NewBadRequestError("Visibility public conflicts with private repository")
+		}
+	case VisibilityPrivate:
+		r.Private = true
+	case VisibilityInternal:
+		if r.Org == "" {
+			return errors.NewBadRequestError("Visibility internal is only allowed for organization repositories")
+		}
+		if r.Private {
+			return errors.NewBadRequestError("Visibility internal conflicts with private repository")
+		}
+	default:
+		return errors.NewBadRequestError("Invalid repository visibility")
+	}
+
+	return nil
+}
+
+func (r *CreateRepoRequest) allowsAnyMergeMethod() bool {
+	for _, allowed := range []*bool{r.AllowSquashMerge, r.AllowMergeCommit, r.AllowRebaseMerge} {
+		if allowed == nil || *allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateRepoResponse is the created repository along with the outcome of the steps
+// applied once it exists.
 type CreateRepoResponse struct {
-	Id    int64  `json:"id"`
-	Owner string `json:"owner"`
-	Name  string `json:"name"`
+	Repository
+	Steps []StepResult `json:"steps,omitempty"`
+}
+
+// StepResult tells if a step applied to a new repository succeeded, Target being what
+// the step worked on (a branch, a label...). The repository is kept even when it did not.
+type StepResult struct {
+	Step    string          `json:"step"`
+	Target  string          `json:"target,omitempty"`
+	Applied bool            `json:"applied"`
+	Error   errors.ApiError `json:"error,omitempty"`
 }
 
 type CreateReposResponse struct {
 	StatusCode int                 `json:"status"`
+	Summary    CreateReposSummary  `json:"summary"`
 	Results    []CreateReposResult `json:"result"`
 }
 
+// Summarize counts the outcome of every result and sets the aggregate status code:
+// 201 when all were created, 206 when some were, otherwise the first error status.
+// A repository an atomic batch failed to roll back overrides it with the status of
+// the first rollback error, the batch having left a repository behind.
+func (r *CreateReposResponse) Summarize() {
+	r.Summary = CreateReposSummary{Total: len(r.Results)}
+	var firstError errors.ApiError
+	var firstSkipped errors.ApiError
+	var firstRollbackError errors.ApiError
+	for _, current := range r.Results {
+		switch {
+		case current.Skipped:
+			r.Summary.Skipped++
+			if firstSkipped == nil {
+				firstSkipped = current.Error
+			}
+		case current.Error != nil:
+			r.Summary.Failed++
+			if firstError == nil {
+				firstError = current.Error
+			}
+		case current.Compensation != nil && current.Compensation.RolledBack:
+			r.Summary.RolledBack++
+		case current.Compensation != nil:
+			r.Summary.RollbackFailed++
+			if firstRollbackError == nil {
+				firstRollbackError = current.Compensation.Error
+			}
+		default:
+			r.Summary.Succeeded++
+		}
+	}
+	if firstError == nil {
+		firstError = firstSkipped
+	}
+
+	switch {
+	case firstRollbackError != nil:
+		r.StatusCode = firstRollbackError.Status()
+	case r.Summary.Succeeded == r.Summary.Total:
+		r.StatusCode = http.StatusCreated
+	case r.Summary.Succeeded == 0 && firstError != nil:
+		r.StatusCode = firstError.Status()
+	default:
+		r.StatusCode = http.StatusPartialContent
+	}
+}
+
+// CreateReposStreamSummary is the last event of a streamed batch.
+type CreateReposStreamSummary struct {
+	StatusCode int                `json:"status"`
+	Summary    CreateReposSummary `json:"summary"`
+}
+
+type CreateReposSummary struct {
+	Total          int `json:"total"`
+	Succeeded      int `json:"succeeded"`
+	Failed         int `json:"failed"`
+	Skipped        int `json:"skipped"`
+	RolledBack     int `json:"rolled_back,omitempty"`
+	RollbackFailed int `json:"rollback_failed,omitempty"`
+}
+
+// CreateReposResult is the outcome of a single batch item, Index being its position
+// in the original request.
 type CreateReposResult struct {
-	Response *CreateRepoResponse `json:"repo"`
-	Error    errors.ApiError     `json:"error"`
+	Index        int                 `json:"index"`
+	Name         string              `json:"name"`
+	Skipped      bool                `json:"skipped,omitempty"`
+	Response     *CreateRepoResponse `json:"repo"`
+	Error        errors.ApiError     `json:"error"`
+	Compensation *CompensationResult `json:"compensation,omitempty"`
+}
+
+// CompensationResult tells if a repository created by a failed atomic batch was deleted.
+type CompensationResult struct {
+	RolledBack bool            `json:"rolled_back"`
+	Error      errors.ApiError `json:"error,omitempty"`
 }
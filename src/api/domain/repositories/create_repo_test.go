@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCreateRepoRequest_Validate_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "  ", "my repo", "..", "repo/name"} {
+		req := CreateRepoRequest{Name: name}
+		err := req.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "Invalid repository name", err.Message())
+	}
+}
+
+func TestCreateRepoRequest_Validate_InvalidHomepage(t *testing.T) {
+	req := CreateRepoRequest{Name: "github-repo", Homepage: "not a url"}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid repository homepage", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_InvalidVisibility(t *testing.T) {
+	req := CreateRepoRequest{Name: "github-repo", Visibility: "secret"}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid repository visibility", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_VisibilityConflict(t *testing.T) {
+	req := CreateRepoRequest{Name: "github-repo", Visibility: "public", Private: true}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Visibility public conflicts with private repository", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_NoMergeMethod(t *testing.T) {
+	disallow := false
+	req := CreateRepoRequest{
+		Name:             "github-repo",
+		AllowSquashMerge: &disallow,
+		AllowMergeCommit: &disallow,
+		AllowRebaseMerge: &disallow,
+	}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "At least one merge method must be allowed", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_Normalizes(t *testing.T) {
+	req := CreateRepoRequest{
+		Name:            " github-repo ",
+		Visibility:      "Private",
+		LicenseTemplate: "MIT",
+	}
+	err := req.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "github-repo", req.Name)
+	assert.EqualValues(t, "private", req.Visibility)
+	assert.True(t, req.Private)
+	assert.EqualValues(t, "mit", req.LicenseTemplate)
+}
+
+func TestCreateRepoRequest_Validate_InternalRequiresOrg(t *testing.T) {
+	req := CreateRepoRequest{Name: "github-repo", Visibility: "internal"}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Visibility internal is only allowed for organization repositories", err.Message())
+
+	req = CreateRepoRequest{Name: "github-repo", Org: "my-org", Visibility: "internal"}
+	assert.Nil(t, req.Validate())
+}
+
+func TestCreateRepoRequest_Validate_TeamIdRequiresOrg(t *testing.T) {
+	req := CreateRepoRequest{Name: "github-repo", TeamId: 12}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Team id is only allowed for organization repositories", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_InvalidOrg(t *testing.T) {
+	req := CreateRepoRequest{Name: "github-repo", Org: "-my_org"}
+	err := req.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid organization name", err.Message())
+}
+
+func TestCreateReposResponse_Summarize(t *testing.T) {
+	response := CreateReposResponse{Results: []CreateReposResult{
+		{Index: 0, Response: &CreateRepoResponse{Repository: Repository{Id: 1}}},
+		{Index: 1, Error: errors.NewBadRequestError("Invalid repository name")},
+		{Index: 2, Skipped: true, Error: errors.NewApiError(http.StatusRequestTimeout, "skipped")},
+	}}
+
+	response.Summarize()
+
+	assert.EqualValues(t, http.StatusPartialContent, response.StatusCode)
+	assert.EqualValues(t, CreateReposSummary{Total: 3, Succeeded: 1, Failed: 1, Skipped: 1}, response.Summary)
+}
+
+func TestCreateReposResponse_Summarize_RollbackFailed(t *testing.T) {
+	response := CreateReposResponse{Results: []CreateReposResult{
+		{Index: 0, Response: &CreateRepoResponse{Repository: Repository{Id: 1}}, Compensation: &CompensationResult{RolledBack: true}},
+		{Index: 1, Response: &CreateRepoResponse{Repository: Repository{Id: 2}}, Compensation: &CompensationResult{Error: errors.NewInternalServerError("Server Error")}},
+		{Index: 2, Error: errors.NewBadRequestError("Invalid repository name")},
+	}}
+
+	response.Summarize()
+
+	assert.EqualValues(t, http.StatusInternalServerError, response.StatusCode)
+	assert.EqualValues(t, CreateReposSummary{Total: 3, Failed: 1, RolledBack: 1, RollbackFailed: 1}, response.Summary)
+}
+
+func TestCreateReposResponse_Summarize_AllFailed(t *testing.T) {
+	response := CreateReposResponse{Results: []CreateReposResult{
+		{Index: 0, Error: errors.NewApiError(http.StatusUnauthorized, "Requires authentication")},
+		{Index: 1, Error: errors.NewBadRequestError("Invalid repository name")},
+	}}
+
+	response.Summarize()
+
+	assert.EqualValues(t, http.StatusUnauthorized, response.StatusCode)
+	assert.EqualValues(t, 2, response.Summary.Failed)
+}
+
+func TestUpdateRepoRequest_Validate_InvalidName(t *testing.T) {
+	name := "   "
+	request := UpdateRepoRequest{Name: &name}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid repository name", err.Message())
+}
+
+func TestUpdateRepoRequest_Validate_NoMergeMethod(t *testing.T) {
+	disabled := false
+	request := UpdateRepoRequest{AllowSquashMerge: &disabled, AllowMergeCommit: &disabled, AllowRebaseMerge: &disabled}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "At least one merge method must be allowed", err.Message())
+}
+
+func TestUpdateRepoRequest_Validate_Normalizes(t *testing.T) {
+	description := "  a description  "
+	visibility := " Private "
+	request := UpdateRepoRequest{Description: &description, Visibility: &visibility}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "a description", *request.Description)
+	assert.EqualValues(t, "private", *request.Visibility)
+}
+
+func TestCreateRepoRequest_Validate_InvalidTemplate(t *testing.T) {
+	request := CreateRepoRequest{Name: "my-repo", Template: &TemplateSource{Owner: "-bad-", Name: "template"}}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid template owner", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_TemplateUnsupportedSetting(t *testing.T) {
+	enabled := true
+	request := CreateRepoRequest{Name: "my-repo", AutoInit: &enabled, Template: &TemplateSource{Owner: "my-org", Name: "template"}}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "Setting auto_init cannot be combined with a template", err.Message())
+}
+
+func TestCreateRepoRequest_Validate_Template(t *testing.T) {
+	request := CreateRepoRequest{Name: "my-repo", Private: true, Template: &TemplateSource{Owner: " my-org ", Name: "template", IncludeAllBranches: true}}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org", request.Template.Owner)
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+)
+
+// ForkRepoRequest forks a repository into the authenticated user account, or into Org
+// when set. An empty Name keeps the name of the source repository.
+type ForkRepoRequest struct {
+	Org               string `json:"org"`
+	Name              string `json:"name"`
+	DefaultBranchOnly bool   `json:"default_branch_only"`
+}
+
+// ForkRepoResponse is the fork github created. Github copies the content in the
+// background, Ready tells if it was seen finished before answering.
+type ForkRepoResponse struct {
+	Repository
+	Ready bool `json:"ready"`
+}
+
+func (r *ForkRepoRequest) Validate() errors.ApiError {
+	r.Org = strings.TrimSpace(r.Org)
+	if r.Org != "" && !isValidOwner(r.Org) {
+		return errors.NewBadRequestError("Invalid organization name")
+	}
+
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name != "" {
+		return ValidateRepoName(r.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	StepWebhook = "webhook"
+
+	HookContentJson = "json"
+	HookContentForm = "form"
+
+	// StepWebhookCi is the target of the webhook step, the url of our CI is not echoed.
+	StepWebhookCi = "ci"
+)
+
+var (
+	defaultHookEvents = []string{"push"}
+	validHookEvent    = regexp.MustCompile(`^([a-z_]+|\*)$`)
+)
+
+// HookRequest registers a webhook delivering Events to Url, push only when empty. With
+// GenerateSecret a random secret is created and returned once, in the response.
+type HookRequest struct {
+	Url            string   `json:"url"`
+	ContentType    string   `json:"content_type"`
+	Secret         string   `json:"secret"`
+	GenerateSecret bool     `json:"generate_secret"`
+	Events         []string `json:"events"`
+	Active         *bool    `json:"active"`
+	InsecureSsl    bool     `json:"insecure_ssl"`
+}
+
+// UpdateHookRequest only changes the fields that are set.
+type UpdateHookRequest struct {
+	Url            string   `json:"url"`
+	ContentType    string   `json:"content_type"`
+	Secret         string   `json:"secret"`
+	GenerateSecret bool     `json:"generate_secret"`
+	Events         []string `json:"events"`
+	Active         *bool    `json:"active"`
+	InsecureSsl    *bool    `json:"insecure_ssl"`
+}
+
+// Webhook never holds the secret, except the one just generated for it.
+type Webhook struct {
+	Id           int64    `json:"id"`
+	Url          string   `json:"url"`
+	ContentType  string   `json:"content_type"`
+	Events       []string `json:"events"`
+	Active       bool     `json:"active"`
+	InsecureSsl  bool     `json:"insecure_ssl"`
+	Secret       string   `json:"secret,omitempty"`
+	LastResponse string   `json:"last_response,omitempty"`
+	CreatedAt    string   `json:"created_at,omitempty"`
+	UpdatedAt    string   `json:"updated_at,omitempty"`
+}
+
+func (r *HookRequest) Validate() errors.ApiError {
+	r.Url = strings.TrimSpace(r.Url)
+	if err := validateHookUrl(r.Url); err != nil {
+		return err
+	}
+
+	r.ContentType = strings.ToLower(strings.TrimSpace(r.ContentType))
+	if r.ContentType == "" {
+		r.ContentType = HookContentJson
+	}
+	if err := validateHookSettings(r.ContentType, r.Secret, r.GenerateSecret, r.Events); err != nil {
+		return err
+	}
+	if len(r.Events) == 0 {
+		r.Events = defaultHookEvents
+	}
+
+	return nil
+}
+
+func (r *UpdateHookRequest) Validate() errors.ApiError {
+	r.Url = strings.TrimSpace(r.Url)
+	if r.Url != "" {
+		if err := validateHookUrl(r.Url); err != nil {
+			return err
+		}
+	}
+	r.ContentType = strings.ToLower(strings.TrimSpace(r.ContentType))
+	if err := validateHookSettings(r.ContentType, r.Secret, r.GenerateSecret, r.Events); err != nil {
+		return err
+	}
+
+	if r.Active == nil && len(r.Events) == 0 && !r.HasConfig() {
+		return errors.NewBadRequestError("Nothing to update")
+	}
+	return nil
+}
+
+// HasConfig tells if the request changes where or how the events are delivered.
+func (r UpdateHookRequest) HasConfig() bool {
+	return r.Url != "" || r.ContentType != "" || r.Secret != "" || r.GenerateSecret || r.InsecureSsl != nil
+}
+
+// validateHookUrl only accepts absolute http urls, github refusing the other ones anyway.
+func validateHookUrl(hookUrl string) errors.ApiError {
+	parsed, err := url.Parse(hookUrl)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return errors.NewBadRequestError("Invalid webhook url, it must be an absolute http or https url")
+	}
+
+	return nil
+}
+
+func validateHookSettings(contentType string, secret string, generateSecret bool, events []string) errors.ApiError {
+	if contentType != "" && contentType != HookContentJson && contentType != HookContentForm {
+		return errors.NewBadRequestError(fmt.Sprintf("Invalid content type %s, it must be %s or %s", contentType, HookContentJson, HookContentForm))
+	}
+	if secret != "" && generateSecret {
+		return errors.NewBadRequestError("Secret and generate_secret cannot be both set")
+	}
+
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		if !validHookEvent.MatchString(event) {
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid webhook event %s", event))
+		}
+		if seen[event] {
+			return errors.NewBadRequestError(fmt.Sprintf("Webhook event %s is listed more than once", event))
+		}
+		seen[event] = true
+	}
+
+	return nil
+}
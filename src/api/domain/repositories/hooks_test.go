@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestHookRequest_Validate(t *testing.T) {
+	request := HookRequest{Url: " https://ci.example.com/hook "}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "https://ci.example.com/hook", request.Url)
+	assert.EqualValues(t, HookContentJson, request.ContentType)
+	assert.EqualValues(t, []string{"push"}, request.Events)
+}
+
+func TestHookRequest_ValidateErrors(t *testing.T) {
+	for message, request := range map[string]HookRequest{
+		"Invalid webhook url, it must be an absolute http or https url": {Url: "ftp://ci.example.com"},
+		"Invalid content type xml, it must be json or form":             {Url: "https://ci.example.com", ContentType: "xml"},
+		"Secret and generate_secret cannot be both set":                 {Url: "https://ci.example.com", Secret: "s3cr3t", GenerateSecret: true},
+		"Invalid webhook event Push":                                    {Url: "https://ci.example.com", Events: []string{"Push"}},
+		"Webhook event push is listed more than once":                   {Url: "https://ci.example.com", Events: []string{"push", "push"}},
+	} {
+		err := request.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, message, err.Message())
+	}
+}
+
+func TestUpdateHookRequest_Validate(t *testing.T) {
+	empty := UpdateHookRequest{}
+	err := empty.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Nothing to update", err.Message())
+
+	active := false
+	request := UpdateHookRequest{Active: &active}
+	assert.Nil(t, request.Validate())
+	assert.False(t, request.HasConfig())
+
+	request = UpdateHookRequest{GenerateSecret: true}
+	assert.Nil(t, request.Validate())
+	assert.True(t, request.HasConfig())
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxPerPage = 100
+)
+
+var (
+	userRepoTypes = map[string]bool{"all": true, "owner": true, "public": true, "private": true, "member": true}
+	orgRepoTypes  = map[string]bool{"all": true, "public": true, "private": true, "forks": true, "sources": true, "member": true}
+	listSorts     = map[string]bool{"created": true, "updated": true, "pushed": true, "full_name": true}
+	listOrders    = map[string]bool{"asc": true, "desc": true}
+)
+
+// ListReposRequest lists the repositories of the authenticated user, or of Org when set.
+// Cursor is the next_cursor of a previous page, the filters must be sent again with it.
+type ListReposRequest struct {
+	Org        string `form:"org"`
+	Type       string `form:"type"`
+	Visibility string `form:"visibility"`
+	Sort       string `form:"sort"`
+	Direction  string `form:"direction"`
+	PerPage    int    `form:"per_page"`
+	Cursor     string `form:"cursor"`
+	All        bool   `form:"all"`
+
+	// Page is decoded from Cursor by Validate.
+	Page int `form:"-"`
+}
+
+type ListReposResponse struct {
+	Repos      []Repository `json:"repos"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type ListReposSummary struct {
+	Total int `json:"total"`
+}
+
+func (r *ListReposRequest) Validate() errors.ApiError {
+	r.Org = strings.TrimSpace(r.Org)
+	if r.Org != "" {
+		if err := ValidateOwner(r.Org); err != nil {
+			return err
+		}
+	}
+
+	r.Type = strings.ToLower(strings.TrimSpace(r.Type))
+	r.Visibility = strings.ToLower(strings.TrimSpace(r.Visibility))
+	r.Sort = strings.ToLower(strings.TrimSpace(r.Sort))
+	r.Direction = strings.ToLower(strings.TrimSpace(r.Direction))
+
+	types := userRepoTypes
+	if r.Org != "" {
+		types = orgRepoTypes
+	}
+	if r.Type != "" && !types[r.Type] {
+		return errors.NewBadRequestError("Invalid repository type")
+	}
+
+	if r.Visibility != "" {
+		switch r.Visibility {
+		case "all", VisibilityPublic, VisibilityPrivate:
+		default:
+			return errors.NewBadRequestError("Invalid repository visibility")
+		}
+		// github rejects visibility for org listings and when combined with type
+		if r.Org != "" || r.Type != "" {
+			return errors.NewBadRequestError("Visibility can only be used for the authenticated user and without type")
+		}
+	}
+
+	if r.Sort != "" && !listSorts[r.Sort] {
+		return errors.NewBadRequestError("Invalid sort")
+	}
+	if r.Direction != "" && !listOrders[r.Direction] {
+		return errors.NewBadRequestError("Invalid direction")
+	}
+	if r.PerPage < 0 || r.PerPage > maxPerPage {
+		return errors.NewBadRequestError("Invalid per_page, it must be between 1 and 100")
+	}
+
+	r.Page = 0
+	if r.Cursor != "" {
+		page, err := decodeCursor(r.Cursor)
+		if err != nil {
+			return err
+		}
+		r.Page = page
+	}
+	return nil
+}
+
+// NewCursor turns the url of the next github page into an opaque cursor, or an empty
+// string when there is no next page.
+func NewCursor(nextUrl string) string {
+	if nextUrl == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(nextUrl)
+	if err != nil {
+		return ""
+	}
+	page, err := strconv.Atoi(parsed.Query().Get("page"))
+	if err != nil || page <= 0 {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(page)))
+}
+
+func decodeCursor(cursor string) (int, errors.ApiError) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.NewBadRequestError("Invalid cursor")
+	}
+
+	page, err := strconv.Atoi(string(decoded))
+	if err != nil || page <= 0 {
+		return 0, errors.NewBadRequestError("Invalid cursor")
+	}
+	return page, nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestListReposRequest_Validate_InvalidFilters(t *testing.T) {
+	requests := []ListReposRequest{
+		{Type: "forks"},
+		{Org: "my-org", Type: "owner"},
+		{Visibility: "internal"},
+		{Org: "my-org", Visibility: "public"},
+		{Type: "owner", Visibility: "public"},
+		{Sort: "stars"},
+		{Direction: "up"},
+		{PerPage: 101},
+		{Cursor: "not a cursor"},
+	}
+
+	for _, request := range requests {
+		err := request.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	}
+}
+
+func TestListReposRequest_Validate_Cursor(t *testing.T) {
+	request := ListReposRequest{Sort: " Updated ", Cursor: NewCursor("https://api.github.com/user/repos?page=4&sort=updated")}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "updated", request.Sort)
+	assert.EqualValues(t, 4, request.Page)
+}
+
+func TestNewCursor_NoNextPage(t *testing.T) {
+	assert.EqualValues(t, "", NewCursor(""))
+	assert.EqualValues(t, "", NewCursor("https://api.github.com/user/repos"))
+}
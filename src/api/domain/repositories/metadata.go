@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+	"time"
+)
+
+const (
+	LabelCreated   = "created"
+	LabelUpdated   = "updated"
+	LabelDeleted   = "deleted"
+	LabelUnchanged = "unchanged"
+
+	MilestoneOpen   = "open"
+	MilestoneClosed = "closed"
+
+	dueOnDateLayout = "2006-01-02"
+)
+
+type TopicsRequest struct {
+	Topics []string `json:"topics"`
+}
+
+type TopicsResponse struct {
+	Topics []string `json:"topics"`
+}
+
+// LabelsRequest is the complete label set of a repository, the labels not listed are deleted.
+// Deleting every label takes an explicit PruneAll, an empty set being refused otherwise.
+type LabelsRequest struct {
+	Labels   []Label `json:"labels"`
+	PruneAll bool    `json:"prune_all"`
+}
+
+// LabelResult tells what syncing did to a single label.
+type LabelResult struct {
+	Name    string          `json:"name"`
+	Action  string          `json:"action"`
+	Applied bool            `json:"applied"`
+	Error   errors.ApiError `json:"error,omitempty"`
+}
+
+type LabelsResponse struct {
+	Results []LabelResult `json:"results"`
+}
+
+// Milestone is created open unless State says otherwise. DueOn takes a date, or a
+// timestamp, and is sent to github as a timestamp.
+type Milestone struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description" yaml:"description"`
+	State       string `json:"state" yaml:"state"`
+	DueOn       string `json:"due_on" yaml:"due_on"`
+}
+
+type MilestonesRequest struct {
+	Milestones []Milestone `json:"milestones"`
+}
+
+type MilestoneResult struct {
+	Title   string          `json:"title"`
+	Number  int             `json:"number,omitempty"`
+	HtmlUrl string          `json:"html_url,omitempty"`
+	Applied bool            `json:"applied"`
+	Error   errors.ApiError `json:"error,omitempty"`
+}
+
+type MilestonesResponse struct {
+	Results []MilestoneResult `json:"results"`
+}
+
+func (r *TopicsRequest) Validate() errors.ApiError {
+	return ValidateTopics(r.Topics)
+}
+
+func (r *LabelsRequest) Validate() errors.ApiError {
+	if len(r.Labels) == 0 && !r.PruneAll {
+		return errors.NewBadRequestError("No labels to sync, set prune_all to delete every label")
+	}
+	return validateLabels(r.Labels)
+}
+
+// validateLabels trims the labels in place and rejects the names listed more than once,
+// github label names being case insensitive.
+func validateLabels(labels []Label) errors.ApiError {
+	names := make(map[string]bool, len(labels))
+	for i := range labels {
+		label := &labels[i]
+		label.Name = strings.TrimSpace(label.Name)
+		label.Description = strings.TrimSpace(label.Description)
+		label.Color = strings.TrimSpace(label.Color)
+		if label.Name == "" || !validLabelColor.MatchString(label.Color) {
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid label %s, it needs a name and a 6 digit hex color", label.Name))
+		}
+
+		key := strings.ToLower(label.Name)
+		if names[key] {
+			return errors.NewBadRequestError(fmt.Sprintf("Label %s is listed more than once", label.Name))
+		}
+		names[key] = true
+	}
+
+	return nil
+}
+
+func (r *MilestonesRequest) Validate() errors.ApiError {
+	if len(r.Milestones) == 0 {
+		return errors.NewBadRequestError("No milestones to create")
+	}
+
+	return validateMilestones(r.Milestones)
+}
+
+func validateMilestones(milestones []Milestone) errors.ApiError {
+	for i := range milestones {
+		if err := milestones[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Milestone) Validate() errors.ApiError {
+	m.Title = strings.TrimSpace(m.Title)
+	if m.Title == "" {
+		return errors.NewBadRequestError("Invalid milestone title")
+	}
+
+	m.State = strings.ToLower(strings.TrimSpace(m.State))
+	switch m.State {
+	case "", MilestoneOpen, MilestoneClosed:
+	default:
+		return errors.NewBadRequestError(fmt.Sprintf("Invalid state for milestone %s", m.Title))
+	}
+
+	m.DueOn = strings.TrimSpace(m.DueOn)
+	if m.DueOn == "" {
+		return nil
+	}
+	if date, err := time.Parse(dueOnDateLayout, m.DueOn); err == nil {
+		m.DueOn = date.Format(time.RFC3339)
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, m.DueOn); err != nil {
+		return errors.NewBadRequestError(fmt.Sprintf("Invalid due date for milestone %s", m.Title))
+	}
+
+	return nil
+}
+
+// MergeLabels returns base with the labels of overrides replacing the ones with the same name.
+func MergeLabels(base []Label, overrides []Label) []Label {
+	merged := make([]Label, 0, len(base)+len(overrides))
+	overridden := make(map[string]bool, len(overrides))
+	for _, label := range overrides {
+		overridden[strings.ToLower(label.Name)] = true
+	}
+	for _, label := range base {
+		if !overridden[strings.ToLower(label.Name)] {
+			merged = append(merged, label)
+		}
+	}
+
+	return append(merged, overrides...)
+}
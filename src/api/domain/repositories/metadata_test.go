@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestMilestone_Validate_DueDate(t *testing.T) {
+	milestone := Milestone{Title: " v1.0 ", State: "Closed", DueOn: "2026-12-31"}
+
+	err := milestone.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "v1.0", milestone.Title)
+	assert.EqualValues(t, MilestoneClosed, milestone.State)
+	assert.EqualValues(t, "2026-12-31T00:00:00Z", milestone.DueOn)
+}
+
+func TestMilestone_Validate_Invalid(t *testing.T) {
+	for message, milestone := range map[string]Milestone{
+		"Invalid milestone title":           {Title: " "},
+		"Invalid state for milestone v1":    {Title: "v1", State: "done"},
+		"Invalid due date for milestone v1": {Title: "v1", DueOn: "next week"},
+	} {
+		err := milestone.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, message, err.Message())
+	}
+}
+
+func TestMilestonesRequest_Validate_Empty(t *testing.T) {
+	request := MilestonesRequest{}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "No milestones to create", err.Message())
+}
+
+func TestLabelsRequest_Validate_Duplicated(t *testing.T) {
+	request := LabelsRequest{Labels: []Label{{Name: "bug", Color: "d73a4a"}, {Name: " Bug ", Color: "ffffff"}}}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Label Bug is listed more than once", err.Message())
+}
+
+func TestLabelsRequest_Validate_Empty(t *testing.T) {
+	for _, request := range []LabelsRequest{{}, {Labels: []Label{}}} {
+		err := request.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "No labels to sync, set prune_all to delete every label", err.Message())
+	}
+
+	request := LabelsRequest{PruneAll: true}
+	assert.Nil(t, request.Validate())
+}
+
+func TestTopicsRequest_Validate(t *testing.T) {
+	request := TopicsRequest{Topics: []string{"Go"}}
+
+	err := request.Validate()
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid topic Go", err.Message())
+}
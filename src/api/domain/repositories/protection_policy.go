@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+	"sync"
+)
+
+const (
+	maxRequiredReviews = 6
+)
+
+var (
+	policiesMutex      sync.RWMutex
+	protectionPolicies = map[string]ProtectionPolicy{
+		"reviewed": {
+			RequiredReviews:     1,
+			DismissStaleReviews: true,
+		},
+		"strict": {
+			RequiredReviews:         2,
+			DismissStaleReviews:     true,
+			RequireCodeOwnerReviews: true,
+			StrictStatusChecks:      true,
+			LinearHistory:           true,
+			EnforceAdmins:           true,
+		},
+	}
+)
+
+// ProtectionPolicy is a named set of branch protection rules, applied to the default
+// branch of a repository once it exists. Zero RequiredReviews means no review is needed.
+type ProtectionPolicy struct {
+	RequiredReviews         int      `json:"required_reviews" yaml:"required_reviews"`
+	DismissStaleReviews     bool     `json:"dismiss_stale_reviews" yaml:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews bool     `json:"require_code_owner_reviews" yaml:"require_code_owner_reviews"`
+	StatusChecks            []string `json:"status_checks" yaml:"status_checks"`
+	StrictStatusChecks      bool     `json:"strict_status_checks" yaml:"strict_status_checks"`
+	LinearHistory           bool     `json:"linear_history" yaml:"linear_history"`
+	EnforceAdmins           bool     `json:"enforce_admins" yaml:"enforce_admins"`
+}
+
+func GetProtectionPolicy(name string) (*ProtectionPolicy, errors.ApiError) {
+	policiesMutex.RLock()
+	defer policiesMutex.RUnlock()
+
+	policy, ok := protectionPolicies[name]
+	if !ok {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Unknown protection policy %s", name))
+	}
+
+	return &policy, nil
+}
+
+// RegisterProtectionPolicy makes policy available under name, replacing any policy
+// already registered with it.
+func RegisterProtectionPolicy(name string, policy ProtectionPolicy) errors.ApiError {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.NewBadRequestError("Invalid protection policy name")
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	policiesMutex.Lock()
+	defer policiesMutex.Unlock()
+
+	protectionPolicies[name] = policy
+	return nil
+}
+
+func (p *ProtectionPolicy) Validate() errors.ApiError {
+	if p.RequiredReviews < 0 || p.RequiredReviews > maxRequiredReviews {
+		return errors.NewBadRequestError("Invalid required reviews, it must be between 0 and 6")
+	}
+	if p.RequiredReviews == 0 && (p.DismissStaleReviews || p.RequireCodeOwnerReviews) {
+		return errors.NewBadRequestError("Review settings need at least one required review")
+	}
+
+	for i, check := range p.StatusChecks {
+		p.StatusChecks[i] = strings.TrimSpace(check)
+		if p.StatusChecks[i] == "" {
+			return errors.NewBadRequestError("Invalid status check")
+		}
+	}
+
+	return nil
+}
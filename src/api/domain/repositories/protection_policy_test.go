@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestGetProtectionPolicy_Unknown(t *testing.T) {
+	policy, err := GetProtectionPolicy("unknown")
+
+	assert.Nil(t, policy)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "Unknown protection policy unknown", err.Message())
+}
+
+func TestGetProtectionPolicy_Strict(t *testing.T) {
+	policy, err := GetProtectionPolicy("strict")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, policy.RequiredReviews)
+	assert.True(t, policy.LinearHistory)
+	assert.True(t, policy.EnforceAdmins)
+}
+
+func TestProtectionPolicy_Validate(t *testing.T) {
+	policies := []ProtectionPolicy{
+		{RequiredReviews: 7},
+		{DismissStaleReviews: true},
+		{StatusChecks: []string{"ci", " "}},
+	}
+
+	for _, policy := range policies {
+		assert.NotNil(t, policy.Validate())
+	}
+	assert.Nil(t, (&ProtectionPolicy{RequiredReviews: 1, StatusChecks: []string{" ci "}}).Validate())
+}
+
+func TestCreateRepoRequest_Validate_ProtectionPolicy(t *testing.T) {
+	enabled := true
+	request := CreateRepoRequest{Name: "my-repo", ProtectionPolicy: "strict"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Protection policy needs auto_init, a seed or a template, github cannot protect a branch of an empty repository", err.Message())
+
+	request = CreateRepoRequest{Name: "my-repo", ProtectionPolicy: "unknown", AutoInit: &enabled}
+	err = request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Unknown protection policy unknown", err.Message())
+
+	request = CreateRepoRequest{Name: "my-repo", ProtectionPolicy: " strict ", AutoInit: &enabled}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "strict", request.ProtectionPolicy)
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+)
+
+// Repository is the representation of a repository returned by every endpoint.
+type Repository struct {
+	Id                  int64  `json:"id"`
+	Owner               string `json:"owner"`
+	Name                string `json:"name"`
+	Description         string `json:"description,omitempty"`
+	Homepage            string `json:"homepage,omitempty"`
+	HtmlUrl             string `json:"html_url,omitempty"`
+	DefaultBranch       string `json:"default_branch,omitempty"`
+	Private             bool   `json:"private"`
+	Visibility          string `json:"visibility,omitempty"`
+	HasIssues           bool   `json:"has_issues"`
+	HasProjects         bool   `json:"has_projects"`
+	HasWiki             bool   `json:"has_wiki"`
+	IsTemplate          bool   `json:"is_template"`
+	AllowSquashMerge    bool   `json:"allow_squash_merge"`
+	AllowMergeCommit    bool   `json:"allow_merge_commit"`
+	AllowRebaseMerge    bool   `json:"allow_rebase_merge"`
+	DeleteBranchOnMerge bool   `json:"delete_branch_on_merge"`
+	Archived            bool   `json:"archived"`
+}
+
+// GetVisibility falls back on the private flag when github did not report the visibility.
+func (r Repository) GetVisibility() string {
+	if r.Visibility != "" {
+		return r.Visibility
+	}
+	if r.Private {
+		return VisibilityPrivate
+	}
+	return VisibilityPublic
+}
+
+// UpdateRepoRequest only changes the settings that are set.
+type UpdateRepoRequest struct {
+	Name                *string `json:"name"`
+	Description         *string `json:"description"`
+	Homepage            *string `json:"homepage"`
+	Private             *bool   `json:"private"`
+	Visibility          *string `json:"visibility"`
+	DefaultBranch       *string `json:"default_branch"`
+	HasIssues           *bool   `json:"has_issues"`
+	HasProjects         *bool   `json:"has_projects"`
+	HasWiki             *bool   `json:"has_wiki"`
+	IsTemplate          *bool   `json:"is_template"`
+	AllowSquashMerge    *bool   `json:"allow_squash_merge"`
+	AllowMergeCommit    *bool   `json:"allow_merge_commit"`
+	AllowRebaseMerge    *bool   `json:"allow_rebase_merge"`
+	DeleteBranchOnMerge *bool   `json:"delete_branch_on_merge"`
+}
+
+func (r *UpdateRepoRequest) Validate() errors.ApiError {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if err := ValidateRepoName(name); err != nil {
+			return err
+		}
+		r.Name = &name
+	}
+
+	if r.Description != nil {
+		description := strings.TrimSpace(*r.Description)
+		if len(description) > maxDescriptionLength {
+			return errors.NewBadRequestError("Invalid repository description")
+		}
+		r.Description = &description
+	}
+
+	if r.Homepage != nil {
+		homepage := strings.TrimSpace(*r.Homepage)
+		if err := validateHomepage(homepage); err != nil {
+			return err
+		}
+		r.Homepage = &homepage
+	}
+
+	if r.Visibility != nil {
+		visibility := strings.ToLower(strings.TrimSpace(*r.Visibility))
+		switch visibility {
+		case VisibilityPublic, VisibilityPrivate, VisibilityInternal:
+		default:
+			return errors.NewBadRequestError("Invalid repository visibility")
+		}
+		if r.Private != nil && *r.Private != (visibility == VisibilityPrivate) {
+			return errors.NewBadRequestError("Visibility " + visibility + " conflicts with private setting")
+		}
+		r.Visibility = &visibility
+	}
+
+	if r.DefaultBranch != nil {
+		branch := strings.TrimSpace(*r.DefaultBranch)
+		if branch == "" || strings.ContainsAny(branch, " ~^:?*[\\") {
+			return errors.NewBadRequestError("Invalid default branch")
+		}
+		r.DefaultBranch = &branch
+	}
+
+	for _, allowed := range []*bool{r.AllowSquashMerge, r.AllowMergeCommit, r.AllowRebaseMerge} {
+		if allowed == nil || *allowed {
+			return nil
+		}
+	}
+	return errors.NewBadRequestError("At least one merge method must be allowed")
+}
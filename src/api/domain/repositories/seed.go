@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	StepSeed = "seed"
+	// SeedTemplateSuffix marks the template files whose content is rendered, the other
+	// ones being committed as they are, e.g. workflows full of ${{ github.sha }}.
+	SeedTemplateSuffix = ".tmpl"
+
+	defaultSeedMessage = "Initial commit"
+)
+
+var (
+	validSeedTemplate = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// SeedRequest commits Files, along with the files of the server side Template rendered
+// with Variables, in a single commit. Inline files win over template files with the
+// same path. An empty Branch means the default branch of the repository.
+type SeedRequest struct {
+	Files     []SeedFile        `json:"files" yaml:"files"`
+	Template  string            `json:"template" yaml:"template"`
+	Variables map[string]string `json:"variables" yaml:"variables"`
+	Message   string            `json:"message" yaml:"message"`
+	Branch    string            `json:"branch" yaml:"branch"`
+
+	// Replace makes the seed commit the only commit of the branch, it is only set for
+	// the repositories this api just created.
+	Replace bool `json:"-" yaml:"-"`
+}
+
+type SeedFile struct {
+	Path       string `json:"path" yaml:"path"`
+	Content    string `json:"content" yaml:"content"`
+	Executable bool   `json:"executable" yaml:"executable"`
+}
+
+// SeedData is what template files and paths can refer to, e.g. {{.Name}} or {{.Vars.team}}.
+type SeedData struct {
+	Owner  string
+	Name   string
+	Branch string
+	Vars   map[string]string
+}
+
+type SeedResponse struct {
+	Branch  string   `json:"branch"`
+	Commit  string   `json:"commit"`
+	HtmlUrl string   `json:"html_url,omitempty"`
+	Files   []string `json:"files"`
+}
+
+func (r *SeedRequest) Validate() errors.ApiError {
+	r.Template = strings.TrimSpace(r.Template)
+	if len(r.Files) == 0 && r.Template == "" {
+		return errors.NewBadRequestError("No files to seed")
+	}
+	if r.Template != "" && (!validSeedTemplate.MatchString(r.Template) || r.Template == "." || r.Template == "..") {
+		return errors.NewBadRequestError("Invalid seed template")
+	}
+
+	paths := make(map[string]bool, len(r.Files))
+	for _, file := range r.Files {
+		if !isValidFilePath(file.Path) {
+			return errors.NewBadRequestError(fmt.Sprintf("Invalid file path %s", file.Path))
+		}
+		if paths[file.Path] {
+			return errors.NewBadRequestError(fmt.Sprintf("File %s is listed more than once", file.Path))
+		}
+		paths[file.Path] = true
+	}
+
+	r.Message = strings.TrimSpace(r.Message)
+	if r.Message == "" {
+		r.Message = defaultSeedMessage
+	}
+	r.Branch = strings.TrimSpace(r.Branch)
+	if strings.ContainsAny(r.Branch, " ~^:?*[\\") {
+		return errors.NewBadRequestError("Invalid branch")
+	}
+
+	return nil
+}
+
+// Merge returns r completed with base, r winning on the template, the variables and
+// the files with the same path.
+func (r SeedRequest) Merge(base SeedRequest) SeedRequest {
+	merged := r
+	merged.Files = MergeSeedFiles(base.Files, r.Files)
+	if merged.Template == "" {
+		merged.Template = base.Template
+	}
+	if merged.Message == "" {
+		merged.Message = base.Message
+	}
+	if merged.Branch == "" {
+		merged.Branch = base.Branch
+	}
+	merged.Variables = make(map[string]string, len(base.Variables)+len(r.Variables))
+	for _, variables := range []map[string]string{base.Variables, r.Variables} {
+		for key, value := range variables {
+			merged.Variables[key] = value
+		}
+	}
+	return merged
+}
+
+// RenderSeedFiles renders the path of every template file with data, and the content
+// of the ones ending with SeedTemplateSuffix, dropping the suffix. A reference to a
+// missing variable is an error rather than an empty string.
+func RenderSeedFiles(files []SeedFile, data SeedData) ([]SeedFile, errors.ApiError) {
+	rendered := make([]SeedFile, 0, len(files))
+	for _, file := range files {
+		path, err := render(file.Path, file.Path, data)
+		if err != nil {
+			return nil, err
+		}
+		content := file.Content
+		if strings.HasSuffix(path, SeedTemplateSuffix) {
+			path = strings.TrimSuffix(path, SeedTemplateSuffix)
+			if content, err = render(file.Path, file.Content, data); err != nil {
+				return nil, err
+			}
+		}
+		if !isValidFilePath(path) {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid file path %s", path))
+		}
+		rendered = append(rendered, SeedFile{Path: path, Content: content, Executable: file.Executable})
+	}
+
+	return rendered, nil
+}
+
+func render(name string, text string, data SeedData) (string, errors.ApiError) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", errors.NewBadRequestError(fmt.Sprintf("Invalid seed template file %s: %s", name, err.Error()))
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", errors.NewBadRequestError(fmt.Sprintf("Cannot render seed template file %s: %s", name, err.Error()))
+	}
+	return out.String(), nil
+}
+
+// MergeSeedFiles returns base with the files of overrides replacing the ones with the same path.
+func MergeSeedFiles(base []SeedFile, overrides []SeedFile) []SeedFile {
+	merged := make([]SeedFile, 0, len(base)+len(overrides))
+	overridden := make(map[string]bool, len(overrides))
+	for _, file := range overrides {
+		overridden[file.Path] = true
+	}
+	for _, file := range base {
+		if !overridden[file.Path] {
+			merged = append(merged, file)
+		}
+	}
+
+	return append(merged, overrides...)
+}
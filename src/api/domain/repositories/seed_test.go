@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestSeedRequest_Validate(t *testing.T) {
+	request := SeedRequest{Files: []SeedFile{{Path: "README.md", Content: "# my-repo"}}}
+
+	err := request.Validate()
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Initial commit", request.Message)
+}
+
+func TestSeedRequest_ValidateErrors(t *testing.T) {
+	for _, request := range []SeedRequest{
+		{},
+		{Template: "../go-service"},
+		{Files: []SeedFile{{Path: "/etc/passwd"}}},
+		{Files: []SeedFile{{Path: "README.md"}, {Path: "README.md"}}},
+		{Template: "go-service", Branch: "bad branch"},
+	} {
+		err := request.Validate()
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	}
+}
+
+func TestSeedRequest_Merge(t *testing.T) {
+	base := SeedRequest{
+		Template:  "go-service",
+		Variables: map[string]string{"team": "platform", "go": "1.21"},
+		Files:     []SeedFile{{Path: "README.md", Content: "base"}, {Path: "LICENSE", Content: "MIT"}},
+	}
+	request := SeedRequest{
+		Variables: map[string]string{"team": "payments"},
+		Files:     []SeedFile{{Path: "README.md", Content: "mine"}},
+	}
+
+	merged := request.Merge(base)
+
+	assert.EqualValues(t, "go-service", merged.Template)
+	assert.EqualValues(t, map[string]string{"team": "payments", "go": "1.21"}, merged.Variables)
+	assert.EqualValues(t, []SeedFile{{Path: "LICENSE", Content: "MIT"}, {Path: "README.md", Content: "mine"}}, merged.Files)
+	assert.EqualValues(t, "platform", base.Variables["team"])
+}
+
+func TestRenderSeedFiles(t *testing.T) {
+	files := []SeedFile{
+		{Path: "README.md.tmpl", Content: "# {{.Name}}\n\nOwned by {{.Vars.team}}.\n"},
+		{Path: "cmd/{{.Name}}/main.go", Content: "package main\n", Executable: true},
+	}
+
+	rendered, err := RenderSeedFiles(files, SeedData{Owner: "my-org", Name: "billing", Vars: map[string]string{"team": "payments"}})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []SeedFile{
+		{Path: "README.md", Content: "# billing\n\nOwned by payments.\n"},
+		{Path: "cmd/billing/main.go", Content: "package main\n", Executable: true},
+	}, rendered)
+}
+
+func TestRenderSeedFiles_KeepsWorkflows(t *testing.T) {
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.sha }} ${{ secrets.TOKEN }}\n"
+	files := []SeedFile{
+		{Path: ".github/workflows/ci.yml", Content: workflow},
+		{Path: ".github/workflows/{{.Name}}.yml.tmpl", Content: "name: {{.Name}}\nenv:\n  SHA: {{\"${{ github.sha }}\"}}\n"},
+	}
+
+	rendered, err := RenderSeedFiles(files, SeedData{Owner: "my-org", Name: "billing"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []SeedFile{
+		{Path: ".github/workflows/ci.yml", Content: workflow},
+		{Path: ".github/workflows/billing.yml", Content: "name: billing\nenv:\n  SHA: ${{ github.sha }}\n"},
+	}, rendered)
+}
+
+func TestRenderSeedFiles_MissingVariable(t *testing.T) {
+	rendered, err := RenderSeedFiles([]SeedFile{{Path: "CODEOWNERS.tmpl", Content: "* @{{.Owner}}/{{.Vars.team}}\n"}}, SeedData{Owner: "my-org", Name: "billing"})
+
+	assert.Nil(t, rendered)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
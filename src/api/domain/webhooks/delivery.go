@@ -0,0 +1,152 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	StatusReceived   = "received"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusDeadLetter = "dead_letter"
+
+	// TriggerDelivery is the first attempt, as github delivered the event. Redeliveries
+	// from github and replays start a new run of attempts, retries continue it.
+	TriggerDelivery   = "delivery"
+	TriggerRedelivery = "redelivery"
+	TriggerReplay     = "replay"
+	TriggerRetry      = "retry"
+)
+
+// Delivery is a single event github delivered, Id being unique across redeliveries
+// of the same event. It is kept along with every attempt at handling it.
+type Delivery struct {
+	Id             string            `json:"id"`
+	Event          string            `json:"event"`
+	Action         string            `json:"action,omitempty"`
+	Repository     string            `json:"repository,omitempty"`
+	InstallationId int64             `json:"installation_id,omitempty"`
+	ReceivedAt     time.Time         `json:"received_at"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Payload        json.RawMessage   `json:"payload,omitempty"`
+	Status         string            `json:"status"`
+	Attempts       []Attempt         `json:"attempts"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at,omitempty"`
+}
+
+// Attempt is one run of the handlers of a delivery. A retry only runs the handlers
+// that failed in the previous attempt.
+type Attempt struct {
+	Trigger    string           `json:"trigger"`
+	StartedAt  time.Time        `json:"started_at"`
+	DurationMs int64            `json:"duration_ms"`
+	Handlers   []HandlerOutcome `json:"handlers"`
+}
+
+type HandlerOutcome struct {
+	Name       string `json:"name"`
+	Succeeded  bool   `json:"succeeded"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// DeliveryFilter selects the deliveries listed, newest first. Empty fields match all.
+type DeliveryFilter struct {
+	Status string
+	Event  string
+	Limit  int
+}
+
+// RetryPolicy retries a failed delivery after Backoff, doubled on each retry, until
+// MaxAttempts attempts of the same run failed. The delivery is then dead lettered.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func (a Attempt) Failed() []string {
+	var failed []string
+	for _, outcome := range a.Handlers {
+		if !outcome.Succeeded {
+			failed = append(failed, outcome.Name)
+		}
+	}
+	return failed
+}
+
+// RunAttempts counts the attempts of the current run, the last delivery, redelivery
+// or replay and the retries that followed it.
+func (d *Delivery) RunAttempts() int {
+	count := 0
+	for i := len(d.Attempts) - 1; i >= 0; i-- {
+		count++
+		if d.Attempts[i].Trigger != TriggerRetry {
+			break
+		}
+	}
+	return count
+}
+
+// AddAttempt records attempt and updates the status of the delivery, scheduling a
+// retry according to policy when handlers failed.
+func (d *Delivery) AddAttempt(attempt Attempt, policy RetryPolicy) {
+	d.Attempts = append(d.Attempts, attempt)
+	d.NextAttemptAt = nil
+	if len(attempt.Failed()) == 0 {
+		d.Status = StatusSucceeded
+		return
+	}
+
+	runAttempts := d.RunAttempts()
+	if runAttempts >= policy.MaxAttempts {
+		d.Status = StatusDeadLetter
+		return
+	}
+	d.Status = StatusFailed
+	next := attempt.StartedAt.Add(policy.Backoff << uint(runAttempts-1))
+	d.NextAttemptAt = &next
+}
+
+// ScheduleRecovery makes a delivery not handled yet due after the backoff of policy, as
+// if its first attempt failed, for it to be retried should the process stop before its
+// handlers ran. The first attempt replaces the schedule.
+func (d *Delivery) ScheduleRecovery(policy RetryPolicy) {
+	next := d.ReceivedAt.Add(policy.Backoff)
+	d.NextAttemptAt = &next
+}
+
+// IsDue tells if the delivery failed, or was never handled, and its retry is due at now.
+func (d *Delivery) IsDue(now time.Time) bool {
+	if d.Status != StatusFailed && d.Status != StatusReceived {
+		return false
+	}
+	return d.NextAttemptAt != nil && !d.NextAttemptAt.After(now)
+}
+
+func (f DeliveryFilter) Matches(delivery *Delivery) bool {
+	return (f.Status == "" || f.Status == delivery.Status) && (f.Event == "" || f.Event == delivery.Event)
+}
+
+// Copy returns a delivery that shares nothing with d.
+func (d Delivery) Copy() *Delivery {
+	if d.Headers != nil {
+		headers := make(map[string]string, len(d.Headers))
+		for key, value := range d.Headers {
+			headers[key] = value
+		}
+		d.Headers = headers
+	}
+	d.Payload = append(json.RawMessage(nil), d.Payload...)
+	attempts := make([]Attempt, 0, len(d.Attempts))
+	for _, attempt := range d.Attempts {
+		attempt.Handlers = append([]HandlerOutcome(nil), attempt.Handlers...)
+		attempts = append(attempts, attempt)
+	}
+	d.Attempts = attempts
+	if d.NextAttemptAt != nil {
+		next := *d.NextAttemptAt
+		d.NextAttemptAt = &next
+	}
+	return &d
+}
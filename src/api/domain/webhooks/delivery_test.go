@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func failedAttempt(trigger string, startedAt time.Time) Attempt {
+	return Attempt{Trigger: trigger, StartedAt: startedAt, Handlers: []HandlerOutcome{
+		{Name: "ci", Succeeded: true},
+		{Name: "chat", Error: errors.New("chat is down").Error()},
+	}}
+}
+
+func TestDelivery_AddAttempt_BacksOffThenDeadLetters(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Minute}
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	delivery := &Delivery{Id: "72d3162e"}
+
+	delivery.AddAttempt(failedAttempt(TriggerDelivery, start), policy)
+	assert.EqualValues(t, StatusFailed, delivery.Status)
+	assert.EqualValues(t, start.Add(time.Minute), *delivery.NextAttemptAt)
+	assert.False(t, delivery.IsDue(start))
+	assert.True(t, delivery.IsDue(start.Add(time.Minute)))
+
+	delivery.AddAttempt(failedAttempt(TriggerRetry, start), policy)
+	assert.EqualValues(t, StatusFailed, delivery.Status)
+	assert.EqualValues(t, start.Add(2*time.Minute), *delivery.NextAttemptAt)
+
+	delivery.AddAttempt(failedAttempt(TriggerRetry, start), policy)
+	assert.EqualValues(t, StatusDeadLetter, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.EqualValues(t, 3, delivery.RunAttempts())
+
+	// a replay starts a new run
+	delivery.AddAttempt(failedAttempt(TriggerReplay, start), policy)
+	assert.EqualValues(t, StatusFailed, delivery.Status)
+	assert.EqualValues(t, 1, delivery.RunAttempts())
+
+	delivery.AddAttempt(Attempt{Trigger: TriggerRetry, StartedAt: start, Handlers: []HandlerOutcome{{Name: "chat", Succeeded: true}}}, policy)
+	assert.EqualValues(t, StatusSucceeded, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+}
+
+func TestDelivery_ScheduleRecovery(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	delivery := &Delivery{Id: "72d3162e", Status: StatusReceived, ReceivedAt: start}
+
+	delivery.ScheduleRecovery(RetryPolicy{MaxAttempts: 3, Backoff: time.Minute})
+
+	assert.False(t, delivery.IsDue(start))
+	assert.True(t, delivery.IsDue(start.Add(time.Minute)))
+
+	delivery.AddAttempt(Attempt{Trigger: TriggerDelivery, StartedAt: start}, RetryPolicy{MaxAttempts: 3})
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.False(t, delivery.IsDue(start.Add(time.Hour)))
+}
+
+func TestAttempt_Failed(t *testing.T) {
+	assert.EqualValues(t, []string{"chat"}, failedAttempt(TriggerDelivery, time.Now()).Failed())
+	assert.Nil(t, Attempt{}.Failed())
+}
+
+func TestDelivery_Copy(t *testing.T) {
+	next := time.Now()
+	delivery := Delivery{Headers: map[string]string{"a": "1"}, Payload: []byte(`{}`), Attempts: []Attempt{failedAttempt(TriggerDelivery, next)}, NextAttemptAt: &next}
+
+	copied := delivery.Copy()
+	copied.Headers["a"] = "2"
+	copied.Payload[0] = '['
+	copied.Attempts[0].Handlers[0].Name = "other"
+	*copied.NextAttemptAt = next.Add(time.Hour)
+
+	assert.EqualValues(t, "1", delivery.Headers["a"])
+	assert.EqualValues(t, `{}`, string(delivery.Payload))
+	assert.EqualValues(t, "ci", delivery.Attempts[0].Handlers[0].Name)
+	assert.EqualValues(t, next, *delivery.NextAttemptAt)
+}
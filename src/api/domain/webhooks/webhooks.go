@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Hub-Signature-256"
+	HeaderEvent     = "X-GitHub-Event"
+	HeaderDelivery  = "X-GitHub-Delivery"
+
+	signaturePrefix = "sha256="
+	contentTypeForm = "application/x-www-form-urlencoded"
+	formPayload     = "payload"
+)
+
+type DeliveryResult struct {
+	DeliveryId string `json:"delivery_id"`
+	Event      string `json:"event"`
+	Duplicate  bool   `json:"duplicate"`
+	Status     string `json:"status"`
+	Handled    int    `json:"handled"`
+}
+
+// envelope holds the fields every event payload shares.
+type envelope struct {
+	Action     string `json:"action"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Installation *github.InstallationRef `json:"installation"`
+}
+
+// VerifySignature tells if signature, the X-Hub-Signature-256 header, is the HMAC
+// SHA256 of body keyed with secret. It never matches an empty secret.
+func VerifySignature(secret string, signature string, body []byte) bool {
+	if secret == "" || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// Sign returns the X-Hub-Signature-256 header github sends for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DecodePayload returns the json payload of body, form encoded when the webhook was
+// registered with the form content type.
+func DecodePayload(contentType string, body []byte) ([]byte, errors.ApiError) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != contentTypeForm {
+		return body, nil
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil || values.Get(formPayload) == "" {
+		return nil, errors.NewBadRequestError("Invalid form payload")
+	}
+	return []byte(values.Get(formPayload)), nil
+}
+
+// NewDelivery describes the delivery of payload, which must be json.
+func NewDelivery(id string, event string, payload []byte) (*Delivery, errors.ApiError) {
+	var common envelope
+	if err := json.Unmarshal(payload, &common); err != nil {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid %s payload", event))
+	}
+
+	delivery := &Delivery{
+		Id:         id,
+		Event:      event,
+		Action:     common.Action,
+		Status:     StatusReceived,
+		ReceivedAt: time.Now().UTC(),
+		Payload:    json.RawMessage(payload),
+	}
+	if common.Repository != nil {
+		delivery.Repository = common.Repository.FullName
+	}
+	if common.Installation != nil {
+		delivery.InstallationId = common.Installation.Id
+	}
+	return delivery, nil
+}
+
+// ParseEvent decodes payload into the typed struct of event, e.g. *github.PushEvent.
+// Events without a struct are returned as json.RawMessage.
+func ParseEvent(event string, payload []byte) (interface{}, errors.ApiError) {
+	var target interface{}
+	switch event {
+	case github.EventPing:
+		target = &github.PingEvent{}
+	case github.EventRepository:
+		target = &github.RepositoryEvent{}
+	case github.EventPush:
+		target = &github.PushEvent{}
+	case github.EventPullRequest:
+		target = &github.PullRequestEvent{}
+	case github.EventInstallation:
+		target = &github.InstallationEvent{}
+	default:
+		return json.RawMessage(payload), nil
+	}
+
+	if err := json.Unmarshal(payload, target); err != nil {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid %s payload", event))
+	}
+	return target, nil
+}
+
+// Handler reacts to the events it was registered for, event being what ParseEvent
+// returned. An error makes the delivery fail.
+type Handler interface {
+	Handle(ctx context.Context, delivery Delivery, event interface{}) error
+}
+
+type HandlerFunc func(ctx context.Context, delivery Delivery, event interface{}) error
+
+func (f HandlerFunc) Handle(ctx context.Context, delivery Delivery, event interface{}) error {
+	return f(ctx, delivery, event)
+}
+
+// OnRepository, OnPush, OnPullRequest and OnInstallation adapt a typed function to a
+// Handler, to be registered for the matching event.
+func OnRepository(f func(ctx context.Context, delivery Delivery, event *github.RepositoryEvent) error) Handler {
+	return HandlerFunc(func(ctx context.Context, delivery Delivery, event interface{}) error {
+		typed, ok := event.(*github.RepositoryEvent)
+		if !ok {
+			return nil
+		}
+		return f(ctx, delivery, typed)
+	})
+}
+
+func OnPush(f func(ctx context.Context, delivery Delivery, event *github.PushEvent) error) Handler {
+	return HandlerFunc(func(ctx context.Context, delivery Delivery, event interface{}) error {
+		typed, ok := event.(*github.PushEvent)
+		if !ok {
+			return nil
+		}
+		return f(ctx, delivery, typed)
+	})
+}
+
+func OnPullRequest(f func(ctx context.Context, delivery Delivery, event *github.PullRequestEvent) error) Handler {
+	return HandlerFunc(func(ctx context.Context, delivery Delivery, event interface{}) error {
+		typed, ok := event.(*github.PullRequestEvent)
+		if !ok {
+			return nil
+		}
+		return f(ctx, delivery, typed)
+	})
+}
+
+func OnInstallation(f func(ctx context.Context, delivery Delivery, event *github.InstallationEvent) error) Handler {
+	return HandlerFunc(func(ctx context.Context, delivery Delivery, event interface{}) error {
+		typed, ok := event.(*github.InstallationEvent)
+		if !ok {
+			return nil
+		}
+		return f(ctx, delivery, typed)
+	})
+}
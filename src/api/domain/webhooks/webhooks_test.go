@@ -0,0 +1,95 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"zen": "Keep it logically awesome."}`)
+	signature := Sign("s3cr3t", body)
+
+	assert.True(t, VerifySignature("s3cr3t", signature, body))
+	assert.False(t, VerifySignature("other", signature, body))
+	assert.False(t, VerifySignature("s3cr3t", signature, []byte(`{}`)))
+	assert.False(t, VerifySignature("s3cr3t", "sha1=abc", body))
+	assert.False(t, VerifySignature("s3cr3t", "sha256=not-hex", body))
+	assert.False(t, VerifySignature("", Sign("", body), body))
+}
+
+func TestVerifySignature_KnownValue(t *testing.T) {
+	// the example of the github documentation
+	assert.True(t, VerifySignature("It's a Secret to Everybody", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", []byte("Hello, World!")))
+}
+
+func TestDecodePayload(t *testing.T) {
+	payload, err := DecodePayload("application/json", []byte(`{"action": "opened"}`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"action": "opened"}`, string(payload))
+
+	form := url.Values{"payload": {`{"action": "opened"}`}}.Encode()
+	payload, err = DecodePayload("application/x-www-form-urlencoded", []byte(form))
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"action": "opened"}`, string(payload))
+
+	payload, err = DecodePayload("application/x-www-form-urlencoded", []byte("other=1"))
+	assert.Nil(t, payload)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestNewDelivery(t *testing.T) {
+	delivery, err := NewDelivery("72d3162e", github.EventPullRequest, []byte(`{"action": "opened", "repository": {"full_name": "my-org/my-repo"}, "installation": {"id": 42}}`))
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "72d3162e", delivery.Id)
+	assert.EqualValues(t, "opened", delivery.Action)
+	assert.EqualValues(t, "my-org/my-repo", delivery.Repository)
+	assert.EqualValues(t, 42, delivery.InstallationId)
+	assert.False(t, delivery.ReceivedAt.IsZero())
+}
+
+func TestParseEvent(t *testing.T) {
+	event, err := ParseEvent(github.EventPush, []byte(`{"ref": "refs/heads/main", "after": "abc", "repository": {"name": "my-repo", "owner": {"login": "my-org"}}, "head_commit": {"id": "abc"}}`))
+
+	assert.Nil(t, err)
+	push, ok := event.(*github.PushEvent)
+	assert.True(t, ok)
+	assert.EqualValues(t, "refs/heads/main", push.Ref)
+	assert.EqualValues(t, "my-org", push.Repository.Owner.Login)
+	assert.EqualValues(t, "abc", push.HeadCommit.Id)
+}
+
+func TestParseEvent_Unknown(t *testing.T) {
+	event, err := ParseEvent("star", []byte(`{"action": "created"}`))
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, json.RawMessage(`{"action": "created"}`), event)
+}
+
+func TestParseEvent_Invalid(t *testing.T) {
+	event, err := ParseEvent(github.EventInstallation, []byte(`{"installation": []}`))
+
+	assert.Nil(t, event)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid installation payload", err.Message())
+}
+
+func TestOnPullRequest_IgnoresOtherEvents(t *testing.T) {
+	var received *github.PullRequestEvent
+	handler := OnPullRequest(func(ctx context.Context, delivery Delivery, event *github.PullRequestEvent) error {
+		received = event
+		return nil
+	})
+
+	assert.Nil(t, handler.Handle(context.Background(), Delivery{}, &github.PushEvent{}))
+	assert.Nil(t, received)
+
+	assert.Nil(t, handler.Handle(context.Background(), Delivery{}, &github.PullRequestEvent{Number: 7}))
+	assert.EqualValues(t, 7, received.Number)
+}
@@ -0,0 +1,336 @@
+package github_provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerBearerFormat   = "Bearer %s"
+	urlOrgInstallation   = "https://api.github.com/orgs/%s/installation"
+	urlUserInstallation  = "https://api.github.com/users/%s/installation"
+	urlInstallationToken = "https://api.github.com/app/installations/%d/access_tokens"
+
+	// github refuses app tokens living longer than 10 minutes, and issued in the future
+	// according to its own clock
+	appJwtLifetime   = 9 * time.Minute
+	appJwtClockDrift = time.Minute
+	// installation tokens are renewed this long before github expires them
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+var (
+	appMutex sync.RWMutex
+	app      *appAuth
+)
+
+type ownerKey struct{}
+
+type userKey struct{}
+
+// appClaims are the claims github reads out of the json web token of an app.
+type appClaims struct {
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	Issuer    string `json:"iss"`
+}
+
+// appAuth authenticates the calls as the installations of a github app, remembering
+// which installation serves each owner and the tokens until shortly before they expire.
+type appAuth struct {
+	appId               int64
+	key                 *rsa.PrivateKey
+	defaultInstallation int64
+	now                 func() time.Time
+
+	mutex         sync.Mutex
+	installations map[string]int64
+	tokens        map[int64]github.InstallationToken
+}
+
+// ConfigureApp makes every call authenticate as the installation of the github app
+// appId on the owner it targets, instead of with the access token it is given. keyPem
+// is the private key generated for the app, defaultInstallation (when not zero) serves
+// the calls targeting no owner, the others keep their access token. An installation
+// cannot act as a user, the calls on the authenticated user, such as creating a user
+// repository, always keep their access token.
+func ConfigureApp(appId int64, keyPem []byte, defaultInstallation int64) error {
+	key, err := parsePrivateKey(keyPem)
+	if err != nil {
+		return err
+	}
+
+	appMutex.Lock()
+	app = newAppAuth(appId, key, defaultInstallation)
+	appMutex.Unlock()
+	return nil
+}
+
+// DisableApp goes back to authenticating every call with its access token.
+func DisableApp() {
+	appMutex.Lock()
+	app = nil
+	appMutex.Unlock()
+}
+
+// WithOwner tells the calls made with ctx which owner they act on, for the urls naming
+// another owner or none, such as the next pages github links to.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// withTarget tells the calls made with ctx that they create a repository for owner, the
+// authenticated user when empty.
+func withTarget(ctx context.Context, owner string) context.Context {
+	if owner != "" {
+		return WithOwner(ctx, owner)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, userKey{}, true)
+}
+
+func newAppAuth(appId int64, key *rsa.PrivateKey, defaultInstallation int64) *appAuth {
+	return &appAuth{
+		appId:               appId,
+		key:                 key,
+		defaultInstallation: defaultInstallation,
+		now:                 time.Now,
+		installations:       make(map[string]int64),
+		tokens:              make(map[int64]github.InstallationToken),
+	}
+}
+
+func getApp() *appAuth {
+	appMutex.RLock()
+	defer appMutex.RUnlock()
+	return app
+}
+
+// parsePrivateKey accepts the PKCS#1 pem github generates as well as PKCS#8.
+func parsePrivateKey(keyPem []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, errors.New("invalid github app private key: no pem block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid github app private key: %s", err.Error())
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid github app private key: not an rsa key")
+	}
+	return key, nil
+}
+
+// resolveToken returns the token a call to callUrl is sent with: the installation token
+// of the owner it targets when a github app is configured, accessToken otherwise.
+func resolveToken(ctx context.Context, callUrl string, accessToken string) (string, *github.GithubErrorResponse) {
+	a := getApp()
+	if a == nil {
+		return accessToken, nil
+	}
+
+	owner, user := targetOf(ctx, callUrl)
+	if user {
+		if accessToken == "" {
+			return "", &github.GithubErrorResponse{
+				StatusCode: http.StatusInternalServerError,
+				Message:    "calls on the authenticated user need an access token, a github app installation cannot make them",
+			}
+		}
+		return accessToken, nil
+	}
+	return a.token(ctx, owner, accessToken)
+}
+
+// targetOf tells which owner a call acts on, the one set on ctx winning over the one
+// in callUrl, or if it acts on the authenticated user.
+func targetOf(ctx context.Context, callUrl string) (string, bool) {
+	if ctx != nil {
+		if user, _ := ctx.Value(userKey{}).(bool); user {
+			return "", true
+		}
+		if owner, _ := ctx.Value(ownerKey{}).(string); owner != "" {
+			return owner, false
+		}
+	}
+	if owner := ownerFromUrl(callUrl); owner != "" {
+		return owner, false
+	}
+
+	path := strings.TrimPrefix(callUrl, urlApiBase)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return "", path == "user" || strings.HasPrefix(path, "user/")
+}
+
+// ownerFromUrl extracts the owner out of the repos/, orgs/ and users/ urls.
+func ownerFromUrl(callUrl string) string {
+	if !strings.HasPrefix(callUrl, urlApiBase) {
+		return ""
+	}
+	path := strings.TrimPrefix(callUrl, urlApiBase)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	switch segments[0] {
+	case "repos", "orgs", "users":
+		owner, err := url.PathUnescape(segments[1])
+		if err != nil {
+			return ""
+		}
+		return owner
+	}
+	return ""
+}
+
+func (a *appAuth) token(ctx context.Context, owner string, accessToken string) (string, *github.GithubErrorResponse) {
+	installation := a.defaultInstallation
+	if owner != "" {
+		var err *github.GithubErrorResponse
+		if installation, err = a.installation(ctx, owner); err != nil {
+			return "", err
+		}
+	}
+	if installation == 0 {
+		if accessToken != "" {
+			return accessToken, nil
+		}
+		return "", &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "no github app installation configured for calls without an owner",
+		}
+	}
+
+	token, err := a.installationToken(ctx, installation)
+	if err != nil && owner != "" && err.StatusCode == http.StatusNotFound {
+		// the app got uninstalled since, look the owner up again next time
+		a.mutex.Lock()
+		delete(a.installations, strings.ToLower(owner))
+		a.mutex.Unlock()
+	}
+	return token, err
+}
+
+// installation finds the installation of the app on owner, an organization or a user.
+func (a *appAuth) installation(ctx context.Context, owner string) (int64, *github.GithubErrorResponse) {
+	key := strings.ToLower(owner)
+	a.mutex.Lock()
+	id, ok := a.installations[key]
+	a.mutex.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var result github.Installation
+	err := a.call(ctx, http.MethodGet, fmt.Sprintf(urlOrgInstallation, url.PathEscape(owner)), &result)
+	if err != nil && err.StatusCode == http.StatusNotFound {
+		err = a.call(ctx, http.MethodGet, fmt.Sprintf(urlUserInstallation, url.PathEscape(owner)), &result)
+	}
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			err.Message = fmt.Sprintf("github app is not installed on %s", owner)
+		}
+		return 0, err
+	}
+
+	a.mutex.Lock()
+	a.installations[key] = result.Id
+	a.mutex.Unlock()
+	return result.Id, nil
+}
+
+// installationToken returns the cached token of installation, asking github for a new
+// one once it is about to expire. Concurrent renewals may both reach github, each
+// token they get being valid.
+func (a *appAuth) installationToken(ctx context.Context, installation int64) (string, *github.GithubErrorResponse) {
+	a.mutex.Lock()
+	cached, ok := a.tokens[installation]
+	a.mutex.Unlock()
+	if ok && a.now().Add(tokenRefreshMargin).Before(cached.ExpiresAt) {
+		return cached.Token, nil
+	}
+
+	var result github.InstallationToken
+	if err := a.call(ctx, http.MethodPost, fmt.Sprintf(urlInstallationToken, installation), &result); err != nil {
+		return "", err
+	}
+
+	a.mutex.Lock()
+	a.tokens[installation] = result
+	a.mutex.Unlock()
+	return result.Token, nil
+}
+
+// call sends a call authenticated as the app itself, which github only accepts for
+// the app endpoints. Its budget is tracked apart from the installation ones.
+func (a *appAuth) call(ctx context.Context, httpMethod string, callUrl string, result interface{}) *github.GithubErrorResponse {
+	jwt, err := a.signJwt(a.now())
+	if err != nil {
+		return &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    fmt.Sprintf("error when trying to sign github app token: %s", err.Error()),
+		}
+	}
+
+	rateKey := fmt.Sprintf("app:%d", a.appId)
+	if err := rateLimits.wait(ctx, rateKey); err != nil {
+		return err
+	}
+	_, apiErr := callWithAuthorization(ctx, httpMethod, callUrl, fmt.Sprintf(headerBearerFormat, jwt), rateKey, nil, result)
+	return apiErr
+}
+
+// signJwt builds the RS256 json web token the app authenticates with.
+func (a *appAuth) signJwt(now time.Time) (string, error) {
+	claims, err := json.Marshal(appClaims{
+		IssuedAt:  now.Add(-appJwtClockDrift).Unix(),
+		ExpiresAt: now.Add(appJwtLifetime).Unix(),
+		Issuer:    strconv.FormatInt(a.appId, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,252 @@
+package github_provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var appTestKey *rsa.PrivateKey
+
+func getAppTestKey(t *testing.T) *rsa.PrivateKey {
+	if appTestKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		appTestKey = key
+	}
+	return appTestKey
+}
+
+func configureTestApp(t *testing.T, defaultInstallation int64) *appAuth {
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(getAppTestKey(t))})
+	if err := ConfigureApp(1234, keyPem, defaultInstallation); err != nil {
+		t.Fatal(err)
+	}
+	return getApp()
+}
+
+func addInstallationMock(url string, status int, body string) {
+	restclient.AddMockUp(restclient.Mock{
+		Url:        url,
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		},
+	})
+}
+
+func addInstallationTokenMock(installation int64, token string, expiresAt time.Time) {
+	restclient.AddMockUp(restclient.Mock{
+		Url:        fmt.Sprintf("https://api.github.com/app/installations/%d/access_tokens", installation),
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(fmt.Sprintf(`{"token": "%s", "expires_at": "%s"}`, token, expiresAt.UTC().Format(time.RFC3339)))),
+		},
+	})
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := getAppTestKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+
+	parsed, err := parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	assert.Nil(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	parsed, err = parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	assert.Nil(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = parsePrivateKey([]byte("not a key"))
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid github app private key: no pem block found", err.Error())
+}
+
+func TestSignJwt(t *testing.T) {
+	a := newAppAuth(1234, getAppTestKey(t), 0)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	jwt, err := a.signJwt(now)
+	assert.Nil(t, err)
+
+	parts := strings.Split(jwt, ".")
+	assert.EqualValues(t, 3, len(parts))
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"alg": "RS256", "typ": "JWT"}`, string(header))
+
+	var claims appClaims
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(payload, &claims))
+	assert.EqualValues(t, "1234", claims.Issuer)
+	assert.EqualValues(t, now.Add(-time.Minute).Unix(), claims.IssuedAt)
+	assert.EqualValues(t, now.Add(9*time.Minute).Unix(), claims.ExpiresAt)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.Nil(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.Nil(t, rsa.VerifyPKCS1v15(&getAppTestKey(t).PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestOwnerFromUrl(t *testing.T) {
+	assert.EqualValues(t, "dmolina79", ownerFromUrl("https://api.github.com/repos/dmolina79/my-github-repo/hooks?per_page=100"))
+	assert.EqualValues(t, "acme", ownerFromUrl("https://api.github.com/orgs/acme/repos"))
+	assert.EqualValues(t, "acme", ownerFromUrl("https://api.github.com/orgs/acme/teams/core/repos/acme/api"))
+	assert.EqualValues(t, "dmolina79", ownerFromUrl("https://api.github.com/users/dmolina79"))
+	assert.EqualValues(t, "", ownerFromUrl("https://api.github.com/user/repos"))
+	assert.EqualValues(t, "", ownerFromUrl("https://api.github.com/repositories/1/hooks?page=2"))
+	assert.EqualValues(t, "", ownerFromUrl("https://api.github.com/rate_limit"))
+	assert.EqualValues(t, "", ownerFromUrl("https://example.com/repos/acme/api"))
+}
+
+func TestResolveTokenWithoutApp(t *testing.T) {
+	DisableApp()
+
+	token, err := resolveToken(context.Background(), "https://api.github.com/repos/acme/api", "pat")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "pat", token)
+}
+
+func TestResolveTokenCachesInstallationToken(t *testing.T) {
+	restclient.FlushMockups()
+	a := configureTestApp(t, 0)
+	defer DisableApp()
+	now := time.Now()
+	a.now = func() time.Time { return now }
+	addInstallationMock("https://api.github.com/orgs/acme/installation", http.StatusOK, `{"id": 42, "app_id": 1234, "account": {"login": "acme"}}`)
+	addInstallationTokenMock(42, "ghs_first", now.Add(time.Hour))
+
+	token, err := resolveToken(context.Background(), "https://api.github.com/repos/acme/api", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_first", token)
+
+	// the mocked bodies are consumed, the owner and the token are served from the cache
+	token, err = resolveToken(context.Background(), "https://api.github.com/orgs/ACME/repos", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_first", token)
+
+	now = now.Add(56 * time.Minute)
+	addInstallationTokenMock(42, "ghs_second", now.Add(time.Hour))
+
+	token, err = resolveToken(context.Background(), "https://api.github.com/repos/acme/api", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_second", token)
+}
+
+func TestResolveTokenUserInstallation(t *testing.T) {
+	restclient.FlushMockups()
+	configureTestApp(t, 0)
+	defer DisableApp()
+	addInstallationMock("https://api.github.com/orgs/dmolina79/installation", http.StatusNotFound, `{"message": "Not Found"}`)
+	addInstallationMock("https://api.github.com/users/dmolina79/installation", http.StatusOK, `{"id": 7}`)
+	addInstallationTokenMock(7, "ghs_user", time.Now().Add(time.Hour))
+
+	token, err := resolveToken(context.Background(), "https://api.github.com/repos/dmolina79/my-github-repo", "")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_user", token)
+}
+
+func TestResolveTokenNotInstalled(t *testing.T) {
+	restclient.FlushMockups()
+	configureTestApp(t, 0)
+	defer DisableApp()
+	addInstallationMock("https://api.github.com/orgs/acme/installation", http.StatusNotFound, `{"message": "Not Found"}`)
+	addInstallationMock("https://api.github.com/users/acme/installation", http.StatusNotFound, `{"message": "Not Found"}`)
+
+	token, err := resolveToken(context.Background(), "https://api.github.com/repos/acme/api", "pat")
+
+	assert.EqualValues(t, "", token)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+	assert.EqualValues(t, "github app is not installed on acme", err.Message)
+}
+
+func TestResolveTokenOwnerFromContext(t *testing.T) {
+	restclient.FlushMockups()
+	configureTestApp(t, 0)
+	defer DisableApp()
+	addInstallationMock("https://api.github.com/orgs/acme/installation", http.StatusOK, `{"id": 42}`)
+	addInstallationTokenMock(42, "ghs_acme", time.Now().Add(time.Hour))
+
+	token, err := resolveToken(WithOwner(context.Background(), "acme"), "https://api.github.com/repositories/1/hooks?page=2", "pat")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_acme", token)
+}
+
+func TestResolveTokenTargetWinsOverUrl(t *testing.T) {
+	restclient.FlushMockups()
+	configureTestApp(t, 0)
+	defer DisableApp()
+	addInstallationMock("https://api.github.com/orgs/my-org/installation", http.StatusOK, `{"id": 42}`)
+	addInstallationTokenMock(42, "ghs_my_org", time.Now().Add(time.Hour))
+
+	token, err := resolveToken(withTarget(context.Background(), "my-org"), "https://api.github.com/repos/octocat/hello-world/forks", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_my_org", token)
+
+	// forked into the authenticated user, which no installation can act as
+	token, err = resolveToken(withTarget(context.Background(), ""), "https://api.github.com/repos/octocat/hello-world/forks", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "pat", token)
+}
+
+func TestResolveTokenAuthenticatedUser(t *testing.T) {
+	restclient.FlushMockups()
+	configureTestApp(t, 99)
+	defer DisableApp()
+
+	token, err := resolveToken(context.Background(), "https://api.github.com/user/repos?per_page=100", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "pat", token)
+
+	token, err = resolveToken(context.Background(), "https://api.github.com/user/repos", "")
+	assert.EqualValues(t, "", token)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+}
+
+func TestResolveTokenWithoutOwner(t *testing.T) {
+	restclient.FlushMockups()
+	configureTestApp(t, 0)
+	defer DisableApp()
+
+	token, err := resolveToken(context.Background(), "https://api.github.com/rate_limit", "pat")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "pat", token)
+
+	token, err = resolveToken(context.Background(), "https://api.github.com/rate_limit", "")
+	assert.EqualValues(t, "", token)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+
+	configureTestApp(t, 99)
+	addInstallationTokenMock(99, "ghs_default", time.Now().Add(time.Hour))
+
+	token, err = resolveToken(context.Background(), "https://api.github.com/rate_limit", "")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_default", token)
+}
@@ -0,0 +1,43 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+	"net/url"
+)
+
+const (
+	urlBranchProtection = "https://api.github.com/repos/%s/%s/branches/%s/protection"
+
+	// messageBranchNotProtected tells an unprotected branch apart from a missing one,
+	// github answers 404 to both.
+	messageBranchNotProtected = "Branch not protected"
+)
+
+// ProtectBranch replaces every protection rule of branch with the ones in request.
+func ProtectBranch(ctx context.Context, accessToken string, owner string, name string, branch string, request github.BranchProtectionRequest) (*github.BranchProtection, *github.GithubErrorResponse) {
+	var result github.BranchProtection
+	protectionUrl := fmt.Sprintf(urlBranchProtection, owner, name, url.PathEscape(branch))
+	if err := execute(ctx, http.MethodPut, protectionUrl, accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBranchProtection returns the protection rules of branch, or nil when github
+// reports the branch as not protected.
+func GetBranchProtection(ctx context.Context, accessToken string, owner string, name string, branch string) (*github.BranchProtection, *github.GithubErrorResponse) {
+	var result github.BranchProtection
+	protectionUrl := fmt.Sprintf(urlBranchProtection, owner, name, url.PathEscape(branch))
+	if err := execute(ctx, http.MethodGet, protectionUrl, accessToken, nil, &result); err != nil {
+		if err.StatusCode == http.StatusNotFound && err.Message == messageBranchNotProtected {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,87 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProtectBranchSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/branches/release%2F1.0/protection",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"url": "https://api.github.com/repos/dmolina79/my-github-repo/branches/release/1.0/protection",
+				"required_pull_request_reviews": {"required_approving_review_count": 2},
+				"enforce_admins": {"enabled": true}, "required_linear_history": {"enabled": true}}`)),
+		},
+	})
+
+	protection, err := ProtectBranch(context.Background(), "", "dmolina79", "my-github-repo", "release/1.0", github.BranchProtectionRequest{EnforceAdmins: true})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, protection)
+	assert.EqualValues(t, 2, protection.RequiredPullRequestReviews.RequiredApprovingReviewCount)
+	assert.True(t, protection.EnforceAdmins.Enabled)
+	assert.True(t, protection.RequiredLinearHistory.Enabled)
+}
+
+func TestProtectBranchNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/branches/main/protection",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Branch not found"}`)),
+		},
+	})
+
+	protection, err := ProtectBranch(context.Background(), "", "dmolina79", "my-github-repo", "main", github.BranchProtectionRequest{})
+
+	assert.Nil(t, protection)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
+
+func TestGetBranchProtectionNotProtected(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/branches/main/protection",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Branch not protected"}`)),
+		},
+	})
+
+	protection, err := GetBranchProtection(context.Background(), "", "dmolina79", "my-github-repo", "main")
+
+	assert.Nil(t, protection)
+	assert.Nil(t, err)
+}
+
+func TestGetBranchProtectionBranchNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/branches/main/protection",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Branch not found"}`)),
+		},
+	})
+
+	protection, err := GetBranchProtection(context.Background(), "", "dmolina79", "my-github-repo", "main")
+
+	assert.Nil(t, protection)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Branch not found", err.Message)
+}
@@ -0,0 +1,61 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+	"net/url"
+)
+
+const (
+	urlCollaborator    = "https://api.github.com/repos/%s/%s/collaborators/%s"
+	urlRepoInvitations = "https://api.github.com/repos/%s/%s/invitations?per_page=100"
+	urlRepoInvitation  = "https://api.github.com/repos/%s/%s/invitations/%d"
+)
+
+// AddCollaborator grants user access to owner/name. Github answers with the invitation
+// sent to the user, or with nothing when the user got access right away, in which case
+// the returned invitation is nil.
+func AddCollaborator(ctx context.Context, accessToken string, owner string, name string, user string, request github.CollaboratorRequest) (*github.Invitation, *github.GithubErrorResponse) {
+	var result github.Invitation
+	collaboratorUrl := fmt.Sprintf(urlCollaborator, owner, name, url.PathEscape(user))
+	if err := execute(ctx, http.MethodPut, collaboratorUrl, accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Id == 0 {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// RemoveCollaborator revokes the access of user, leaving its pending invitations as they are.
+func RemoveCollaborator(ctx context.Context, accessToken string, owner string, name string, user string) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodDelete, fmt.Sprintf(urlCollaborator, owner, name, url.PathEscape(user)), accessToken, nil, nil)
+}
+
+// ListInvitations returns every invitation of owner/name still waiting to be accepted,
+// following the pages github splits them into.
+func ListInvitations(ctx context.Context, accessToken string, owner string, name string) ([]github.Invitation, *github.GithubErrorResponse) {
+	var invitations []github.Invitation
+	for pageUrl := fmt.Sprintf(urlRepoInvitations, owner, name); pageUrl != ""; {
+		var page []github.Invitation
+		var err *github.GithubErrorResponse
+		if pageUrl, err = fetchPage(ctx, accessToken, owner, pageUrl, &page); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, page...)
+	}
+
+	return invitations, nil
+}
+
+func DeleteInvitation(ctx context.Context, accessToken string, owner string, name string, id int64) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodDelete, fmt.Sprintf(urlRepoInvitation, owner, name, id), accessToken, nil, nil)
+}
+
+// RemoveTeamRepo revokes the access the team teamSlug of org has on owner/name.
+func RemoveTeamRepo(ctx context.Context, accessToken string, org string, teamSlug string, owner string, name string) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodDelete, fmt.Sprintf(urlTeamRepo, org, url.PathEscape(teamSlug), owner, name), accessToken, nil, nil)
+}
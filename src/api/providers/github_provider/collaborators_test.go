@@ -0,0 +1,93 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAddCollaboratorInvited(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/collaborators/octocat",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 7, "invitee": {"login": "octocat"}, "permissions": "write"}`)),
+		},
+	})
+
+	invitation, err := AddCollaborator(context.Background(), "", "dmolina79", "my-github-repo", "octocat", github.CollaboratorRequest{Permission: "push"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, invitation)
+	assert.EqualValues(t, 7, invitation.Id)
+	assert.EqualValues(t, "octocat", invitation.Invitee.Login)
+}
+
+func TestAddCollaboratorAlreadyCollaborator(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/collaborators/octocat",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+			Body:       ioutil.NopCloser(strings.NewReader(``)),
+		},
+	})
+
+	invitation, err := AddCollaborator(context.Background(), "", "dmolina79", "my-github-repo", "octocat", github.CollaboratorRequest{})
+
+	assert.Nil(t, err)
+	assert.Nil(t, invitation)
+}
+
+func TestListInvitationsSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/invitations?per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/repositories/1/invitations?per_page=100&page=2>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 7, "invitee": {"login": "octocat"}, "permissions": "write"}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repositories/1/invitations?per_page=100&page=2",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 8, "invitee": {"login": "hubot"}, "permissions": "read"}]`)),
+		},
+	})
+
+	invitations, err := ListInvitations(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(invitations))
+	assert.EqualValues(t, "write", invitations[0].Permissions)
+	assert.EqualValues(t, "hubot", invitations[1].Invitee.Login)
+}
+
+func TestRemoveTeamRepoNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/teams/platform/repos/my-org/my-github-repo",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	err := RemoveTeamRepo(context.Background(), "", "my-org", "platform", "my-org", "my-github-repo")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
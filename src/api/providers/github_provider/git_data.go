@@ -0,0 +1,77 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+)
+
+const (
+	urlGitBlobs   = "https://api.github.com/repos/%s/%s/git/blobs"
+	urlGitTrees   = "https://api.github.com/repos/%s/%s/git/trees"
+	urlGitCommits = "https://api.github.com/repos/%s/%s/git/commits"
+	urlGitCommit  = "https://api.github.com/repos/%s/%s/git/commits/%s"
+	urlGitBranch  = "https://api.github.com/repos/%s/%s/git/ref/heads/%s"
+	urlGitHead    = "https://api.github.com/repos/%s/%s/git/refs/heads/%s"
+)
+
+// The git data calls answer 409 while the repository has no commit at all.
+
+// CreateBlob stores content, base64 encoded, in the object database of owner/name.
+func CreateBlob(ctx context.Context, accessToken string, owner string, name string, content string) (*github.GitObject, *github.GithubErrorResponse) {
+	var result github.GitObject
+	request := github.CreateBlobRequest{Content: content, Encoding: github.BlobEncodingBase64}
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlGitBlobs, owner, name), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func CreateTree(ctx context.Context, accessToken string, owner string, name string, request github.CreateTreeRequest) (*github.GitObject, *github.GithubErrorResponse) {
+	var result github.GitObject
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlGitTrees, owner, name), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func CreateCommit(ctx context.Context, accessToken string, owner string, name string, request github.CreateCommitRequest) (*github.Commit, *github.GithubErrorResponse) {
+	var result github.Commit
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlGitCommits, owner, name), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetCommit(ctx context.Context, accessToken string, owner string, name string, sha string) (*github.Commit, *github.GithubErrorResponse) {
+	var result github.Commit
+	if err := execute(ctx, http.MethodGet, fmt.Sprintf(urlGitCommit, owner, name, sha), accessToken, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetBranchRef returns the ref of branch, pointing to its last commit.
+func GetBranchRef(ctx context.Context, accessToken string, owner string, name string, branch string) (*github.Ref, *github.GithubErrorResponse) {
+	var result github.Ref
+	if err := execute(ctx, http.MethodGet, fmt.Sprintf(urlGitBranch, owner, name, escapePath(branch)), accessToken, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateBranchRef moves branch to the commit in request.
+func UpdateBranchRef(ctx context.Context, accessToken string, owner string, name string, branch string, request github.UpdateRefRequest) (*github.Ref, *github.GithubErrorResponse) {
+	var result github.Ref
+	if err := execute(ctx, http.MethodPatch, fmt.Sprintf(urlGitHead, owner, name, escapePath(branch)), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
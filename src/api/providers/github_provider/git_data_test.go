@@ -0,0 +1,68 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateBlobSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/git/blobs",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"sha": "3a0f86fb8db8eea7ccbb9a95f325ddbedfb25e15", "url": "https://api.github.com/repos/dmolina79/my-github-repo/git/blobs/3a0f86fb8db8eea7ccbb9a95f325ddbedfb25e15"}`)),
+		},
+	})
+
+	blob, err := CreateBlob(context.Background(), "", "dmolina79", "my-github-repo", "SGVsbG8=")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, blob)
+	assert.EqualValues(t, "3a0f86fb8db8eea7ccbb9a95f325ddbedfb25e15", blob.Sha)
+}
+
+func TestGetBranchRefEmptyRepository(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/git/ref/heads/main",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusConflict,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Git Repository is empty."}`)),
+		},
+	})
+
+	ref, err := GetBranchRef(context.Background(), "", "dmolina79", "my-github-repo", "main")
+
+	assert.Nil(t, ref)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.StatusCode)
+	assert.EqualValues(t, "Git Repository is empty.", err.Message)
+}
+
+func TestUpdateBranchRefSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/git/refs/heads/release/v1",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"ref": "refs/heads/release/v1", "object": {"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd", "type": "commit"}}`)),
+		},
+	})
+
+	ref, err := UpdateBranchRef(context.Background(), "", "dmolina79", "my-github-repo", "release/v1", github.UpdateRefRequest{Sha: "aa218f56b14c9653891f9e74264a383fa43fefbd", Force: true})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, ref)
+	assert.EqualValues(t, "refs/heads/release/v1", ref.Ref)
+	assert.EqualValues(t, "aa218f56b14c9653891f9e74264a383fa43fefbd", ref.Object.Sha)
+}
@@ -1,9 +1,11 @@
 package github_provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/config"
 	"github.com/dmolina79/golang-github-api/src/api/domain/github"
 	"io/ioutil"
 	"log"
@@ -14,26 +16,180 @@ const (
 	headerAuthorization       = "Authorization"
 	headerAuthorizationFormat = "token %s"
 	urlCreateRepo             = "https://api.github.com/user/repos"
+	urlCreateOrgRepo          = "https://api.github.com/orgs/%s/repos"
+	urlRepo                   = "https://api.github.com/repos/%s/%s"
+	urlGenerateRepo           = "https://api.github.com/repos/%s/%s/generate"
+	urlForkRepo               = "https://api.github.com/repos/%s/%s/forks"
+	urlRepoCommits            = "https://api.github.com/repos/%s/%s/commits?per_page=1"
 )
 
 func getAuthorizationHeader(accessToken string) string {
 	return fmt.Sprintf(headerAuthorizationFormat, accessToken)
 }
 
-func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.CreateRepoResponse, *github.GithubErrorResponse) {
+func getRequestContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout := config.GetGithubRequestTimeout(); timeout > 0 {
+		ctx = restclient.WithTimeout(ctx, timeout)
+	}
+	return ctx
+}
+
+func CreateRepo(ctx context.Context, accessToken string, request github.CreateRepoRequest) (*github.CreateRepoResponse, *github.GithubErrorResponse) {
+	return createRepo(ctx, urlCreateRepo, accessToken, request)
+}
+
+func CreateOrgRepo(ctx context.Context, accessToken string, org string, request github.CreateRepoRequest) (*github.CreateRepoResponse, *github.GithubErrorResponse) {
+	res, err := createRepo(ctx, fmt.Sprintf(urlCreateOrgRepo, org), accessToken, request)
+	if err != nil {
+		return nil, translateOrgError(org, err)
+	}
+
+	return res, nil
+}
+
+// translateOrgError makes membership problems distinguishable from other failures:
+// GitHub answers 404 when the token cannot see the org and 403 when it cannot create in it.
+func translateOrgError(org string, err *github.GithubErrorResponse) *github.GithubErrorResponse {
+	switch err.StatusCode {
+	case http.StatusNotFound:
+		err.Message = fmt.Sprintf("organization %s not found or access token is not a member", org)
+	case http.StatusForbidden:
+		err.Message = fmt.Sprintf("access token is not allowed to create repositories in organization %s: %s", org, err.Message)
+	}
+
+	return err
+}
+
+func createRepo(ctx context.Context, url string, accessToken string, request github.CreateRepoRequest) (*github.CreateRepoResponse, *github.GithubErrorResponse) {
+	var result github.CreateRepoResponse
+	if err := execute(ctx, http.MethodPost, url, accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GenerateRepo creates a repository out of the template repository templateOwner/templateName.
+func GenerateRepo(ctx context.Context, accessToken string, templateOwner string, templateName string, request github.GenerateRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	// the new repository belongs to request.Owner, not to the owner of the template
+	ctx = withTarget(ctx, request.Owner)
+	var result github.Repository
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlGenerateRepo, templateOwner, templateName), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ForkRepo asks github to fork owner/name. Github answers right away and copies the
+// content in the background, see RepoHasCommits.
+func ForkRepo(ctx context.Context, accessToken string, owner string, name string, request github.ForkRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	ctx = withTarget(ctx, request.Organization)
+	var result github.Repository
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlForkRepo, owner, name), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// RepoHasCommits reports if owner/name already holds git content. Github answers 409
+// for an empty repository and 404 while a fork is still being created.
+func RepoHasCommits(ctx context.Context, accessToken string, owner string, name string) (bool, *github.GithubErrorResponse) {
+	err := execute(ctx, http.MethodGet, fmt.Sprintf(urlRepoCommits, owner, name), accessToken, nil, nil)
+	if err == nil {
+		return true, nil
+	}
+	if err.StatusCode == http.StatusConflict || err.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func GetRepo(ctx context.Context, accessToken string, owner string, name string) (*github.Repository, *github.GithubErrorResponse) {
+	var result github.Repository
+	if err := execute(ctx, http.MethodGet, fmt.Sprintf(urlRepo, owner, name), accessToken, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func UpdateRepo(ctx context.Context, accessToken string, owner string, name string, request github.UpdateRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	var result github.Repository
+	if err := execute(ctx, http.MethodPatch, fmt.Sprintf(urlRepo, owner, name), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ArchiveRepo makes the repository read only, github offers no way back through the api.
+func ArchiveRepo(ctx context.Context, accessToken string, owner string, name string) (*github.Repository, *github.GithubErrorResponse) {
+	archived := true
+	return UpdateRepo(ctx, accessToken, owner, name, github.UpdateRepoRequest{Archived: &archived})
+}
+
+// DeleteRepo needs the access token to have the delete_repo scope.
+func DeleteRepo(ctx context.Context, accessToken string, owner string, name string) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodDelete, fmt.Sprintf(urlRepo, owner, name), accessToken, nil, nil)
+}
+
+// execute sends a call to github on behalf of accessToken, once the rate limit tracker
+// allows it, and decodes a successful response body into result (when not nil).
+func execute(ctx context.Context, httpMethod string, url string, accessToken string, body interface{}, result interface{}) *github.GithubErrorResponse {
+	_, err := executeWithHeaders(ctx, httpMethod, url, accessToken, body, result)
+	return err
+}
+
+// executeWithHeaders works as execute but also returns the headers of a successful response.
+func executeWithHeaders(ctx context.Context, httpMethod string, url string, accessToken string, body interface{}, result interface{}) (http.Header, *github.GithubErrorResponse) {
+	accessToken, err := resolveToken(ctx, url, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := rateLimits.wait(ctx, accessToken); err != nil {
+		return nil, err
+	}
+
+	return call(ctx, httpMethod, url, accessToken, body, result)
+}
+
+func send(ctx context.Context, httpMethod string, url string, accessToken string, body interface{}, result interface{}) *github.GithubErrorResponse {
+	accessToken, err := resolveToken(ctx, url, accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = call(ctx, httpMethod, url, accessToken, body, result)
+	return err
+}
+
+func call(ctx context.Context, httpMethod string, url string, accessToken string, body interface{}, result interface{}) (http.Header, *github.GithubErrorResponse) {
+	return callWithAuthorization(ctx, httpMethod, url, getAuthorizationHeader(accessToken), accessToken, body, result)
+}
+
+// callWithAuthorization sends the call with the given authorization header, tracking
+// the rate limit of the response under rateKey.
+func callWithAuthorization(ctx context.Context, httpMethod string, url string, authorization string, rateKey string, body interface{}, result interface{}) (http.Header, *github.GithubErrorResponse) {
 	headers := http.Header{}
-	headers.Set(headerAuthorization, getAuthorizationHeader(accessToken))
+	headers.Set(headerAuthorization, authorization)
 
-	resp, err := restclient.Post(urlCreateRepo, request, headers)
+	resp, err := restclient.Do(getRequestContext(ctx), httpMethod, url, body, headers)
 
 	if err != nil {
-		log.Println(fmt.Sprintf("Error when trying to create new repo in github: %s", err.Error()))
+		log.Println(fmt.Sprintf("Error when trying to send %s %s to github: %s", httpMethod, url, err.Error()))
 		return nil, &github.GithubErrorResponse{
 			StatusCode: http.StatusInternalServerError,
 			Message:    err.Error(),
 		}
 	}
 
+	defer resp.Body.Close()
 	bytes, err := ioutil.ReadAll(resp.Body)
 
 	if err != nil {
@@ -43,7 +199,7 @@ func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.C
 		}
 	}
 
-	defer resp.Body.Close()
+	rateLimits.update(rateKey, resp)
 
 	if resp.StatusCode > 299 {
 		var errorResp github.GithubErrorResponse
@@ -54,17 +210,20 @@ func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.C
 			}
 		}
 		errorResp.StatusCode = resp.StatusCode
-		return nil, &errorResp
+		return nil, rateLimits.translateError(rateKey, resp, &errorResp)
 	}
 
-	var result github.CreateRepoResponse
-	if err := json.Unmarshal(bytes, &result); err != nil {
-		log.Println(fmt.Sprintf("Error when trying to unmarshal create repo success response: %s", err.Error()))
+	if result == nil || len(bytes) == 0 {
+		return resp.Header, nil
+	}
+
+	if err := json.Unmarshal(bytes, result); err != nil {
+		log.Println(fmt.Sprintf("Error when trying to unmarshal github success response: %s", err.Error()))
 		return nil, &github.GithubErrorResponse{
 			StatusCode: http.StatusInternalServerError,
-			Message:    "error when trying to unmarshal github create repo response",
+			Message:    fmt.Sprintf("error when trying to unmarshal github %s response", httpMethod),
 		}
 	}
 
-	return &result, nil
+	return resp.Header, nil
 }
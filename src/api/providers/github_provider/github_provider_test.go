@@ -1,6 +1,7 @@
 package github_provider
 
 import (
+	"context"
 	"errors"
 	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
 	"github.com/dmolina79/golang-github-api/src/api/domain/github"
@@ -21,6 +22,8 @@ func TestConstants(t *testing.T) {
 	assert.EqualValues(t, "Authorization", headerAuthorization)
 	assert.EqualValues(t, "token %s", headerAuthorizationFormat)
 	assert.EqualValues(t, "https://api.github.com/user/repos", urlCreateRepo)
+	assert.EqualValues(t, "https://api.github.com/orgs/%s/repos", urlCreateOrgRepo)
+	assert.EqualValues(t, "https://api.github.com/repos/%s/%s", urlRepo)
 }
 
 func Test_getAuthorizationHeader(t *testing.T) {
@@ -36,7 +39,7 @@ func TestCreateRepoErrorRestclient(t *testing.T) {
 		Err:        errors.New("Invalid rest client response"),
 	})
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -54,7 +57,7 @@ func TestCreateRepoErrorUnauthorized(t *testing.T) {
 		},
 	})
 
-	response, err := CreateRepo("", github.CreateRepoRequest{})
+	response, err := CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, response)
 	assert.NotNil(t, err)
@@ -73,7 +76,7 @@ func TestCreateRepoSuccess(t *testing.T) {
 		},
 	})
 
-	r, err := CreateRepo("", github.CreateRepoRequest{})
+	r, err := CreateRepo(context.Background(), "", github.CreateRepoRequest{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, r)
@@ -81,3 +84,220 @@ func TestCreateRepoSuccess(t *testing.T) {
 	assert.EqualValues(t, "my-github-repo", r.Name)
 	assert.EqualValues(t, "dmolina79", r.Owner.Login)
 }
+
+func TestCreateOrgRepoNotMember(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Not Found","documentation_url":"https://developer.github.com/v3/repos/#create"}`)),
+		},
+	})
+
+	response, err := CreateOrgRepo(context.Background(), "", "my-org", github.CreateRepoRequest{})
+
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+	assert.EqualValues(t, "organization my-org not found or access token is not a member", err.Message)
+}
+
+func TestCreateOrgRepoForbidden(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Must have admin rights to Repository."}`)),
+		},
+	})
+
+	response, err := CreateOrgRepo(context.Background(), "", "my-org", github.CreateRepoRequest{})
+
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "access token is not allowed to create repositories in organization my-org: Must have admin rights to Repository.", err.Message)
+}
+
+func TestCreateOrgRepoSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-github-repo", "visibility": "internal", "owner": { "login": "my-org" } }`)),
+		},
+	})
+
+	r, err := CreateOrgRepo(context.Background(), "", "my-org", github.CreateRepoRequest{Visibility: "internal"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, r)
+	assert.EqualValues(t, "my-org", r.Owner.Login)
+	assert.EqualValues(t, "internal", r.Visibility)
+}
+
+func TestDeleteRepoSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+			Body:       ioutil.NopCloser(strings.NewReader(``)),
+		},
+	})
+
+	err := DeleteRepo(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+}
+
+func TestDeleteRepoForbidden(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Must have admin rights to Repository."}`)),
+		},
+	})
+
+	err := DeleteRepo(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "Must have admin rights to Repository.", err.Message)
+}
+
+func TestGetRepoNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Not Found"}`)),
+		},
+	})
+
+	r, err := GetRepo(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, r)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
+
+func TestGetRepoSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-github-repo", "archived": true, "owner": { "login": "dmolina79" } }`)),
+		},
+	})
+
+	r, err := GetRepo(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, r)
+	assert.EqualValues(t, 123, r.Id)
+	assert.True(t, r.Archived)
+}
+
+func TestArchiveRepoSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-github-repo", "archived": true}`)),
+		},
+	})
+
+	r, err := ArchiveRepo(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, r)
+	assert.True(t, r.Archived)
+}
+
+func TestGenerateRepoSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/template/generate",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-github-repo", "owner": { "login": "my-org" } }`)),
+		},
+	})
+
+	r, err := GenerateRepo(context.Background(), "", "my-org", "template", github.GenerateRepoRequest{Owner: "my-org", Name: "my-github-repo"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, r)
+	assert.EqualValues(t, "my-github-repo", r.Name)
+}
+
+func TestForkRepoSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/forks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-fork", "owner": { "login": "my-org" } }`)),
+		},
+	})
+
+	r, err := ForkRepo(context.Background(), "", "dmolina79", "my-github-repo", github.ForkRepoRequest{Organization: "my-org", Name: "my-fork"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, r)
+	assert.EqualValues(t, "my-org", r.Owner.Login)
+}
+
+func TestRepoHasCommitsEmpty(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/commits?per_page=1",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusConflict,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Git Repository is empty."}`)),
+		},
+	})
+
+	ready, err := RepoHasCommits(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.False(t, ready)
+}
+
+func TestRepoHasCommitsUnauthorized(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/commits?per_page=1",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Bad credentials"}`)),
+		},
+	})
+
+	ready, err := RepoHasCommits(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.False(t, ready)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.StatusCode)
+}
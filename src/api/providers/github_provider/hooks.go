@@ -0,0 +1,79 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+)
+
+const (
+	urlRepoHooks  = "https://api.github.com/repos/%s/%s/hooks"
+	urlListHooks  = "https://api.github.com/repos/%s/%s/hooks?per_page=100"
+	urlRepoHook   = "https://api.github.com/repos/%s/%s/hooks/%d"
+	urlHookConfig = "https://api.github.com/repos/%s/%s/hooks/%d/config"
+	urlHookPings  = "https://api.github.com/repos/%s/%s/hooks/%d/pings"
+)
+
+func CreateHook(ctx context.Context, accessToken string, owner string, name string, request github.CreateHookRequest) (*github.Hook, *github.GithubErrorResponse) {
+	var result github.Hook
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlRepoHooks, owner, name), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListHooks returns every webhook of owner/name, following the pages. Github masks the
+// secrets of the returned configs.
+func ListHooks(ctx context.Context, accessToken string, owner string, name string) ([]github.Hook, *github.GithubErrorResponse) {
+	var hooks []github.Hook
+	for pageUrl := fmt.Sprintf(urlListHooks, owner, name); pageUrl != ""; {
+		var page []github.Hook
+		var err *github.GithubErrorResponse
+		if pageUrl, err = fetchPage(ctx, accessToken, owner, pageUrl, &page); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, page...)
+	}
+
+	return hooks, nil
+}
+
+func GetHook(ctx context.Context, accessToken string, owner string, name string, id int64) (*github.Hook, *github.GithubErrorResponse) {
+	var result github.Hook
+	if err := execute(ctx, http.MethodGet, fmt.Sprintf(urlRepoHook, owner, name, id), accessToken, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func UpdateHook(ctx context.Context, accessToken string, owner string, name string, id int64, request github.UpdateHookRequest) (*github.Hook, *github.GithubErrorResponse) {
+	var result github.Hook
+	if err := execute(ctx, http.MethodPatch, fmt.Sprintf(urlRepoHook, owner, name, id), accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateHookConfig changes the fields of config that are set, keeping the others.
+func UpdateHookConfig(ctx context.Context, accessToken string, owner string, name string, id int64, config github.HookConfig) (*github.HookConfig, *github.GithubErrorResponse) {
+	var result github.HookConfig
+	if err := execute(ctx, http.MethodPatch, fmt.Sprintf(urlHookConfig, owner, name, id), accessToken, config, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PingHook makes github send a ping event to the webhook, the delivery itself happens
+// asynchronously.
+func PingHook(ctx context.Context, accessToken string, owner string, name string, id int64) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodPost, fmt.Sprintf(urlHookPings, owner, name, id), accessToken, nil, nil)
+}
+
+func DeleteHook(ctx context.Context, accessToken string, owner string, name string, id int64) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodDelete, fmt.Sprintf(urlRepoHook, owner, name, id), accessToken, nil, nil)
+}
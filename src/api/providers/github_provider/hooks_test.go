@@ -0,0 +1,82 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateHookSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/hooks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"id": 12345678, "name": "web", "active": true, "events": ["push", "pull_request"],
+				"config": {"url": "https://ci.example.com/hook", "content_type": "json", "insecure_ssl": "0", "secret": "********"}}`)),
+		},
+	})
+
+	hook, err := CreateHook(context.Background(), "", "dmolina79", "my-github-repo", github.CreateHookRequest{
+		Name:   github.HookName,
+		Active: true,
+		Events: []string{"push", "pull_request"},
+		Config: github.HookConfig{Url: "https://ci.example.com/hook", ContentType: "json", Secret: "s3cr3t"},
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, hook)
+	assert.EqualValues(t, 12345678, hook.Id)
+	assert.EqualValues(t, "https://ci.example.com/hook", hook.Config.Url)
+	assert.EqualValues(t, []string{"push", "pull_request"}, hook.Events)
+}
+
+func TestListHooksFollowsPages(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/hooks?per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/repositories/1/hooks?per_page=100&page=2>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 1, "config": {"url": "https://ci.example.com/hook"}}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repositories/1/hooks?per_page=100&page=2",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 2, "config": {"url": "https://chat.example.com/hook"}}]`)),
+		},
+	})
+
+	hooks, err := ListHooks(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(hooks))
+	assert.EqualValues(t, 2, hooks[1].Id)
+}
+
+func TestPingHookNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/hooks/42/pings",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	err := PingHook(context.Background(), "", "dmolina79", "my-github-repo", 42)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
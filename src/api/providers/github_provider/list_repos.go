@@ -0,0 +1,105 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	headerLink       = "Link"
+	urlApiBase       = "https://api.github.com/"
+	urlListUserRepos = "https://api.github.com/user/repos"
+	linkRelNext      = `rel="next"`
+)
+
+// ListRepos fetches the first page of repositories of the authenticated user, or of
+// org when not empty, matching request.
+func ListRepos(ctx context.Context, accessToken string, org string, request github.ListReposRequest) (*github.ReposPage, *github.GithubErrorResponse) {
+	listUrl := urlListUserRepos
+	if org != "" {
+		listUrl = fmt.Sprintf(urlCreateOrgRepo, org)
+	}
+
+	query := url.Values{}
+	setQuery(query, "type", request.Type)
+	setQuery(query, "visibility", request.Visibility)
+	setQuery(query, "sort", request.Sort)
+	setQuery(query, "direction", request.Direction)
+	if request.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(request.PerPage))
+	}
+	if request.Page > 0 {
+		query.Set("page", strconv.Itoa(request.Page))
+	}
+	if len(query) > 0 {
+		listUrl = listUrl + "?" + query.Encode()
+	}
+
+	return ListReposPage(ctx, accessToken, listUrl)
+}
+
+// ListReposPage fetches the page at pageUrl, as linked by a previous page.
+func ListReposPage(ctx context.Context, accessToken string, pageUrl string) (*github.ReposPage, *github.GithubErrorResponse) {
+	var repos []github.Repository
+	nextUrl, err := fetchPage(ctx, accessToken, "", pageUrl, &repos)
+	if err != nil {
+		return nil, err
+	}
+
+	return &github.ReposPage{Repos: repos, NextUrl: nextUrl}, nil
+}
+
+// fetchPage reads the page at pageUrl into result and returns the url of the next one,
+// empty on the last page. Owner, when set, is the one the pages belong to: the next
+// ones link to repositories/{id}, naming no owner.
+func fetchPage(ctx context.Context, accessToken string, owner string, pageUrl string, result interface{}) (string, *github.GithubErrorResponse) {
+	// the access token is sent along, so only github itself may be followed
+	if !strings.HasPrefix(pageUrl, urlApiBase) {
+		return "", &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    fmt.Sprintf("invalid next page url %s", pageUrl),
+		}
+	}
+	if owner != "" {
+		ctx = WithOwner(ctx, owner)
+	}
+
+	headers, err := executeWithHeaders(ctx, http.MethodGet, pageUrl, accessToken, nil, result)
+	if err != nil {
+		return "", err
+	}
+	return parseNextLink(headers.Get(headerLink)), nil
+}
+
+// parseNextLink extracts the rel="next" url out of a Link header such as
+// <https://api.github.com/user/repos?page=2>; rel="next", <...?page=5>; rel="last"
+func parseNextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) != linkRelNext {
+				continue
+			}
+			target := strings.TrimSpace(parts[0])
+			if strings.HasPrefix(target, "<") && strings.HasSuffix(target, ">") {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
+
+func setQuery(query url.Values, key string, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,72 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseNextLink(t *testing.T) {
+	header := `<https://api.github.com/user/repos?page=3>; rel="prev", <https://api.github.com/user/repos?page=5>; rel="next", <https://api.github.com/user/repos?page=9>; rel="last"`
+
+	assert.EqualValues(t, "https://api.github.com/user/repos?page=5", parseNextLink(header))
+	assert.EqualValues(t, "", parseNextLink(`<https://api.github.com/user/repos?page=1>; rel="first"`))
+	assert.EqualValues(t, "", parseNextLink(""))
+}
+
+func TestListReposWithFilters(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos?direction=asc&page=2&per_page=10&sort=created&type=forks",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/organizations/1/repos?page=3&per_page=10>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 1, "name": "one"}, {"id": 2, "name": "two"}]`)),
+		},
+	})
+
+	page, err := ListRepos(context.Background(), "", "my-org", github.ListReposRequest{
+		Type:      "forks",
+		Sort:      "created",
+		Direction: "asc",
+		PerPage:   10,
+		Page:      2,
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, page)
+	assert.EqualValues(t, 2, len(page.Repos))
+	assert.EqualValues(t, "https://api.github.com/organizations/1/repos?page=3&per_page=10", page.NextUrl)
+}
+
+func TestListReposLastPage(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 1, "name": "one"}]`)),
+		},
+	})
+
+	page, err := ListRepos(context.Background(), "", "", github.ListReposRequest{})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(page.Repos))
+	assert.EqualValues(t, "", page.NextUrl)
+}
+
+func TestListReposPageRejectsForeignUrl(t *testing.T) {
+	page, err := ListReposPage(context.Background(), "", "https://example.com/user/repos?page=2")
+
+	assert.Nil(t, page)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+}
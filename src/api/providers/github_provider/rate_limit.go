@@ -0,0 +1,182 @@
+package github_provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitUsed      = "X-RateLimit-Used"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+	urlRateLimit             = "https://api.github.com/rate_limit"
+
+	defaultSecondaryRateLimitWait = time.Minute
+)
+
+var (
+	rateLimits = newRateLimitTracker()
+)
+
+type rateLimitState struct {
+	github.RateLimit
+	known        bool
+	blockedUntil time.Time
+}
+
+// rateLimitTracker keeps the last known github budget of every access token so calls
+// can be paused, or rejected, before github starts failing them.
+type rateLimitTracker struct {
+	mutex  sync.Mutex
+	states map[string]*rateLimitState
+}
+
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{states: make(map[string]*rateLimitState)}
+}
+
+// tokenKey avoids keeping raw access tokens around as map keys.
+func tokenKey(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (t *rateLimitTracker) state(accessToken string) *rateLimitState {
+	key := tokenKey(accessToken)
+	current := t.states[key]
+	if current == nil {
+		current = &rateLimitState{}
+		t.states[key] = current
+	}
+	return current
+}
+
+// pausedUntil returns the moment the budget of accessToken is available again,
+// or the zero time when calls can go through right away.
+func (t *rateLimitTracker) pausedUntil(accessToken string) time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	current := t.state(accessToken)
+	until := current.blockedUntil
+	if current.known && current.Remaining <= 0 {
+		if reset := time.Unix(current.Reset, 0); reset.After(until) {
+			until = reset
+		}
+	}
+
+	if !until.After(now) {
+		return time.Time{}
+	}
+	return until
+}
+
+// wait holds the call while the budget is exhausted, as long as it is restored within
+// the configured max wait. Longer pauses are rejected with a 429 right away.
+func (t *rateLimitTracker) wait(ctx context.Context, accessToken string) *github.GithubErrorResponse {
+	until := t.pausedUntil(accessToken)
+	if until.IsZero() {
+		return nil
+	}
+
+	wait := time.Until(until)
+	if wait > config.GetGithubRateLimitMaxWait() {
+		return newRateLimitError(until, "github rate limit exhausted for access token")
+	}
+
+	log.Println(fmt.Sprintf("github rate limit exhausted, pausing call for %s", wait))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// the budget is still exhausted, the caller gave up waiting for it
+		return newRateLimitError(until, "github rate limit exhausted for access token, gave up waiting")
+	case <-timer.C:
+		return nil
+	}
+}
+
+// update records the budget reported by github in the response headers.
+func (t *rateLimitTracker) update(accessToken string, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	current := t.state(accessToken)
+	current.known = true
+	current.Remaining = remaining
+	current.Limit, _ = strconv.Atoi(resp.Header.Get(headerRateLimitLimit))
+	current.Used, _ = strconv.Atoi(resp.Header.Get(headerRateLimitUsed))
+	current.Reset, _ = strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64)
+}
+
+// translateError turns primary and secondary rate limit responses into a 429 carrying
+// the reset time, and blocks the token until then. Any other error is returned as is.
+func (t *rateLimitTracker) translateError(accessToken string, resp *http.Response, err *github.GithubErrorResponse) *github.GithubErrorResponse {
+	if err.StatusCode != http.StatusForbidden && err.StatusCode != http.StatusTooManyRequests {
+		return err
+	}
+
+	var until time.Time
+	if seconds, convErr := strconv.Atoi(resp.Header.Get(headerRetryAfter)); convErr == nil {
+		until = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else if resp.Header.Get(headerRateLimitRemaining) == "0" {
+		reset, _ := strconv.ParseInt(resp.Header.Get(headerRateLimitReset), 10, 64)
+		until = time.Unix(reset, 0)
+	} else if strings.Contains(strings.ToLower(err.Message), "secondary rate limit") {
+		until = time.Now().Add(defaultSecondaryRateLimitWait)
+	} else {
+		return err
+	}
+
+	t.mutex.Lock()
+	if current := t.state(accessToken); until.After(current.blockedUntil) {
+		current.blockedUntil = until
+	}
+	t.mutex.Unlock()
+
+	return newRateLimitError(until, err.Message)
+}
+
+func newRateLimitError(until time.Time, message string) *github.GithubErrorResponse {
+	return &github.GithubErrorResponse{
+		StatusCode:     http.StatusTooManyRequests,
+		Message:        fmt.Sprintf("%s, resets at %s", message, until.UTC().Format(time.RFC3339)),
+		RateLimitReset: until.Unix(),
+	}
+}
+
+// GetRateLimit fetches the current budget of accessToken. Github does not count
+// this call against the budget so it is never paused.
+func GetRateLimit(ctx context.Context, accessToken string) (*github.RateLimitResponse, *github.GithubErrorResponse) {
+	var result github.RateLimitResponse
+	if err := send(ctx, http.MethodGet, urlRateLimit, accessToken, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetRateLimitPausedUntil returns until when calls for accessToken are held back, or
+// the zero time when they are not.
+func GetRateLimitPausedUntil(accessToken string) time.Time {
+	return rateLimits.pausedUntil(accessToken)
+}
@@ -0,0 +1,177 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rateLimitHeaders(remaining int, reset time.Time) http.Header {
+	headers := http.Header{}
+	headers.Set(headerRateLimitLimit, "5000")
+	headers.Set(headerRateLimitRemaining, fmt.Sprintf("%d", remaining))
+	headers.Set(headerRateLimitUsed, fmt.Sprintf("%d", 5000-remaining))
+	headers.Set(headerRateLimitReset, fmt.Sprintf("%d", reset.Unix()))
+	return headers
+}
+
+func TestRateLimitTracksHeaders(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     rateLimitHeaders(10, time.Now().Add(time.Hour)),
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123}`)),
+		},
+	})
+
+	_, err := CreateRepo(context.Background(), "abc", github.CreateRepoRequest{})
+
+	assert.Nil(t, err)
+	state := rateLimits.state("abc")
+	assert.True(t, state.known)
+	assert.EqualValues(t, 5000, state.Limit)
+	assert.EqualValues(t, 10, state.Remaining)
+	assert.True(t, GetRateLimitPausedUntil("abc").IsZero())
+}
+
+func TestRateLimitPrimaryExhausted(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	reset := time.Now().Add(time.Hour)
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     rateLimitHeaders(0, reset),
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"API rate limit exceeded"}`)),
+		},
+	})
+
+	response, err := CreateRepo(context.Background(), "abc", github.CreateRepoRequest{})
+
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.EqualValues(t, reset.Unix(), err.RateLimitReset)
+	assert.True(t, strings.HasPrefix(err.Message, "API rate limit exceeded, resets at "))
+
+	// the next call is rejected without reaching github
+	restclient.FlushMockups()
+	response, err = CreateRepo(context.Background(), "abc", github.CreateRepoRequest{})
+
+	assert.Nil(t, response)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.True(t, strings.HasPrefix(err.Message, "github rate limit exhausted for access token"))
+	assert.EqualValues(t, reset.Unix(), GetRateLimitPausedUntil("abc").Unix())
+	assert.True(t, GetRateLimitPausedUntil("another-token").IsZero())
+}
+
+func TestRateLimitSecondary(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	headers := http.Header{}
+	headers.Set(headerRetryAfter, "120")
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     headers,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"You have exceeded a secondary rate limit."}`)),
+		},
+	})
+
+	_, err := CreateRepo(context.Background(), "abc", github.CreateRepoRequest{})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.InDelta(t, time.Now().Add(120*time.Second).Unix(), err.RateLimitReset, 2)
+	assert.False(t, GetRateLimitPausedUntil("abc").IsZero())
+}
+
+func TestRateLimitPlainForbiddenUntouched(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     rateLimitHeaders(100, time.Now().Add(time.Hour)),
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Resource not accessible by integration"}`)),
+		},
+	})
+
+	_, err := CreateRepo(context.Background(), "abc", github.CreateRepoRequest{})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "Resource not accessible by integration", err.Message)
+	assert.True(t, GetRateLimitPausedUntil("abc").IsZero())
+}
+
+func TestRateLimitPausesShortWaits(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	rateLimits.state("abc").blockedUntil = time.Now().Add(50 * time.Millisecond)
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123}`)),
+		},
+	})
+
+	start := time.Now()
+	r, err := CreateRepo(context.Background(), "abc", github.CreateRepoRequest{})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 123, r.Id)
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestRateLimitWaitCancelled(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	until := time.Now().Add(5 * time.Second)
+	rateLimits.state("abc").blockedUntil = until
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := CreateRepo(ctx, "abc", github.CreateRepoRequest{})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.EqualValues(t, until.Unix(), err.RateLimitReset)
+}
+
+func TestGetRateLimit(t *testing.T) {
+	rateLimits = newRateLimitTracker()
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/rate_limit",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"resources":{"core":{"limit":5000,"remaining":4999,"used":1,"reset":1700000000}},"rate":{"limit":5000,"remaining":4999,"used":1,"reset":1700000000}}`)),
+		},
+	})
+
+	res, err := GetRateLimit(context.Background(), "abc")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 4999, res.Rate.Remaining)
+	assert.EqualValues(t, 5000, res.Resources["core"].Limit)
+}
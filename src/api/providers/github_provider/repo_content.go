@@ -0,0 +1,108 @@
+package github_provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	urlRepoTopics  = "https://api.github.com/repos/%s/%s/topics"
+	urlRepoLabels  = "https://api.github.com/repos/%s/%s/labels"
+	urlListLabels  = "https://api.github.com/repos/%s/%s/labels?per_page=100"
+	urlMilestones  = "https://api.github.com/repos/%s/%s/milestones"
+	urlRepoLabel   = "https://api.github.com/repos/%s/%s/labels/%s"
+	urlTeamRepo    = "https://api.github.com/orgs/%s/teams/%s/repos/%s/%s"
+	urlRepoContent = "https://api.github.com/repos/%s/%s/contents/%s"
+)
+
+// ReplaceTopics sets the topics of owner/name, removing any other one.
+func ReplaceTopics(ctx context.Context, accessToken string, owner string, name string, topics []string) (*github.Topics, *github.GithubErrorResponse) {
+	var result github.Topics
+	if err := execute(ctx, http.MethodPut, fmt.Sprintf(urlRepoTopics, owner, name), accessToken, github.Topics{Names: topics}, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func GetTopics(ctx context.Context, accessToken string, owner string, name string) (*github.Topics, *github.GithubErrorResponse) {
+	var result github.Topics
+	if err := execute(ctx, http.MethodGet, fmt.Sprintf(urlRepoTopics, owner, name), accessToken, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func CreateLabel(ctx context.Context, accessToken string, owner string, name string, label github.Label) (*github.Label, *github.GithubErrorResponse) {
+	var result github.Label
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlRepoLabels, owner, name), accessToken, label, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateLabel changes the label currently named current, renaming it to label.Name.
+func UpdateLabel(ctx context.Context, accessToken string, owner string, name string, current string, label github.Label) (*github.Label, *github.GithubErrorResponse) {
+	var result github.Label
+	labelUrl := fmt.Sprintf(urlRepoLabel, owner, name, url.PathEscape(current))
+	request := github.UpdateLabelRequest{NewName: label.Name, Color: label.Color, Description: label.Description}
+	if err := execute(ctx, http.MethodPatch, labelUrl, accessToken, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListLabels returns every label of owner/name, following the pages github splits them into.
+func ListLabels(ctx context.Context, accessToken string, owner string, name string) ([]github.Label, *github.GithubErrorResponse) {
+	var labels []github.Label
+	for pageUrl := fmt.Sprintf(urlListLabels, owner, name); pageUrl != ""; {
+		var page []github.Label
+		var err *github.GithubErrorResponse
+		if pageUrl, err = fetchPage(ctx, accessToken, owner, pageUrl, &page); err != nil {
+			return nil, err
+		}
+		labels = append(labels, page...)
+	}
+
+	return labels, nil
+}
+
+func DeleteLabel(ctx context.Context, accessToken string, owner string, name string, label string) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodDelete, fmt.Sprintf(urlRepoLabel, owner, name, url.PathEscape(label)), accessToken, nil, nil)
+}
+
+func CreateMilestone(ctx context.Context, accessToken string, owner string, name string, milestone github.Milestone) (*github.Milestone, *github.GithubErrorResponse) {
+	var result github.Milestone
+	if err := execute(ctx, http.MethodPost, fmt.Sprintf(urlMilestones, owner, name), accessToken, milestone, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// AddTeamRepo grants the team teamSlug of org the given permission on owner/name.
+func AddTeamRepo(ctx context.Context, accessToken string, org string, teamSlug string, owner string, name string, permission string) *github.GithubErrorResponse {
+	teamUrl := fmt.Sprintf(urlTeamRepo, org, url.PathEscape(teamSlug), owner, name)
+	return execute(ctx, http.MethodPut, teamUrl, accessToken, github.TeamRepoRequest{Permission: permission}, nil)
+}
+
+// CreateFile commits a new file at path, each call producing its own commit.
+func CreateFile(ctx context.Context, accessToken string, owner string, name string, path string, request github.CreateFileRequest) *github.GithubErrorResponse {
+	return execute(ctx, http.MethodPut, fmt.Sprintf(urlRepoContent, owner, name, escapePath(path)), accessToken, request, nil)
+}
+
+// escapePath escapes every segment of a file path while keeping its slashes.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,141 @@
+package github_provider
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReplaceTopicsSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/topics",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"names": ["go", "api"]}`)),
+		},
+	})
+
+	topics, err := ReplaceTopics(context.Background(), "", "dmolina79", "my-github-repo", []string{"go", "api"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go", "api"}, topics.Names)
+}
+
+func TestGetTopicsSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/topics",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"names": ["go"]}`)),
+		},
+	})
+
+	topics, err := GetTopics(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go"}, topics.Names)
+}
+
+func TestUpdateLabelEscapesName(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/labels/good%20first%20issue",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"name": "good first issue", "color": "7057ff"}`)),
+		},
+	})
+
+	label, err := UpdateLabel(context.Background(), "", "dmolina79", "my-github-repo", "good first issue", github.Label{Name: "good first issue", Color: "7057ff"})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, "7057ff", label.Color)
+}
+
+func TestAddTeamRepoNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/teams/platform/repos/my-org/my-github-repo",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	err := AddTeamRepo(context.Background(), "", "my-org", "platform", "my-org", "my-github-repo", "push")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
+
+func TestCreateFileSuccess(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/contents/.github/CODE%20OWNERS",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"content": {"path": ".github/CODE OWNERS"}}`)),
+		},
+	})
+
+	err := CreateFile(context.Background(), "", "dmolina79", "my-github-repo", ".github/CODE OWNERS", github.CreateFileRequest{Message: "Add CODEOWNERS", Content: "KiBAbWU="})
+
+	assert.Nil(t, err)
+}
+
+func TestListLabelsFollowsPages(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/labels?per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/repositories/1/labels?per_page=100&page=2>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"name": "bug", "color": "d73a4a"}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repositories/1/labels?per_page=100&page=2",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"name": "wontfix", "color": "ffffff"}]`)),
+		},
+	})
+
+	labels, err := ListLabels(context.Background(), "", "dmolina79", "my-github-repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(labels))
+	assert.EqualValues(t, "wontfix", labels[1].Name)
+}
+
+func TestCreateMilestoneInvalid(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-github-repo/milestones",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Validation Failed"}`)),
+		},
+	})
+
+	milestone, err := CreateMilestone(context.Background(), "", "dmolina79", "my-github-repo", github.Milestone{Title: "v1"})
+
+	assert.Nil(t, milestone)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.StatusCode)
+}
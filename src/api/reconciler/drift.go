@@ -0,0 +1,61 @@
+package reconciler
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"sort"
+	"strings"
+)
+
+// DiffManaged compares the recorded state of a managed repository against what github
+// reports for it. Policy is the protection policy recorded for it, nil when its branch
+// protection is not managed.
+func DiffManaged(desired drift.ManagedRepo, live drift.LiveRepo, policy *repositories.ProtectionPolicy) []reconcile.Change {
+	d := &differ{}
+	current := live.Repository
+	d.text("visibility", desired.Visibility, current.GetVisibility())
+	if desired.DefaultBranch != "" {
+		d.text("default_branch", desired.DefaultBranch, current.DefaultBranch)
+	}
+	d.flag("has_issues", &desired.HasIssues, current.HasIssues)
+	d.flag("has_projects", &desired.HasProjects, current.HasProjects)
+	d.flag("has_wiki", &desired.HasWiki, current.HasWiki)
+	d.set("topics", desired.Topics, live.Topics)
+
+	if policy != nil {
+		d.protection(*policy, live.Protection)
+	}
+	return d.changes
+}
+
+// set records a change when desired and live hold different items, in any order.
+func (d *differ) set(field string, desired []string, live []string) {
+	desired, live = sortedCopy(desired), sortedCopy(live)
+	if strings.Join(desired, "\n") != strings.Join(live, "\n") {
+		d.changes = append(d.changes, reconcile.Change{Field: field, Live: live, Desired: desired})
+	}
+}
+
+func sortedCopy(values []string) []string {
+	values = append([]string{}, values...)
+	sort.Strings(values)
+	return values
+}
+
+func (d *differ) protection(desired repositories.ProtectionPolicy, live *repositories.ProtectionPolicy) {
+	if live == nil {
+		d.changes = append(d.changes, reconcile.Change{Field: "branch_protection", Live: nil, Desired: desired})
+		return
+	}
+
+	if desired.RequiredReviews != live.RequiredReviews {
+		d.changes = append(d.changes, reconcile.Change{Field: "branch_protection.required_reviews", Live: live.RequiredReviews, Desired: desired.RequiredReviews})
+	}
+	d.flag("branch_protection.dismiss_stale_reviews", &desired.DismissStaleReviews, live.DismissStaleReviews)
+	d.flag("branch_protection.require_code_owner_reviews", &desired.RequireCodeOwnerReviews, live.RequireCodeOwnerReviews)
+	d.set("branch_protection.status_checks", desired.StatusChecks, live.StatusChecks)
+	d.flag("branch_protection.strict_status_checks", &desired.StrictStatusChecks, live.StrictStatusChecks)
+	d.flag("branch_protection.linear_history", &desired.LinearHistory, live.LinearHistory)
+	d.flag("branch_protection.enforce_admins", &desired.EnforceAdmins, live.EnforceAdmins)
+}
@@ -0,0 +1,63 @@
+package reconciler
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiffManaged_NoDrift(t *testing.T) {
+	desired := drift.ManagedRepo{Visibility: "private", DefaultBranch: "main", HasIssues: true, Topics: []string{"go", "api"}}
+	live := drift.LiveRepo{
+		Repository: repositories.Repository{Private: true, DefaultBranch: "main", HasIssues: true},
+		Topics:     []string{"api", "go"},
+	}
+
+	assert.EqualValues(t, 0, len(DiffManaged(desired, live, nil)))
+}
+
+func TestDiffManaged_Drift(t *testing.T) {
+	desired := drift.ManagedRepo{Visibility: "private", DefaultBranch: "main", HasWiki: true, Topics: []string{"go"}}
+	live := drift.LiveRepo{
+		Repository: repositories.Repository{Visibility: "public", DefaultBranch: "master"},
+	}
+
+	changes := DiffManaged(desired, live, nil)
+
+	assert.EqualValues(t, []reconcile.Change{
+		{Field: "visibility", Live: "public", Desired: "private"},
+		{Field: "default_branch", Live: "master", Desired: "main"},
+		{Field: "has_wiki", Live: false, Desired: true},
+		{Field: "topics", Live: []string{}, Desired: []string{"go"}},
+	}, changes)
+}
+
+func TestDiffManaged_ProtectionRemoved(t *testing.T) {
+	policy := repositories.ProtectionPolicy{RequiredReviews: 1}
+	desired := drift.ManagedRepo{Visibility: "public"}
+	live := drift.LiveRepo{Repository: repositories.Repository{Visibility: "public"}}
+
+	changes := DiffManaged(desired, live, &policy)
+
+	assert.EqualValues(t, 1, len(changes))
+	assert.EqualValues(t, "branch_protection", changes[0].Field)
+	assert.Nil(t, changes[0].Live)
+}
+
+func TestDiffManaged_ProtectionWeakened(t *testing.T) {
+	policy := repositories.ProtectionPolicy{RequiredReviews: 2, EnforceAdmins: true, StatusChecks: []string{"ci"}}
+	desired := drift.ManagedRepo{Visibility: "public"}
+	live := drift.LiveRepo{
+		Repository: repositories.Repository{Visibility: "public"},
+		Protection: &repositories.ProtectionPolicy{RequiredReviews: 1, StatusChecks: []string{"ci"}},
+	}
+
+	changes := DiffManaged(desired, live, &policy)
+
+	assert.EqualValues(t, []reconcile.Change{
+		{Field: "branch_protection.required_reviews", Live: 1, Desired: 2},
+		{Field: "branch_protection.enforce_admins", Live: false, Desired: true},
+	}, changes)
+}
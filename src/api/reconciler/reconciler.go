@@ -0,0 +1,115 @@
+package reconciler
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"sort"
+	"strings"
+)
+
+// Diff compares the validated manifest against the live repositories of its owner and
+// returns the actions bringing them to the desired state: creations first, then updates
+// and archives, each sorted by name. Archived live repositories are read only on github,
+// so they are never updated.
+func Diff(manifest reconcile.Manifest, live []repositories.Repository) []reconcile.Action {
+	liveByName := make(map[string]repositories.Repository, len(live))
+	for _, repo := range live {
+		liveByName[strings.ToLower(repo.Name)] = repo
+	}
+
+	var creates, updates, archives []reconcile.Action
+	listed := make(map[string]bool, len(manifest.Repos))
+	for _, desired := range manifest.Repos {
+		key := strings.ToLower(desired.Name)
+		listed[key] = true
+
+		current, exists := liveByName[key]
+		switch {
+		case !exists && !desired.Archived:
+			request := desired.CreateRequest(manifest.Org)
+			creates = append(creates, reconcile.Action{Type: reconcile.ActionCreate, Name: desired.Name, Create: &request})
+		case !exists || current.Archived:
+		case desired.Archived:
+			archives = append(archives, reconcile.Action{Type: reconcile.ActionArchive, Owner: current.Owner, Name: current.Name})
+		default:
+			if action := diffSettings(desired, current); action != nil {
+				updates = append(updates, *action)
+			}
+		}
+	}
+
+	if manifest.Prune {
+		for _, current := range live {
+			if !listed[strings.ToLower(current.Name)] && !current.Archived {
+				archives = append(archives, reconcile.Action{Type: reconcile.ActionArchive, Owner: current.Owner, Name: current.Name})
+			}
+		}
+	}
+
+	plan := make([]reconcile.Action, 0, len(creates)+len(updates)+len(archives))
+	for _, actions := range [][]reconcile.Action{creates, updates, archives} {
+		sort.Slice(actions, func(i, j int) bool { return actions[i].Name < actions[j].Name })
+		plan = append(plan, actions...)
+	}
+	return plan
+}
+
+// diffSettings returns the update action for the managed settings of desired that differ
+// from current, or nil when there are none.
+func diffSettings(desired reconcile.DesiredRepo, current repositories.Repository) *reconcile.Action {
+	d := &differ{}
+	name := desired.Name
+	d.update.Name = d.optionalText("name", &name, current.Name)
+	d.update.Description = d.optionalText("description", desired.Description, current.Description)
+	d.update.Homepage = d.optionalText("homepage", desired.Homepage, current.Homepage)
+	d.update.Visibility = d.optionalText("visibility", desired.Visibility, current.GetVisibility())
+	d.update.DefaultBranch = d.optionalText("default_branch", desired.DefaultBranch, current.DefaultBranch)
+	d.update.HasIssues = d.flag("has_issues", desired.HasIssues, current.HasIssues)
+	d.update.HasProjects = d.flag("has_projects", desired.HasProjects, current.HasProjects)
+	d.update.HasWiki = d.flag("has_wiki", desired.HasWiki, current.HasWiki)
+	d.update.IsTemplate = d.flag("is_template", desired.IsTemplate, current.IsTemplate)
+	d.update.AllowSquashMerge = d.flag("allow_squash_merge", desired.AllowSquashMerge, current.AllowSquashMerge)
+	d.update.AllowMergeCommit = d.flag("allow_merge_commit", desired.AllowMergeCommit, current.AllowMergeCommit)
+	d.update.AllowRebaseMerge = d.flag("allow_rebase_merge", desired.AllowRebaseMerge, current.AllowRebaseMerge)
+	d.update.DeleteBranchOnMerge = d.flag("delete_branch_on_merge", desired.DeleteBranchOnMerge, current.DeleteBranchOnMerge)
+
+	if len(d.changes) == 0 {
+		return nil
+	}
+	return &reconcile.Action{
+		Type:    reconcile.ActionUpdate,
+		Owner:   current.Owner,
+		Name:    current.Name,
+		Changes: d.changes,
+		Update:  &d.update,
+	}
+}
+
+// differ collects the changes between desired and live settings, along with the
+// update request applying them.
+type differ struct {
+	changes []reconcile.Change
+	update  repositories.UpdateRepoRequest
+}
+
+func (d *differ) text(field string, desired string, live string) {
+	if desired != live {
+		d.changes = append(d.changes, reconcile.Change{Field: field, Live: live, Desired: desired})
+	}
+}
+
+func (d *differ) optionalText(field string, desired *string, live string) *string {
+	if desired == nil || *desired == live {
+		return nil
+	}
+	d.text(field, *desired, live)
+	return desired
+}
+
+func (d *differ) flag(field string, desired *bool, live bool) *bool {
+	if desired == nil || *desired == live {
+		return nil
+	}
+	d.changes = append(d.changes, reconcile.Change{Field: field, Live: live, Desired: *desired})
+	return desired
+}
@@ -0,0 +1,75 @@
+package reconciler
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff_NothingToDo(t *testing.T) {
+	enabled := true
+	manifest := reconcile.Manifest{Repos: []reconcile.DesiredRepo{{Name: "my-repo", HasIssues: &enabled}}}
+	live := []repositories.Repository{{Owner: "dmolina79", Name: "my-repo", HasIssues: true, HasWiki: true}}
+
+	plan := Diff(manifest, live)
+
+	assert.EqualValues(t, 0, len(plan))
+}
+
+func TestDiff_Plan(t *testing.T) {
+	enabled := true
+	private := repositories.VisibilityPrivate
+	description := "the api"
+	manifest := reconcile.Manifest{
+		Org:   "my-org",
+		Prune: true,
+		Repos: []reconcile.DesiredRepo{
+			{Name: "web", Description: &description},
+			{Name: "api", Description: &description, Visibility: &private, HasWiki: &enabled},
+			{Name: "legacy", Archived: true},
+			{Name: "frozen", Description: &description},
+			{Name: "gone", Archived: true},
+		},
+	}
+	live := []repositories.Repository{
+		{Owner: "my-org", Name: "API", Description: "the api", Visibility: "public"},
+		{Owner: "my-org", Name: "legacy"},
+		{Owner: "my-org", Name: "frozen", Archived: true},
+		{Owner: "my-org", Name: "unlisted"},
+		{Owner: "my-org", Name: "old", Archived: true},
+	}
+
+	plan := Diff(manifest, live)
+
+	assert.EqualValues(t, 4, len(plan))
+
+	assert.EqualValues(t, reconcile.ActionCreate, plan[0].Type)
+	assert.EqualValues(t, "web", plan[0].Name)
+	assert.EqualValues(t, "my-org", plan[0].Create.Org)
+	assert.EqualValues(t, "the api", plan[0].Create.Description)
+
+	assert.EqualValues(t, reconcile.ActionUpdate, plan[1].Type)
+	assert.EqualValues(t, "API", plan[1].Name)
+	assert.EqualValues(t, []reconcile.Change{
+		{Field: "name", Live: "API", Desired: "api"},
+		{Field: "visibility", Live: "public", Desired: "private"},
+		{Field: "has_wiki", Live: false, Desired: true},
+	}, plan[1].Changes)
+	assert.EqualValues(t, "api", *plan[1].Update.Name)
+	assert.Nil(t, plan[1].Update.Description)
+	assert.EqualValues(t, "private", *plan[1].Update.Visibility)
+
+	assert.EqualValues(t, reconcile.ActionArchive, plan[2].Type)
+	assert.EqualValues(t, "legacy", plan[2].Name)
+	assert.EqualValues(t, reconcile.ActionArchive, plan[3].Type)
+	assert.EqualValues(t, "unlisted", plan[3].Name)
+}
+
+func TestDiff_VisibilityFromPrivate(t *testing.T) {
+	private := repositories.VisibilityPrivate
+	manifest := reconcile.Manifest{Repos: []reconcile.DesiredRepo{{Name: "my-repo", Visibility: &private}}}
+	live := []repositories.Repository{{Name: "my-repo", Private: true}}
+
+	assert.EqualValues(t, 0, len(Diff(manifest, live)))
+}
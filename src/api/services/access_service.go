@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+)
+
+type accessService struct{}
+
+type accessServiceInterface interface {
+	AddCollaborator(ctx context.Context, owner string, name string, user string, request repositories.CollaboratorRequest) (*repositories.CollaboratorResponse, errors.ApiError)
+	RemoveCollaborator(ctx context.Context, owner string, name string, user string) errors.ApiError
+	ListInvitations(ctx context.Context, owner string, name string) ([]repositories.Invitation, errors.ApiError)
+	SetTeamPermission(ctx context.Context, owner string, name string, team string, request repositories.TeamPermissionRequest) errors.ApiError
+	RemoveTeam(ctx context.Context, owner string, name string, team string) errors.ApiError
+}
+
+var (
+	AccessService accessServiceInterface
+)
+
+func init() {
+	AccessService = &accessService{}
+}
+
+// AddCollaborator grants user access to owner/name. Users who are not members of the
+// owning organization only get access once they accept the invitation github sends them.
+func (s *accessService) AddCollaborator(ctx context.Context, owner string, name string, user string, input repositories.CollaboratorRequest) (*repositories.CollaboratorResponse, errors.ApiError) {
+	if err := validateCollaboratorPath(owner, name, user); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	invitation, err := github_provider.AddCollaborator(ctx, config.GetGithubAccessToken(), owner, name, user, github.CollaboratorRequest{Permission: input.Permission})
+	if err != nil {
+		log.Error("error when trying to add collaborator", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("user:%s", user))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	response := &repositories.CollaboratorResponse{User: user, Permission: input.Permission, Status: repositories.CollaboratorAdded}
+	if invitation != nil {
+		response.Status = repositories.CollaboratorInvited
+		response.InvitationId = invitation.Id
+		response.HtmlUrl = invitation.HtmlUrl
+	}
+	log.Info("collaborator added", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("status:%s", response.Status))
+	return response, nil
+}
+
+// RemoveCollaborator revokes the access of user and cancels the invitations still
+// pending for it, so the user cannot accept one later.
+func (s *accessService) RemoveCollaborator(ctx context.Context, owner string, name string, user string) errors.ApiError {
+	if err := validateCollaboratorPath(owner, name, user); err != nil {
+		return err
+	}
+
+	accessToken := config.GetGithubAccessToken()
+	if err := github_provider.RemoveCollaborator(ctx, accessToken, owner, name, user); err != nil {
+		log.Error("error when trying to remove collaborator", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("user:%s", user))
+		return newApiErrorFromGithub(err)
+	}
+
+	invitations, err := github_provider.ListInvitations(ctx, accessToken, owner, name)
+	if err != nil {
+		log.Error("error when trying to list invitations", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return newApiErrorFromGithub(err)
+	}
+	for _, invitation := range invitations {
+		if !strings.EqualFold(invitation.Invitee.Login, user) {
+			continue
+		}
+		if err := github_provider.DeleteInvitation(ctx, accessToken, owner, name, invitation.Id); err != nil {
+			log.Error("error when trying to cancel invitation", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("invitation_id:%d", invitation.Id))
+			return newApiErrorFromGithub(err)
+		}
+	}
+
+	log.Info("collaborator removed", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+	return nil
+}
+
+func (s *accessService) ListInvitations(ctx context.Context, owner string, name string) ([]repositories.Invitation, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+
+	res, err := github_provider.ListInvitations(ctx, config.GetGithubAccessToken(), owner, name)
+	if err != nil {
+		log.Error("error when trying to list invitations", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	result := make([]repositories.Invitation, 0, len(res))
+	for _, invitation := range res {
+		result = append(result, repositories.Invitation{
+			Id:         invitation.Id,
+			User:       invitation.Invitee.Login,
+			Permission: invitation.Permissions,
+			HtmlUrl:    invitation.HtmlUrl,
+			CreatedAt:  invitation.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// SetTeamPermission grants team access to owner/name, teams only exist within the
+// organization owning the repository.
+func (s *accessService) SetTeamPermission(ctx context.Context, owner string, name string, team string, input repositories.TeamPermissionRequest) errors.ApiError {
+	if err := validateTeamPath(owner, name, team); err != nil {
+		return err
+	}
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if err := github_provider.AddTeamRepo(ctx, config.GetGithubAccessToken(), owner, team, owner, name, input.Permission); err != nil {
+		log.Error("error when trying to grant team access", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("team:%s", team))
+		return newApiErrorFromGithub(err)
+	}
+
+	return nil
+}
+
+func (s *accessService) RemoveTeam(ctx context.Context, owner string, name string, team string) errors.ApiError {
+	if err := validateTeamPath(owner, name, team); err != nil {
+		return err
+	}
+
+	if err := github_provider.RemoveTeamRepo(ctx, config.GetGithubAccessToken(), owner, team, owner, name); err != nil {
+		log.Error("error when trying to revoke team access", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("team:%s", team))
+		return newApiErrorFromGithub(err)
+	}
+
+	return nil
+}
+
+func validateCollaboratorPath(owner string, name string, user string) errors.ApiError {
+	if err := validateRepoPath(owner, name); err != nil {
+		return err
+	}
+
+	return repositories.ValidateLogin(user)
+}
+
+func validateTeamPath(owner string, name string, team string) errors.ApiError {
+	if err := validateRepoPath(owner, name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(team) == "" {
+		return errors.NewBadRequestError("Invalid team")
+	}
+
+	return nil
+}
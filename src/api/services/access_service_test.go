@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAccessService_AddCollaborator_InvalidPermission(t *testing.T) {
+	res, err := AccessService.AddCollaborator(context.Background(), "my-org", "my-repo", "octocat", repositories.CollaboratorRequest{Permission: "owner"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestAccessService_AddCollaborator_Invited(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/collaborators/octocat",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 7, "invitee": {"login": "octocat"}, "html_url": "https://github.com/my-org/my-repo/invitations"}`)),
+		},
+	})
+
+	res, err := AccessService.AddCollaborator(context.Background(), "my-org", "my-repo", "octocat", repositories.CollaboratorRequest{})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, repositories.CollaboratorInvited, res.Status)
+	assert.EqualValues(t, "push", res.Permission)
+	assert.EqualValues(t, 7, res.InvitationId)
+}
+
+func TestAccessService_RemoveCollaborator_CancelsInvitations(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/collaborators/octocat",
+		HttpMethod: http.MethodDelete,
+		Response:   &http.Response{StatusCode: http.StatusNoContent, Body: ioutil.NopCloser(strings.NewReader(``))},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/invitations?per_page=100",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 7, "invitee": {"login": "OctoCat"}}, {"id": 8, "invitee": {"login": "hubot"}}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/invitations/7",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Must have admin rights to Repository."}`)),
+		},
+	})
+
+	err := AccessService.RemoveCollaborator(context.Background(), "my-org", "my-repo", "octocat")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "Must have admin rights to Repository.", err.Message())
+}
+
+func TestAccessService_SetTeamPermission(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/teams/platform/repos/my-org/my-repo",
+		HttpMethod: http.MethodPut,
+		Response:   &http.Response{StatusCode: http.StatusNoContent, Body: ioutil.NopCloser(strings.NewReader(``))},
+	})
+
+	err := AccessService.SetTeamPermission(context.Background(), "my-org", "my-repo", "platform", repositories.TeamPermissionRequest{Permission: "maintain"})
+
+	assert.Nil(t, err)
+}
+
+func TestAccessService_RemoveTeam_InvalidTeam(t *testing.T) {
+	err := AccessService.RemoveTeam(context.Background(), "my-org", "my-repo", " ")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Invalid team", err.Message())
+}
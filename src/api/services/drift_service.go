@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/reconciler"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"sync"
+	"time"
+)
+
+type driftService struct {
+	managed managed_store.ManagedRepoStore
+	mutex   sync.RWMutex
+	report  *drift.Report
+}
+
+type driftServiceInterface interface {
+	CheckDrift(ctx context.Context) (*drift.Report, errors.ApiError)
+	GetDrift(ctx context.Context, refresh bool) (*drift.Report, errors.ApiError)
+}
+
+var (
+	DriftService driftServiceInterface
+
+	// managedRepos is shared by the default RepositoryService and MetadataService, which
+	// record the repositories, and the default DriftService, which checks them.
+	managedRepos = managed_store.NewMemoryManagedRepoStore()
+)
+
+func init() {
+	DriftService = NewDriftService(managedRepos)
+}
+
+func NewDriftService(managed managed_store.ManagedRepoStore) driftServiceInterface {
+	return &driftService{managed: managed}
+}
+
+// StartDriftChecks runs DriftService.CheckDrift every interval in the background until
+// the returned function is called.
+func StartDriftChecks(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := DriftService.CheckDrift(ctx); err != nil {
+					log.Error("error when trying to check drift", err)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// CheckDrift compares every managed repository against github, one after the other,
+// and keeps the report for GetDrift.
+func (s *driftService) CheckDrift(ctx context.Context) (*drift.Report, errors.ApiError) {
+	managed, err := s.managed.List()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken := config.GetGithubAccessToken()
+	report := &drift.Report{Repos: make([]drift.RepoDrift, 0, len(managed))}
+	for _, repo := range managed {
+		report.AddRepo(checkManagedRepo(ctx, accessToken, repo))
+	}
+	report.CheckedAt = time.Now().UTC()
+
+	s.mutex.Lock()
+	s.report = report
+	s.mutex.Unlock()
+
+	log.Info("drift check completed", fmt.Sprintf("total:%d", report.Summary.Total), fmt.Sprintf("drifted:%d", report.Summary.Drifted), fmt.Sprintf("failed:%d", report.Summary.Failed))
+	return report, nil
+}
+
+// GetDrift returns the last report, checking right away when asked to or when no check
+// ran yet.
+func (s *driftService) GetDrift(ctx context.Context, refresh bool) (*drift.Report, errors.ApiError) {
+	s.mutex.RLock()
+	report := s.report
+	s.mutex.RUnlock()
+
+	if report == nil || refresh {
+		return s.CheckDrift(ctx)
+	}
+	return report, nil
+}
+
+func checkManagedRepo(ctx context.Context, accessToken string, managed drift.ManagedRepo) drift.RepoDrift {
+	result := drift.RepoDrift{Owner: managed.Owner, Name: managed.Name}
+	live, policy, err := fetchLiveState(ctx, accessToken, managed)
+	result.CheckedAt = time.Now().UTC()
+	if err != nil {
+		log.Error("error when trying to check repository drift", err, fmt.Sprintf("owner:%s", managed.Owner), fmt.Sprintf("name:%s", managed.Name))
+		result.Error = err
+		return result
+	}
+
+	result.Changes = reconciler.DiffManaged(managed, *live, policy)
+	result.Drifted = len(result.Changes) > 0
+	return result
+}
+
+// fetchLiveState reads the watched settings of managed from github, along with the
+// protection policy it is expected to follow, if any.
+func fetchLiveState(ctx context.Context, accessToken string, managed drift.ManagedRepo) (*drift.LiveRepo, *repositories.ProtectionPolicy, errors.ApiError) {
+	repo, err := github_provider.GetRepo(ctx, accessToken, managed.Owner, managed.Name)
+	if err != nil {
+		return nil, nil, newApiErrorFromGithub(err)
+	}
+	live := &drift.LiveRepo{Repository: *toRepository(repo)}
+
+	topics, err := github_provider.GetTopics(ctx, accessToken, managed.Owner, managed.Name)
+	if err != nil {
+		return nil, nil, newApiErrorFromGithub(err)
+	}
+	live.Topics = topics.Names
+
+	if managed.ProtectionPolicy == "" {
+		return live, nil, nil
+	}
+	policy, apiErr := repositories.GetProtectionPolicy(managed.ProtectionPolicy)
+	if apiErr != nil {
+		return nil, nil, apiErr
+	}
+
+	branch := live.Repository.DefaultBranch
+	if branch == "" {
+		branch = defaultBranch
+	}
+	protection, err := github_provider.GetBranchProtection(ctx, accessToken, managed.Owner, managed.Name, branch)
+	if err != nil {
+		return nil, nil, newApiErrorFromGithub(err)
+	}
+	if protection != nil {
+		live.Protection = toProtectionPolicy(*protection)
+	}
+	return live, policy, nil
+}
+
+// toProtectionPolicy is the reverse of toBranchProtectionRequest.
+func toProtectionPolicy(protection github.BranchProtection) *repositories.ProtectionPolicy {
+	policy := &repositories.ProtectionPolicy{
+		EnforceAdmins: protection.EnforceAdmins.Enabled,
+		LinearHistory: protection.RequiredLinearHistory.Enabled,
+	}
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil {
+		policy.RequiredReviews = reviews.RequiredApprovingReviewCount
+		policy.DismissStaleReviews = reviews.DismissStaleReviews
+		policy.RequireCodeOwnerReviews = reviews.RequireCodeOwnerReviews
+	}
+	if checks := protection.RequiredStatusChecks; checks != nil {
+		policy.StrictStatusChecks = checks.Strict
+		policy.StatusChecks = checks.Contexts
+	}
+	return policy
+}
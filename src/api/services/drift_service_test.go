@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func addDriftMocks(protection *http.Response) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/api",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 1, "name": "api", "owner": {"login": "my-org"}, "visibility": "public", "default_branch": "main", "has_issues": true}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/api/topics",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"names": ["go"]}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/api/branches/main/protection",
+		HttpMethod: http.MethodGet,
+		Response:   protection,
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/gone",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+}
+
+func TestDriftService_CheckDrift(t *testing.T) {
+	managed := managed_store.NewMemoryManagedRepoStore()
+	managed.Save(&drift.ManagedRepo{Owner: "my-org", Name: "api", Visibility: "private", DefaultBranch: "main", HasIssues: true, Topics: []string{"go"}, ProtectionPolicy: "reviewed"})
+	managed.Save(&drift.ManagedRepo{Owner: "my-org", Name: "gone", Visibility: "private"})
+	addDriftMocks(&http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Branch not protected"}`)),
+	})
+
+	report, err := NewDriftService(managed).CheckDrift(context.Background())
+
+	assert.Nil(t, err)
+	assert.NotNil(t, report)
+	assert.EqualValues(t, drift.Summary{Total: 2, Drifted: 1, Failed: 1}, report.Summary)
+
+	assert.EqualValues(t, "api", report.Repos[0].Name)
+	assert.True(t, report.Repos[0].Drifted)
+	assert.EqualValues(t, 2, len(report.Repos[0].Changes))
+	assert.EqualValues(t, "visibility", report.Repos[0].Changes[0].Field)
+	assert.EqualValues(t, "branch_protection", report.Repos[0].Changes[1].Field)
+
+	assert.EqualValues(t, "gone", report.Repos[1].Name)
+	assert.EqualValues(t, http.StatusNotFound, report.Repos[1].Error.Status())
+}
+
+func TestDriftService_GetDriftKeepsLastReport(t *testing.T) {
+	managed := managed_store.NewMemoryManagedRepoStore()
+	managed.Save(&drift.ManagedRepo{Owner: "my-org", Name: "api", Visibility: "public", DefaultBranch: "main", HasIssues: true, Topics: []string{"go"}, ProtectionPolicy: "reviewed"})
+	addDriftMocks(&http.Response{
+		StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"required_pull_request_reviews": {"required_approving_review_count": 1, "dismiss_stale_reviews": true},
+			"enforce_admins": {"enabled": false}, "required_linear_history": {"enabled": false}}`)),
+	})
+	service := NewDriftService(managed)
+
+	first, err := service.GetDrift(context.Background(), false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, drift.Summary{Total: 1}, first.Summary)
+
+	restclient.FlushMockups()
+	again, err := service.GetDrift(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, first, again)
+
+	refreshed, err := service.GetDrift(context.Background(), true)
+	assert.Nil(t, err)
+	assert.EqualValues(t, drift.Summary{Total: 1, Failed: 1}, refreshed.Summary)
+}
+
+func TestDriftService_TopicsSetThroughTheApi(t *testing.T) {
+	managed := managed_store.NewMemoryManagedRepoStore()
+	managed.Save(&drift.ManagedRepo{Owner: "my-org", Name: "api", Visibility: "public", DefaultBranch: "main", HasIssues: true, Topics: []string{"rust"}})
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/api/topics", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"names": ["go"]}`))}})
+
+	_, err := NewMetadataService(managed).SetTopics(context.Background(), "my-org", "api", repositories.TopicsRequest{Topics: []string{"go"}})
+	assert.Nil(t, err)
+
+	addDriftMocks(nil)
+	report, err := NewDriftService(managed).CheckDrift(context.Background())
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, drift.Summary{Total: 1}, report.Summary)
+	assert.False(t, report.Repos[0].Drifted)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strconv"
+)
+
+const (
+	hookSecretBytes = 32
+	insecureSslOn   = "1"
+	insecureSslOff  = "0"
+)
+
+type hooksService struct{}
+
+type hooksServiceInterface interface {
+	CreateHook(ctx context.Context, owner string, name string, request repositories.HookRequest) (*repositories.Webhook, errors.ApiError)
+	ListHooks(ctx context.Context, owner string, name string) ([]repositories.Webhook, errors.ApiError)
+	UpdateHook(ctx context.Context, owner string, name string, hookId string, request repositories.UpdateHookRequest) (*repositories.Webhook, errors.ApiError)
+	PingHook(ctx context.Context, owner string, name string, hookId string) errors.ApiError
+	DeleteHook(ctx context.Context, owner string, name string, hookId string) errors.ApiError
+}
+
+var (
+	HooksService hooksServiceInterface
+)
+
+func init() {
+	HooksService = &hooksService{}
+}
+
+func (s *hooksService) CreateHook(ctx context.Context, owner string, name string, input repositories.HookRequest) (*repositories.Webhook, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	if input.GenerateSecret {
+		secret, err := generateHookSecret()
+		if err != nil {
+			return nil, err
+		}
+		input.Secret = secret
+	}
+	active := input.Active == nil || *input.Active
+	request := github.CreateHookRequest{
+		Name:   github.HookName,
+		Active: active,
+		Events: input.Events,
+		Config: github.HookConfig{
+			Url:         input.Url,
+			ContentType: input.ContentType,
+			Secret:      input.Secret,
+			InsecureSsl: toInsecureSsl(input.InsecureSsl),
+		},
+	}
+
+	hook, err := github_provider.CreateHook(ctx, config.GetGithubAccessToken(), owner, name, request)
+	if err != nil {
+		log.Error("error when trying to create webhook", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	result := toWebhook(*hook)
+	if input.GenerateSecret {
+		result.Secret = input.Secret
+	}
+	log.Info("webhook created", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("hook:%d", hook.Id))
+	return &result, nil
+}
+
+func (s *hooksService) ListHooks(ctx context.Context, owner string, name string) ([]repositories.Webhook, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+
+	hooks, err := github_provider.ListHooks(ctx, config.GetGithubAccessToken(), owner, name)
+	if err != nil {
+		log.Error("error when trying to list webhooks", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	result := make([]repositories.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		result = append(result, toWebhook(hook))
+	}
+	return result, nil
+}
+
+// UpdateHook changes the config of the webhook first, then its events and state, and
+// returns the webhook as github has it afterwards.
+func (s *hooksService) UpdateHook(ctx context.Context, owner string, name string, hookId string, input repositories.UpdateHookRequest) (*repositories.Webhook, errors.ApiError) {
+	id, apiErr := parseHookId(owner, name, hookId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	accessToken := config.GetGithubAccessToken()
+	if input.GenerateSecret {
+		secret, err := generateHookSecret()
+		if err != nil {
+			return nil, err
+		}
+		input.Secret = secret
+	}
+	if input.HasConfig() {
+		hookConfig := github.HookConfig{Url: input.Url, ContentType: input.ContentType, Secret: input.Secret}
+		if input.InsecureSsl != nil {
+			hookConfig.InsecureSsl = toInsecureSsl(*input.InsecureSsl)
+		}
+		if _, err := github_provider.UpdateHookConfig(ctx, accessToken, owner, name, id, hookConfig); err != nil {
+			log.Error("error when trying to update webhook config", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("hook:%d", id))
+			return nil, newApiErrorFromGithub(err)
+		}
+	}
+
+	var hook *github.Hook
+	var err *github.GithubErrorResponse
+	if input.Active != nil || len(input.Events) > 0 {
+		hook, err = github_provider.UpdateHook(ctx, accessToken, owner, name, id, github.UpdateHookRequest{Active: input.Active, Events: input.Events})
+	} else {
+		hook, err = github_provider.GetHook(ctx, accessToken, owner, name, id)
+	}
+	if err != nil {
+		log.Error("error when trying to update webhook", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("hook:%d", id))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	result := toWebhook(*hook)
+	if input.GenerateSecret {
+		result.Secret = input.Secret
+	}
+	return &result, nil
+}
+
+func (s *hooksService) PingHook(ctx context.Context, owner string, name string, hookId string) errors.ApiError {
+	id, apiErr := parseHookId(owner, name, hookId)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := github_provider.PingHook(ctx, config.GetGithubAccessToken(), owner, name, id); err != nil {
+		log.Error("error when trying to ping webhook", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("hook:%d", id))
+		return newApiErrorFromGithub(err)
+	}
+	return nil
+}
+
+func (s *hooksService) DeleteHook(ctx context.Context, owner string, name string, hookId string) errors.ApiError {
+	id, apiErr := parseHookId(owner, name, hookId)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := github_provider.DeleteHook(ctx, config.GetGithubAccessToken(), owner, name, id); err != nil {
+		log.Error("error when trying to delete webhook", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("hook:%d", id))
+		return newApiErrorFromGithub(err)
+	}
+	log.Info("webhook deleted", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("hook:%d", id))
+	return nil
+}
+
+// ciHookRequest is the CI webhook configured for new repositories, nil when there is
+// none or when input opts out.
+func ciHookRequest(input repositories.CreateRepoRequest) *github.CreateHookRequest {
+	if config.GetCiWebhookUrl() == "" || (input.CiWebhook != nil && !*input.CiWebhook) {
+		return nil
+	}
+
+	return &github.CreateHookRequest{
+		Name:   github.HookName,
+		Active: true,
+		Events: config.GetCiWebhookEvents(),
+		Config: github.HookConfig{
+			Url:         config.GetCiWebhookUrl(),
+			ContentType: repositories.HookContentJson,
+			Secret:      config.GetCiWebhookSecret(),
+			InsecureSsl: insecureSslOff,
+		},
+	}
+}
+
+func parseHookId(owner string, name string, hookId string) (int64, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseInt(hookId, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.NewBadRequestError("Invalid webhook id")
+	}
+	return id, nil
+}
+
+// generateHookSecret returns a random hex secret, for github to sign the deliveries with.
+func generateHookSecret() (string, errors.ApiError) {
+	secret := make([]byte, hookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		log.Error("error when trying to generate webhook secret", err)
+		return "", errors.NewInternalServerError("error when trying to generate webhook secret")
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func toInsecureSsl(insecure bool) string {
+	if insecure {
+		return insecureSslOn
+	}
+	return insecureSslOff
+}
+
+func toWebhook(hook github.Hook) repositories.Webhook {
+	return repositories.Webhook{
+		Id:           hook.Id,
+		Url:          hook.Config.Url,
+		ContentType:  hook.Config.ContentType,
+		Events:       hook.Events,
+		Active:       hook.Active,
+		InsecureSsl:  hook.Config.InsecureSsl == insecureSslOn,
+		LastResponse: hook.LastResponse.Status,
+		CreatedAt:    hook.CreatedAt,
+		UpdatedAt:    hook.UpdatedAt,
+	}
+}
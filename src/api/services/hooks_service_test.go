@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHooksService_CreateHook_GeneratesSecret(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+		Body: ioutil.NopCloser(strings.NewReader(`{"id": 7, "active": true, "events": ["push"], "config": {"url": "https://ci.example.com/hook", "content_type": "json", "insecure_ssl": "0", "secret": "********"}}`))}})
+
+	res, err := HooksService.CreateHook(context.Background(), "my-org", "my-repo", repositories.HookRequest{Url: "https://ci.example.com/hook", GenerateSecret: true})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 7, res.Id)
+	assert.EqualValues(t, "https://ci.example.com/hook", res.Url)
+	assert.True(t, res.Active)
+	assert.False(t, res.InsecureSsl)
+	assert.EqualValues(t, 64, len(res.Secret))
+}
+
+func TestHooksService_ListHooks_NeverReturnsSecrets(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks?per_page=100", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`[{"id": 7, "active": true, "config": {"url": "https://ci.example.com/hook", "secret": "********"}, "last_response": {"code": 200, "status": "active"}}]`))}})
+
+	res, err := HooksService.ListHooks(context.Background(), "my-org", "my-repo")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []repositories.Webhook{{Id: 7, Url: "https://ci.example.com/hook", Active: true, LastResponse: "active"}}, res)
+}
+
+func TestHooksService_UpdateHook_ConfigOnly(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks/7/config", HttpMethod: http.MethodPatch, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"url": "https://ci.example.com/v2/hook", "content_type": "json"}`))}})
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks/7", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"id": 7, "active": true, "events": ["push"], "config": {"url": "https://ci.example.com/v2/hook", "content_type": "json"}}`))}})
+
+	res, err := HooksService.UpdateHook(context.Background(), "my-org", "my-repo", "7", repositories.UpdateHookRequest{Url: "https://ci.example.com/v2/hook"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, "https://ci.example.com/v2/hook", res.Url)
+	assert.EqualValues(t, "", res.Secret)
+}
+
+func TestHooksService_UpdateHook_SecretOnly(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks/7/config", HttpMethod: http.MethodPatch, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"url": "https://ci.example.com/hook", "content_type": "json"}`))}})
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks/7", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"id": 7, "active": true, "events": ["push"], "config": {"url": "https://ci.example.com/hook", "content_type": "json"}}`))}})
+
+	res, err := HooksService.UpdateHook(context.Background(), "my-org", "my-repo", "7", repositories.UpdateHookRequest{GenerateSecret: true})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, "https://ci.example.com/hook", res.Url)
+	assert.NotEmpty(t, res.Secret)
+}
+
+func TestHooksService_InvalidHookId(t *testing.T) {
+	for _, id := range []string{"", "abc", "0", "-1"} {
+		err := HooksService.DeleteHook(context.Background(), "my-org", "my-repo", id)
+
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "Invalid webhook id", err.Message())
+	}
+}
+
+func TestHooksService_PingHook(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/hooks/7/pings", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusNoContent,
+		Body: ioutil.NopCloser(strings.NewReader(``))}})
+
+	err := HooksService.PingHook(context.Background(), "my-org", "my-repo", "7")
+
+	assert.Nil(t, err)
+}
+
+func TestCiHookRequest_NotConfigured(t *testing.T) {
+	enabled := true
+	assert.Nil(t, ciHookRequest(repositories.CreateRepoRequest{Name: "my-repo", CiWebhook: &enabled}))
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/jobs"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/stores/job_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type jobsService struct {
+	store     job_store.JobStore
+	retention time.Duration
+	mutex     sync.Mutex
+	cancels   map[string]context.CancelFunc
+}
+
+type jobsServiceInterface interface {
+	CreateReposJob(request []repositories.CreateRepoRequest, atomic bool) (*jobs.Job, errors.ApiError)
+	GetJob(id string) (*jobs.Job, errors.ApiError)
+	CancelJob(id string) (*jobs.Job, errors.ApiError)
+}
+
+var (
+	JobsService jobsServiceInterface
+)
+
+func init() {
+	JobsService = NewJobsService(job_store.NewMemoryJobStore(), config.GetJobRetention())
+}
+
+// NewJobsService keeps the finished jobs for retention, zero keeping them for good.
+func NewJobsService(store job_store.JobStore, retention time.Duration) jobsServiceInterface {
+	return &jobsService{
+		store:     store,
+		retention: retention,
+		cancels:   make(map[string]context.CancelFunc),
+	}
+}
+
+func newJobId() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// CreateReposJob starts creating the repositories in the background and returns as
+// soon as the job is registered. The job outlives the http request that started it,
+// the finished jobs older than the retention are forgotten on the way.
+func (s *jobsService) CreateReposJob(request []repositories.CreateRepoRequest, atomic bool) (*jobs.Job, errors.ApiError) {
+	if len(request) == 0 {
+		return nil, errors.NewBadRequestError("No repositories to create")
+	}
+
+	id, err := newJobId()
+	if err != nil {
+		return nil, errors.NewInternalServerError("error when trying to generate job id")
+	}
+
+	now := time.Now().UTC()
+	if s.retention > 0 {
+		if pruned, err := s.store.Prune(now.Add(-s.retention)); err != nil {
+			log.Error("error when trying to prune jobs", err)
+		} else if pruned > 0 {
+			log.Info("finished jobs pruned", fmt.Sprintf("count:%d", pruned))
+		}
+	}
+	job := &jobs.Job{
+		Id:        id,
+		Status:    jobs.StatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Total:     len(request),
+	}
+	if err := s.store.Save(job); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mutex.Lock()
+	s.cancels[id] = cancel
+	s.mutex.Unlock()
+
+	log.Info("batch job started", fmt.Sprintf("job_id:%s", id), fmt.Sprintf("total:%d", len(request)))
+	go s.run(ctx, id, request, atomic)
+
+	return job, nil
+}
+
+func (s *jobsService) run(ctx context.Context, id string, request []repositories.CreateRepoRequest, atomic bool) {
+	res, err := RepositoryService.CreateReposWithOptions(ctx, request, CreateReposOptions{
+		Atomic: atomic,
+		Progress: func(result repositories.CreateReposResult) {
+			s.update(id, func(job *jobs.Job) {
+				job.AddResult(result)
+			})
+		},
+	})
+
+	s.mutex.Lock()
+	delete(s.cancels, id)
+	s.mutex.Unlock()
+
+	s.update(id, func(job *jobs.Job) {
+		job.Status = jobs.StatusCompleted
+		if ctx.Err() != nil {
+			job.Status = jobs.StatusCancelled
+		}
+		if err != nil {
+			job.StatusCode = err.Status()
+			return
+		}
+		job.Results = res.Results
+		job.Processed = len(res.Results)
+		job.Summary = res.Summary
+		job.StatusCode = res.StatusCode
+	})
+	log.Info("batch job finished", fmt.Sprintf("job_id:%s", id), fmt.Sprintf("status:%d", res.StatusCode))
+}
+
+// update applies fn to the stored job; the lock keeps progress and cancellation from
+// overwriting each other.
+func (s *jobsService) update(id string, fn func(job *jobs.Job)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, err := s.store.Get(id)
+	if err != nil {
+		log.Error("error when trying to load job", err, fmt.Sprintf("job_id:%s", id))
+		return
+	}
+	fn(job)
+	job.UpdatedAt = time.Now().UTC()
+	if err := s.store.Save(job); err != nil {
+		log.Error("error when trying to save job", err, fmt.Sprintf("job_id:%s", id))
+	}
+}
+
+func (s *jobsService) GetJob(id string) (*jobs.Job, errors.ApiError) {
+	return s.store.Get(id)
+}
+
+// CancelJob stops the items of the job that did not start yet; the ones already being
+// created are left to finish.
+func (s *jobsService) CancelJob(id string) (*jobs.Job, errors.ApiError) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cancel := s.cancels[id]
+	if job.IsFinished() || cancel == nil {
+		return nil, errors.NewApiError(http.StatusConflict, "job already finished")
+	}
+
+	cancel()
+	job.Status = jobs.StatusCancelling
+	job.UpdatedAt = time.Now().UTC()
+	if err := s.store.Save(job); err != nil {
+		return nil, err
+	}
+
+	log.Info("batch job cancelled", fmt.Sprintf("job_id:%s", id))
+	return job, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/jobs"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/stores/job_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingReposService holds every batch until its context is cancelled.
+type blockingReposService struct {
+	reposService
+}
+
+func (s *blockingReposService) CreateReposWithOptions(ctx context.Context, req []repositories.CreateRepoRequest, options CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError) {
+	<-ctx.Done()
+	return s.reposService.CreateReposWithOptions(ctx, req, options)
+}
+
+func waitForJob(t *testing.T, service jobsServiceInterface, id string) *jobs.Job {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := service.GetJob(id)
+		assert.Nil(t, err)
+		if job.IsFinished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func TestJobsService_CreateReposJob_Empty(t *testing.T) {
+	service := NewJobsService(job_store.NewMemoryJobStore(), 0)
+
+	job, err := service.CreateReposJob(nil, false)
+
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestJobsService_CreateReposJob_Completes(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-github-repo", "owner": { "login": "dmolina79" } }`)),
+		},
+	})
+	service := NewJobsService(job_store.NewMemoryJobStore(), 0)
+
+	job, err := service.CreateReposJob([]repositories.CreateRepoRequest{{}, {Name: "my-github-repo"}}, false)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, job.Id)
+	assert.EqualValues(t, jobs.StatusRunning, job.Status)
+	assert.EqualValues(t, 2, job.Total)
+
+	finished := waitForJob(t, service, job.Id)
+	assert.EqualValues(t, jobs.StatusCompleted, finished.Status)
+	assert.EqualValues(t, http.StatusPartialContent, finished.StatusCode)
+	assert.EqualValues(t, 2, finished.Processed)
+	assert.EqualValues(t, repositories.CreateReposSummary{Total: 2, Succeeded: 1, Failed: 1}, finished.Summary)
+	assert.EqualValues(t, 0, finished.Results[0].Index)
+	assert.NotNil(t, finished.Results[1].Response)
+
+	_, cancelErr := service.CancelJob(job.Id)
+	assert.NotNil(t, cancelErr)
+	assert.EqualValues(t, http.StatusConflict, cancelErr.Status())
+}
+
+func TestJobsService_CancelJob(t *testing.T) {
+	previous := RepositoryService
+	RepositoryService = &blockingReposService{}
+	defer func() { RepositoryService = previous }()
+
+	service := NewJobsService(job_store.NewMemoryJobStore(), 0)
+	job, err := service.CreateReposJob([]repositories.CreateRepoRequest{{Name: "a"}, {Name: "b"}}, false)
+	assert.Nil(t, err)
+
+	cancelled, err := service.CancelJob(job.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, jobs.StatusCancelling, cancelled.Status)
+
+	finished := waitForJob(t, service, job.Id)
+	assert.EqualValues(t, jobs.StatusCancelled, finished.Status)
+	assert.EqualValues(t, 2, finished.Summary.Skipped)
+}
+
+func TestJobsService_GetJob_NotFound(t *testing.T) {
+	service := NewJobsService(job_store.NewMemoryJobStore(), 0)
+
+	job, err := service.GetJob("missing")
+
+	assert.Nil(t, job)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestJobsService_CreateReposJob_PrunesFinishedJobs(t *testing.T) {
+	previous := RepositoryService
+	RepositoryService = &blockingReposService{}
+	defer func() { RepositoryService = previous }()
+
+	store := job_store.NewMemoryJobStore()
+	old := time.Now().UTC().Add(-2 * time.Hour)
+	store.Save(&jobs.Job{Id: "finished", Status: jobs.StatusCompleted, UpdatedAt: old})
+	store.Save(&jobs.Job{Id: "running", Status: jobs.StatusRunning, UpdatedAt: old})
+	service := NewJobsService(store, time.Hour)
+
+	job, err := service.CreateReposJob([]repositories.CreateRepoRequest{{Name: "a"}}, false)
+	assert.Nil(t, err)
+
+	_, err = service.GetJob("finished")
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	_, err = service.GetJob("running")
+	assert.Nil(t, err)
+
+	// the job reads RepositoryService until it finishes
+	_, err = service.CancelJob(job.Id)
+	assert.Nil(t, err)
+	waitForJob(t, service, job.Id)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+	"time"
+)
+
+type metadataService struct {
+	managed managed_store.ManagedRepoStore
+}
+
+type metadataServiceInterface interface {
+	SetTopics(ctx context.Context, owner string, name string, request repositories.TopicsRequest) (*repositories.TopicsResponse, errors.ApiError)
+	SyncLabels(ctx context.Context, owner string, name string, request repositories.LabelsRequest) (*repositories.LabelsResponse, errors.ApiError)
+	CreateMilestones(ctx context.Context, owner string, name string, request repositories.MilestonesRequest) (*repositories.MilestonesResponse, errors.ApiError)
+}
+
+var (
+	MetadataService metadataServiceInterface
+)
+
+func init() {
+	MetadataService = NewMetadataService(managedRepos)
+}
+
+// NewMetadataService records the topics set on the repositories of managed, so drift
+// detection does not report them.
+func NewMetadataService(managed managed_store.ManagedRepoStore) metadataServiceInterface {
+	return &metadataService{managed: managed}
+}
+
+// SetTopics replaces every topic of owner/name, an empty list removes them all.
+func (s *metadataService) SetTopics(ctx context.Context, owner string, name string, input repositories.TopicsRequest) (*repositories.TopicsResponse, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	topics := input.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	res, err := github_provider.ReplaceTopics(ctx, config.GetGithubAccessToken(), owner, name, topics)
+	if err != nil {
+		log.Error("error when trying to set topics", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+	s.updateManagedTopics(owner, name, res.Names)
+
+	return &repositories.TopicsResponse{Topics: res.Names}, nil
+}
+
+// updateManagedTopics records topics as the desired ones of owner/name when this api
+// manages it.
+func (s *metadataService) updateManagedTopics(owner string, name string, topics []string) {
+	if s.managed == nil {
+		return
+	}
+	managed, err := s.managed.Get(owner, name)
+	if err != nil {
+		return
+	}
+
+	managed.Topics = append([]string{}, topics...)
+	managed.RecordedAt = time.Now().UTC()
+	if err := s.managed.Save(managed); err != nil {
+		log.Error("error when trying to record managed repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+	}
+}
+
+// SyncLabels makes the labels of owner/name match the request, deleting the ones not
+// listed. A failing label does not stop the others, each one has its own result.
+func (s *metadataService) SyncLabels(ctx context.Context, owner string, name string, input repositories.LabelsRequest) (*repositories.LabelsResponse, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	results, err := syncLabels(ctx, config.GetGithubAccessToken(), owner, name, input.Labels, true)
+	if err != nil {
+		log.Error("error when trying to list labels", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	return &repositories.LabelsResponse{Results: results}, nil
+}
+
+// CreateMilestones creates each milestone in order, a failing one does not stop the others.
+func (s *metadataService) CreateMilestones(ctx context.Context, owner string, name string, input repositories.MilestonesRequest) (*repositories.MilestonesResponse, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	accessToken := config.GetGithubAccessToken()
+	response := &repositories.MilestonesResponse{Results: make([]repositories.MilestoneResult, 0, len(input.Milestones))}
+	for _, milestone := range input.Milestones {
+		result := repositories.MilestoneResult{Title: milestone.Title}
+		res, err := createMilestone(ctx, accessToken, owner, name, milestone)
+		if err != nil {
+			log.Error("error when trying to create milestone", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+			result.Error = newApiErrorFromGithub(err)
+		} else {
+			result.Applied = true
+			result.Number = res.Number
+			result.HtmlUrl = res.HtmlUrl
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+func createMilestone(ctx context.Context, accessToken string, owner string, name string, milestone repositories.Milestone) (*github.Milestone, *github.GithubErrorResponse) {
+	return github_provider.CreateMilestone(ctx, accessToken, owner, name, github.Milestone{
+		Title:       milestone.Title,
+		State:       milestone.State,
+		Description: milestone.Description,
+		DueOn:       milestone.DueOn,
+	})
+}
+
+// syncLabels creates the desired labels missing from owner/name and updates the ones
+// that differ. When prune is set the labels not desired are deleted. The returned error
+// is only set when the current labels could not be listed.
+func syncLabels(ctx context.Context, accessToken string, owner string, name string, desired []repositories.Label, prune bool) ([]repositories.LabelResult, *github.GithubErrorResponse) {
+	current, err := github_provider.ListLabels(ctx, accessToken, owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]github.Label, len(current))
+	for _, label := range current {
+		existing[strings.ToLower(label.Name)] = label
+	}
+
+	results := make([]repositories.LabelResult, 0, len(desired))
+	wanted := make(map[string]bool, len(desired))
+	for _, label := range desired {
+		key := strings.ToLower(label.Name)
+		wanted[key] = true
+		request := github.Label{Name: label.Name, Color: label.Color, Description: label.Description}
+
+		live, found := existing[key]
+		switch {
+		case !found:
+			_, err := github_provider.CreateLabel(ctx, accessToken, owner, name, request)
+			results = append(results, newLabelResult(label.Name, repositories.LabelCreated, err))
+		case live.Name != label.Name || !strings.EqualFold(live.Color, label.Color) || live.Description != label.Description:
+			_, err := github_provider.UpdateLabel(ctx, accessToken, owner, name, live.Name, request)
+			results = append(results, newLabelResult(label.Name, repositories.LabelUpdated, err))
+		default:
+			results = append(results, newLabelResult(label.Name, repositories.LabelUnchanged, nil))
+		}
+	}
+
+	if prune {
+		for _, label := range current {
+			if !wanted[strings.ToLower(label.Name)] {
+				err := github_provider.DeleteLabel(ctx, accessToken, owner, name, label.Name)
+				results = append(results, newLabelResult(label.Name, repositories.LabelDeleted, err))
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func newLabelResult(label string, action string, err *github.GithubErrorResponse) repositories.LabelResult {
+	result := repositories.LabelResult{Name: label, Action: action, Applied: err == nil}
+	if err != nil {
+		result.Error = newApiErrorFromGithub(err)
+	}
+	return result
+}
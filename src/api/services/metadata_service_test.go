@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetadataService_SetTopics_InvalidTopic(t *testing.T) {
+	res, err := MetadataService.SetTopics(context.Background(), "my-org", "my-repo", repositories.TopicsRequest{Topics: []string{"not valid"}})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestMetadataService_SyncLabels(t *testing.T) {
+	restclient.FlushMockups()
+	for _, mock := range []restclient.Mock{
+		{Url: "https://api.github.com/repos/my-org/my-repo/labels?per_page=100", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`[{"name": "bug", "color": "D73A4A"}, {"name": "Enhancement", "color": "a2eeef"}, {"name": "wontfix", "color": "ffffff"}]`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/labels", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"name": "ops", "color": "000000"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/labels/Enhancement", HttpMethod: http.MethodPatch, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"name": "enhancement", "color": "a2eeef"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/labels/wontfix", HttpMethod: http.MethodDelete, Response: &http.Response{StatusCode: http.StatusForbidden,
+			Body: ioutil.NopCloser(strings.NewReader(`{"message": "Must have admin rights to Repository."}`))}},
+	} {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := MetadataService.SyncLabels(context.Background(), "my-org", "my-repo", repositories.LabelsRequest{Labels: []repositories.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "enhancement", Color: "a2eeef"},
+		{Name: "ops", Color: "000000"},
+	}})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 4, len(res.Results))
+	assert.EqualValues(t, repositories.LabelResult{Name: "bug", Action: repositories.LabelUnchanged, Applied: true}, res.Results[0])
+	assert.EqualValues(t, repositories.LabelResult{Name: "enhancement", Action: repositories.LabelUpdated, Applied: true}, res.Results[1])
+	assert.EqualValues(t, repositories.LabelResult{Name: "ops", Action: repositories.LabelCreated, Applied: true}, res.Results[2])
+	assert.EqualValues(t, repositories.LabelDeleted, res.Results[3].Action)
+	assert.False(t, res.Results[3].Applied)
+	assert.EqualValues(t, http.StatusForbidden, res.Results[3].Error.Status())
+}
+
+func TestMetadataService_SyncLabels_EmptyNeedsPruneAll(t *testing.T) {
+	restclient.FlushMockups()
+
+	res, err := MetadataService.SyncLabels(context.Background(), "my-org", "my-repo", repositories.LabelsRequest{Labels: []repositories.Label{}})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestMetadataService_CreateMilestones(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/my-repo/milestones",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"number": 1, "title": "v1", "html_url": "https://github.com/my-org/my-repo/milestone/1"}`)),
+		},
+	})
+
+	res, err := MetadataService.CreateMilestones(context.Background(), "my-org", "my-repo", repositories.MilestonesRequest{Milestones: []repositories.Milestone{{Title: "v1", DueOn: "2026-12-31"}}})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, []repositories.MilestoneResult{{Title: "v1", Number: 1, HtmlUrl: "https://github.com/my-org/my-repo/milestone/1", Applied: true}}, res.Results)
+}
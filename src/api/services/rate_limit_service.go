@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/ratelimit"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"time"
+)
+
+type rateLimitService struct{}
+
+type rateLimitServiceInterface interface {
+	GetRateLimit(ctx context.Context) (*ratelimit.RateLimitResponse, errors.ApiError)
+}
+
+var (
+	RateLimitService rateLimitServiceInterface
+)
+
+func init() {
+	RateLimitService = &rateLimitService{}
+}
+
+func (s *rateLimitService) GetRateLimit(ctx context.Context) (*ratelimit.RateLimitResponse, errors.ApiError) {
+	accessToken := config.GetGithubAccessToken()
+	res, err := github_provider.GetRateLimit(ctx, accessToken)
+	if err != nil {
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	result := ratelimit.RateLimitResponse{
+		Budget:    toBudget(res.Rate),
+		Resources: make(map[string]ratelimit.Budget, len(res.Resources)),
+	}
+	for name, current := range res.Resources {
+		result.Resources[name] = toBudget(current)
+	}
+	if until := github_provider.GetRateLimitPausedUntil(accessToken); !until.IsZero() {
+		result.PausedUntil = &until
+	}
+
+	return &result, nil
+}
+
+func toBudget(limit github.RateLimit) ratelimit.Budget {
+	return ratelimit.Budget{
+		Limit:     limit.Limit,
+		Remaining: limit.Remaining,
+		Used:      limit.Used,
+		ResetAt:   time.Unix(limit.Reset, 0).UTC(),
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/reconciler"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"strings"
+)
+
+type reconcileService struct{}
+
+type reconcileServiceInterface interface {
+	Reconcile(ctx context.Context, manifest reconcile.Manifest, dryRun bool) (*reconcile.ReconcileResponse, errors.ApiError)
+}
+
+var (
+	ReconcileService reconcileServiceInterface
+)
+
+func init() {
+	ReconcileService = &reconcileService{}
+}
+
+// Reconcile plans the actions bringing the live repositories to the manifest and, unless
+// dryRun is set, applies them one after the other through the RepositoryService.
+func (s *reconcileService) Reconcile(ctx context.Context, manifest reconcile.Manifest, dryRun bool) (*reconcile.ReconcileResponse, errors.ApiError) {
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	live, err := fetchLiveRepos(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &reconcile.ReconcileResponse{DryRun: dryRun, Plan: reconciler.Diff(manifest, live)}
+	log.Info("reconcile plan ready", fmt.Sprintf("org:%s", manifest.Org), fmt.Sprintf("actions:%d", len(response.Plan)), fmt.Sprintf("dry_run:%t", dryRun))
+	if dryRun {
+		return response, nil
+	}
+
+	response.Results = make([]reconcile.ActionResult, 0, len(response.Plan))
+	for _, action := range response.Plan {
+		response.Results = append(response.Results, applyAction(ctx, action))
+	}
+	return response, nil
+}
+
+// fetchLiveRepos lists every repository of the manifest owner. The settings github
+// leaves out of listings are read one by one for the repositories the manifest manages.
+func fetchLiveRepos(ctx context.Context, manifest reconcile.Manifest) ([]repositories.Repository, errors.ApiError) {
+	request := repositories.ListReposRequest{Org: manifest.Org, Type: "owner"}
+	if manifest.Org != "" {
+		request.Type = "all"
+	}
+
+	managed := make(map[string]bool, len(manifest.Repos))
+	for _, desired := range manifest.Repos {
+		managed[strings.ToLower(desired.Name)] = true
+	}
+
+	var live []repositories.Repository
+	var fetchErr errors.ApiError
+	listErr := RepositoryService.ListAllRepos(ctx, request, func(page []repositories.Repository) {
+		for _, repo := range page {
+			if fetchErr == nil && managed[strings.ToLower(repo.Name)] && !repo.Archived {
+				var full *repositories.Repository
+				if full, fetchErr = RepositoryService.GetRepo(ctx, repo.Owner, repo.Name); fetchErr == nil {
+					repo = *full
+				}
+			}
+			live = append(live, repo)
+		}
+	})
+	if listErr != nil {
+		return nil, listErr
+	}
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	return live, nil
+}
+
+func applyAction(ctx context.Context, action reconcile.Action) reconcile.ActionResult {
+	result := reconcile.ActionResult{Type: action.Type, Name: action.Name}
+
+	var err errors.ApiError
+	switch action.Type {
+	case reconcile.ActionCreate:
+		_, err = RepositoryService.CreateRepo(ctx, *action.Create)
+	case reconcile.ActionUpdate:
+		_, err = RepositoryService.UpdateRepo(ctx, action.Owner, action.Name, *action.Update)
+	case reconcile.ActionArchive:
+		_, err = RepositoryService.ArchiveRepo(ctx, action.Owner, action.Name)
+	default:
+		err = errors.NewInternalServerError(fmt.Sprintf("unknown reconcile action %s", action.Type))
+	}
+
+	if err != nil {
+		log.Error("error when trying to apply reconcile action", err, fmt.Sprintf("type:%s", action.Type), fmt.Sprintf("name:%s", action.Name))
+		result.Error = err
+		return result
+	}
+	result.Applied = true
+	return result
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/reconcile"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func addReconcileMocks() {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos?type=all",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 1, "name": "api", "owner": {"login": "my-org"}}, {"id": 2, "name": "unlisted", "owner": {"login": "my-org"}}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/api",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 1, "name": "api", "owner": {"login": "my-org"}, "allow_squash_merge": true}`)),
+		},
+	})
+}
+
+func TestReconcileService_InvalidManifest(t *testing.T) {
+	res, err := ReconcileService.Reconcile(context.Background(), reconcile.Manifest{}, true)
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestReconcileService_DryRun(t *testing.T) {
+	addReconcileMocks()
+	disabled := false
+
+	res, err := ReconcileService.Reconcile(context.Background(), reconcile.Manifest{
+		Org:   "my-org",
+		Repos: []reconcile.DesiredRepo{{Name: "api", AllowSquashMerge: &disabled}, {Name: "web"}},
+	}, true)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, res.DryRun)
+	assert.EqualValues(t, 2, len(res.Plan))
+	assert.EqualValues(t, reconcile.ActionCreate, res.Plan[0].Type)
+	assert.EqualValues(t, reconcile.ActionUpdate, res.Plan[1].Type)
+	assert.EqualValues(t, "allow_squash_merge", res.Plan[1].Changes[0].Field)
+	assert.Nil(t, res.Results)
+}
+
+func TestReconcileService_Apply(t *testing.T) {
+	addReconcileMocks()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/unlisted",
+		HttpMethod: http.MethodPatch,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Must have admin rights to Repository."}`)),
+		},
+	})
+
+	res, err := ReconcileService.Reconcile(context.Background(), reconcile.Manifest{
+		Org:   "my-org",
+		Prune: true,
+		Repos: []reconcile.DesiredRepo{{Name: "api"}},
+	}, false)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 1, len(res.Plan))
+	assert.EqualValues(t, 1, len(res.Results))
+	assert.EqualValues(t, reconcile.ActionArchive, res.Results[0].Type)
+	assert.False(t, res.Results[0].Applied)
+	assert.EqualValues(t, http.StatusForbidden, res.Results[0].Error.Status())
+}
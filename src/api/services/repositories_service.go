@@ -1,22 +1,57 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"github.com/dmolina79/golang-github-api/src/api/config"
 	"github.com/dmolina79/golang-github-api/src/api/domain/github"
 	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
 	"github.com/dmolina79/golang-github-api/src/api/log"
 	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/stores/blueprint_store"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
 	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
-type reposService struct{}
+type reposService struct {
+	blueprints blueprint_store.BlueprintStore
+	managed    managed_store.ManagedRepoStore
+}
+
+const (
+	// defaultBranch is assumed when github does not report the default branch of a new repository.
+	defaultBranch = "main"
+)
+
+var (
+	// forkPollInterval is the pause between two checks of a fork github is still copying.
+	forkPollInterval = 2 * time.Second
+)
+
+// CreateReposOptions tune a batch creation. Progress, when not nil, receives every
+// single result as soon as it is available. Atomic deletes the repositories created by
+// the batch as soon as any of its items does not succeed.
+type CreateReposOptions struct {
+	Atomic   bool
+	Progress func(repositories.CreateReposResult)
+}
 
 type repoServiceInterface interface {
-	CreateRepo(request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
-	CreateRepos(request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError)
+	CreateRepo(ctx context.Context, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
+	CreateRepos(ctx context.Context, request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError)
+	CreateReposWithOptions(ctx context.Context, request []repositories.CreateRepoRequest, options CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError)
+	GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError)
+	UpdateRepo(ctx context.Context, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError)
+	ArchiveRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError)
+	DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError
+	ForkRepo(ctx context.Context, owner string, name string, request repositories.ForkRepoRequest, wait bool) (*repositories.ForkRepoResponse, errors.ApiError)
+	ListRepos(ctx context.Context, request repositories.ListReposRequest) (*repositories.ListReposResponse, errors.ApiError)
+	ListAllRepos(ctx context.Context, request repositories.ListReposRequest, page func([]repositories.Repository)) errors.ApiError
 }
 
 var (
@@ -24,107 +59,522 @@ var (
 )
 
 func init() {
-	RepositoryService = &reposService{}
+	RepositoryService = NewRepositoryService(blueprint_store.NewMemoryBlueprintStore(nil), managedRepos)
 }
 
-func (s *reposService) CreateRepo(input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+// NewRepositoryService records the desired state of the repositories it creates or
+// updates in managed, so drift detection can check them later.
+func NewRepositoryService(blueprints blueprint_store.BlueprintStore, managed managed_store.ManagedRepoStore) repoServiceInterface {
+	return &reposService{blueprints: blueprints, managed: managed}
+}
+
+func (s *reposService) CreateRepo(ctx context.Context, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
 	clientId := "1"
+	input, blueprint, apiErr := s.expandBlueprint(input)
+	if apiErr != nil {
+		return nil, apiErr
+	}
 	if err := input.Validate(); err != nil {
 		return nil, err
 	}
+	if input.CiWebhook != nil && *input.CiWebhook && config.GetCiWebhookUrl() == "" {
+		return nil, errors.NewBadRequestError("No CI webhook is configured")
+	}
+	setup := newRepoSetup(input, blueprint)
+
+	if input.Template != nil {
+		return s.generateRepo(ctx, input, setup)
+	}
 
 	request := github.CreateRepoRequest{
-		Name:        input.Name,
-		Description: input.Description,
-		Private:     false,
+		Name:                input.Name,
+		Description:         input.Description,
+		Homepage:            input.Homepage,
+		Private:             input.Private,
+		Visibility:          input.Visibility,
+		TeamId:              input.TeamId,
+		HasIssues:           input.HasIssues,
+		HasProjects:         input.HasProjects,
+		HasWiki:             input.HasWiki,
+		IsTemplate:          input.IsTemplate,
+		AutoInit:            input.AutoInit != nil && *input.AutoInit,
+		GitignoreTemplate:   input.GitignoreTemplate,
+		LicenseTemplate:     input.LicenseTemplate,
+		AllowSquashMerge:    input.AllowSquashMerge,
+		AllowMergeCommit:    input.AllowMergeCommit,
+		AllowRebaseMerge:    input.AllowRebaseMerge,
+		DeleteBranchOnMerge: input.DeleteBranchOnMerge,
 	}
 
 	log.Info("sending request to external api", fmt.Sprintf("client_id:%s", clientId), "status:pending")
-	res, err := github_provider.CreateRepo(config.GetGithubAccessToken(), request)
+	var res *github.CreateRepoResponse
+	var err *github.GithubErrorResponse
+	if input.Org != "" {
+		res, err = github_provider.CreateOrgRepo(ctx, config.GetGithubAccessToken(), input.Org, request)
+	} else {
+		res, err = github_provider.CreateRepo(ctx, config.GetGithubAccessToken(), request)
+	}
 
 	if err != nil {
 		log.Error("sending request to external api", err, fmt.Sprintf("client_id:%s", clientId), "status:error")
-		return nil, errors.NewApiError(err.StatusCode, err.Message)
+		return nil, newApiErrorFromGithub(err)
 	}
 
 	log.Info("response obtained from external api", fmt.Sprintf("client_id:%s", clientId), "status:success")
-	result := repositories.CreateRepoResponse{
-		Id:    res.Id,
-		Owner: res.Owner.Login,
-		Name:  res.Name,
+	return s.setupRepo(ctx, res, setup), nil
+}
+
+// generateRepo creates the repository out of input.Template, once github confirms the
+// source is actually marked as a template.
+func (s *reposService) generateRepo(ctx context.Context, input repositories.CreateRepoRequest, setup repoSetup) (*repositories.CreateRepoResponse, errors.ApiError) {
+	template := input.Template
+	accessToken := config.GetGithubAccessToken()
+
+	source, err := github_provider.GetRepo(ctx, accessToken, template.Owner, template.Name)
+	if err != nil {
+		log.Error("error when trying to get template repository", err, fmt.Sprintf("owner:%s", template.Owner), fmt.Sprintf("name:%s", template.Name))
+		if err.StatusCode == http.StatusNotFound {
+			return nil, errors.NewNotFoundError(fmt.Sprintf("Template repository %s/%s not found", template.Owner, template.Name))
+		}
+		return nil, newApiErrorFromGithub(err)
+	}
+	if !source.IsTemplate {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Repository %s/%s is not a template", template.Owner, template.Name))
+	}
+
+	request := github.GenerateRepoRequest{
+		Owner:              input.Org,
+		Name:               input.Name,
+		Description:        input.Description,
+		IncludeAllBranches: template.IncludeAllBranches,
+		Private:            input.Private,
+	}
+
+	res, err := github_provider.GenerateRepo(ctx, accessToken, template.Owner, template.Name, request)
+	if err != nil {
+		log.Error("error when trying to generate repository", err, fmt.Sprintf("owner:%s", template.Owner), fmt.Sprintf("name:%s", template.Name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	log.Info("repository generated from template", fmt.Sprintf("owner:%s", template.Owner), fmt.Sprintf("name:%s", template.Name))
+	return s.setupRepo(ctx, res, setup), nil
+}
+
+func (s *reposService) GetRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+
+	res, err := github_provider.GetRepo(ctx, config.GetGithubAccessToken(), owner, name)
+	if err != nil {
+		log.Error("error when trying to get repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	return toRepository(res), nil
+}
+
+func (s *reposService) UpdateRepo(ctx context.Context, owner string, name string, input repositories.UpdateRepoRequest) (*repositories.Repository, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	request := github.UpdateRepoRequest{
+		Name:                input.Name,
+		Description:         input.Description,
+		Homepage:            input.Homepage,
+		Private:             input.Private,
+		Visibility:          input.Visibility,
+		DefaultBranch:       input.DefaultBranch,
+		HasIssues:           input.HasIssues,
+		HasProjects:         input.HasProjects,
+		HasWiki:             input.HasWiki,
+		IsTemplate:          input.IsTemplate,
+		AllowSquashMerge:    input.AllowSquashMerge,
+		AllowMergeCommit:    input.AllowMergeCommit,
+		AllowRebaseMerge:    input.AllowRebaseMerge,
+		DeleteBranchOnMerge: input.DeleteBranchOnMerge,
+	}
+
+	res, err := github_provider.UpdateRepo(ctx, config.GetGithubAccessToken(), owner, name, request)
+	if err != nil {
+		log.Error("error when trying to update repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	s.updateManaged(owner, name, input)
+	return toRepository(res), nil
+}
+
+func (s *reposService) ArchiveRepo(ctx context.Context, owner string, name string) (*repositories.Repository, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+
+	res, err := github_provider.ArchiveRepo(ctx, config.GetGithubAccessToken(), owner, name)
+	if err != nil {
+		log.Error("error when trying to archive repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	s.forgetManaged(owner, name)
+
+	return toRepository(res), nil
+}
+
+func (s *reposService) DeleteRepo(ctx context.Context, owner string, name string) errors.ApiError {
+	if err := validateRepoPath(owner, name); err != nil {
+		return err
+	}
+
+	if err := github_provider.DeleteRepo(ctx, config.GetGithubAccessToken(), owner, name); err != nil {
+		log.Error("error when trying to delete repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return newApiErrorFromGithub(err)
+	}
+
+	s.forgetManaged(owner, name)
+	log.Info("repository deleted", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+	return nil
+}
+
+// ForkRepo forks owner/name. When wait is set it polls github, up to the configured
+// fork wait timeout, until the fork holds the copied content.
+func (s *reposService) ForkRepo(ctx context.Context, owner string, name string, input repositories.ForkRepoRequest, wait bool) (*repositories.ForkRepoResponse, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	accessToken := config.GetGithubAccessToken()
+	request := github.ForkRepoRequest{
+		Organization:      input.Org,
+		Name:              input.Name,
+		DefaultBranchOnly: input.DefaultBranchOnly,
+	}
+
+	res, err := github_provider.ForkRepo(ctx, accessToken, owner, name, request)
+	if err != nil {
+		log.Error("error when trying to fork repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	response := &repositories.ForkRepoResponse{Repository: *toRepository(res)}
+	if !wait {
+		return response, nil
+	}
+
+	ready, apiErr := waitForFork(ctx, accessToken, response.Owner, response.Name)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	response.Ready = ready
+	return response, nil
+}
+
+// waitForFork returns false, without an error, when the fork is still not ready once the
+// wait timeout expires: the fork exists and github will eventually finish it.
+func waitForFork(ctx context.Context, accessToken string, owner string, name string) (bool, errors.ApiError) {
+	ctx, cancel := context.WithTimeout(ctx, config.GetForkWaitTimeout())
+	defer cancel()
+
+	for {
+		ready, err := github_provider.RepoHasCommits(ctx, accessToken, owner, name)
+		if err != nil && ctx.Err() == nil {
+			log.Error("error when trying to check fork", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+			return false, newApiErrorFromGithub(err)
+		}
+		if ready {
+			return true, nil
+		}
+
+		timer := time.NewTimer(forkPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info("fork not ready before wait timeout", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+			return false, nil
+		case <-timer.C:
+		}
 	}
+}
 
-	return &result, nil
+func (s *reposService) ListRepos(ctx context.Context, input repositories.ListReposRequest) (*repositories.ListReposResponse, errors.ApiError) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := github_provider.ListRepos(ctx, config.GetGithubAccessToken(), input.Org, toListReposRequest(input))
+	if err != nil {
+		log.Error("error when trying to list repositories", err, fmt.Sprintf("org:%s", input.Org))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	return &repositories.ListReposResponse{
+		Repos:      toRepositories(res.Repos),
+		NextCursor: repositories.NewCursor(res.NextUrl),
+	}, nil
 }
 
-func (s *reposService) CreateRepos(req []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError) {
+// ListAllRepos follows every github page starting at the one selected by input, handing
+// each of them to page as soon as it arrives so they are never held together in memory.
+func (s *reposService) ListAllRepos(ctx context.Context, input repositories.ListReposRequest, page func([]repositories.Repository)) errors.ApiError {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if input.Org != "" {
+		// the next pages link to organizations/{id}, naming no owner
+		ctx = github_provider.WithOwner(ctx, input.Org)
+	}
+	accessToken := config.GetGithubAccessToken()
+	res, err := github_provider.ListRepos(ctx, accessToken, input.Org, toListReposRequest(input))
+	for pages := 1; ; pages++ {
+		if err != nil {
+			log.Error("error when trying to list repositories", err, fmt.Sprintf("org:%s", input.Org), fmt.Sprintf("pages:%d", pages))
+			return newApiErrorFromGithub(err)
+		}
+
+		page(toRepositories(res.Repos))
+		if res.NextUrl == "" {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return errors.NewApiError(http.StatusRequestTimeout, "repository listing cancelled")
+		}
+
+		res, err = github_provider.ListReposPage(ctx, accessToken, res.NextUrl)
+	}
+}
+
+func toListReposRequest(input repositories.ListReposRequest) github.ListReposRequest {
+	return github.ListReposRequest{
+		Type:       input.Type,
+		Visibility: input.Visibility,
+		Sort:       input.Sort,
+		Direction:  input.Direction,
+		PerPage:    input.PerPage,
+		Page:       input.Page,
+	}
+}
+
+func toRepositories(res []github.Repository) []repositories.Repository {
+	result := make([]repositories.Repository, 0, len(res))
+	for i := range res {
+		result = append(result, *toRepository(&res[i]))
+	}
+	return result
+}
+
+func validateRepoPath(owner string, name string) errors.ApiError {
+	if err := repositories.ValidateOwner(owner); err != nil {
+		return err
+	}
+
+	return repositories.ValidateRepoName(name)
+}
+
+func toRepository(res *github.Repository) *repositories.Repository {
+	return &repositories.Repository{
+		Id:                  res.Id,
+		Owner:               res.Owner.Login,
+		Name:                res.Name,
+		Description:         res.Description,
+		Homepage:            res.Homepage,
+		HtmlUrl:             res.HtmlUrl,
+		DefaultBranch:       res.DefaultBranch,
+		Private:             res.Private,
+		Visibility:          res.Visibility,
+		HasIssues:           res.HasIssues,
+		HasProjects:         res.HasProjects,
+		HasWiki:             res.HasWiki,
+		IsTemplate:          res.IsTemplate,
+		AllowSquashMerge:    res.AllowSquashMerge,
+		AllowMergeCommit:    res.AllowMergeCommit,
+		AllowRebaseMerge:    res.AllowRebaseMerge,
+		DeleteBranchOnMerge: res.DeleteBranchOnMerge,
+		Archived:            res.Archived,
+	}
+}
+
+func (s *reposService) CreateRepos(ctx context.Context, req []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError) {
+	return s.CreateReposWithOptions(ctx, req, CreateReposOptions{})
+}
+
+func (s *reposService) CreateReposWithOptions(ctx context.Context, req []repositories.CreateRepoRequest, options CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError) {
+	if len(req) == 0 {
+		return repositories.CreateReposResponse{}, errors.NewBadRequestError("No repositories to create")
+	}
+
+	// cancelling ctx only stops dispatching the remaining items, the ones already sent
+	// are left to finish: github may still create a repository whose call got cancelled,
+	// and it would be reported failed while it exists
+	callCtx := context.WithoutCancel(ctx)
+	progress := options.Progress
+	if options.Atomic {
+		// no point in creating more repositories once one failed, they would be rolled back
+		var abort context.CancelFunc
+		ctx, abort = context.WithCancel(ctx)
+		defer abort()
+		progress = func(result repositories.CreateReposResult) {
+			if result.Error != nil {
+				abort()
+			}
+			if options.Progress != nil {
+				options.Progress(result)
+			}
+		}
+	}
+
 	input := make(chan repositories.CreateReposResult)
 	output := make(chan repositories.CreateReposResponse)
 	defer close(output)
 
 	var wg sync.WaitGroup
-	go s.handleRepoResults(&wg, input, output)
+	go s.handleRepoResults(&wg, input, output, progress)
 
-	for _, current := range req {
+	// a bounded pool of workers keeps big batches from tripping github abuse detection
+	jobs := make(chan createRepoJob)
+	workers := config.GetBatchMaxConcurrency()
+	if workers <= 0 || workers > len(req) {
+		workers = len(req)
+	}
+	for i := 0; i < workers; i++ {
+		go s.createRepoWorker(ctx, callCtx, config.GetBatchItemDelay(), jobs, input)
+	}
+
+	for index, current := range req {
 		wg.Add(1)
-		go s.createRepoConcurrent(current, input)
+		jobs <- createRepoJob{index: index, request: current}
 	}
+	close(jobs)
 
 	// wait until all routines are done
 	wg.Wait()
 	close(input)
 
 	result := <-output
-
-	successCreations := 0
-	for _, current := range result.Results {
-		if current.Response != nil {
-			successCreations++
-		}
+	if options.Atomic {
+		s.rollback(&result)
 	}
-
-	switch successCreations {
-	case len(req):
-		result.StatusCode = http.StatusCreated
-	case 0:
-		result.StatusCode = result.Results[0].Error.Status()
-	default:
-		result.StatusCode = http.StatusPartialContent
-	}
-	fmt.Println(fmt.Sprintf("successCreations %v", successCreations))
+	result.Summarize()
+	log.Info("batch creation completed", fmt.Sprintf("requested:%d", len(req)), fmt.Sprintf("created:%d", result.Summary.Succeeded), fmt.Sprintf("failed:%d", result.Summary.Failed), fmt.Sprintf("skipped:%d", result.Summary.Skipped))
 
 	return result, nil
 }
 
-func (s *reposService) handleRepoResults(wg *sync.WaitGroup, input chan repositories.CreateReposResult, out chan repositories.CreateReposResponse) {
+func (s *reposService) handleRepoResults(wg *sync.WaitGroup, input chan repositories.CreateReposResult, out chan repositories.CreateReposResponse, progress func(repositories.CreateReposResult)) {
 	var results repositories.CreateReposResponse
 
 	for incomingRes := range input {
-		repoResult := repositories.CreateReposResult{
-			Response: incomingRes.Response,
-			Error:    incomingRes.Error,
+		results.Results = append(results.Results, incomingRes)
+		if progress != nil {
+			progress(incomingRes)
 		}
-		results.Results = append(results.Results, repoResult)
 
 		wg.Done()
 	}
 
+	// results arrive in completion order, clients expect them in input order
+	sort.Slice(results.Results, func(i, j int) bool {
+		return results.Results[i].Index < results.Results[j].Index
+	})
 	out <- results
 }
 
-func (s *reposService) createRepoConcurrent(input repositories.CreateRepoRequest, out chan repositories.CreateReposResult) {
+// rollback deletes every repository created by an atomic batch unless all of them
+// were, recording the outcome on each result.
+func (s *reposService) rollback(result *repositories.CreateReposResponse) {
+	created := 0
+	for _, current := range result.Results {
+		if current.Response != nil {
+			created++
+		}
+	}
+	if created == len(result.Results) || created == 0 {
+		return
+	}
+
+	// the batch context may be the one that got cancelled, compensation must still run
+	ctx := context.Background()
+	for i := range result.Results {
+		current := &result.Results[i]
+		if current.Response == nil {
+			continue
+		}
+
+		current.Compensation = &repositories.CompensationResult{}
+		err := github_provider.DeleteRepo(ctx, config.GetGithubAccessToken(), current.Response.Owner, current.Response.Name)
+		if err != nil {
+			log.Error("error when trying to roll back repository", err, fmt.Sprintf("index:%d", current.Index), "status:error")
+			current.Compensation.Error = newApiErrorFromGithub(err)
+			continue
+		}
+		current.Compensation.RolledBack = true
+	}
+	log.Info("atomic batch rolled back", fmt.Sprintf("created:%d", created))
+}
+
+type createRepoJob struct {
+	index   int
+	request repositories.CreateRepoRequest
+}
+
+// createRepoWorker creates the repositories it receives one at a time, waiting delay
+// between two of them. Once ctx is done the remaining ones are skipped, the calls
+// being made with callCtx.
+func (s *reposService) createRepoWorker(ctx context.Context, callCtx context.Context, delay time.Duration, jobs chan createRepoJob, out chan repositories.CreateReposResult) {
+	for current := range jobs {
+		s.createRepoConcurrent(ctx, callCtx, current.index, current.request, out)
+
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+		}
+	}
+}
+
+func (s *reposService) createRepoConcurrent(ctx context.Context, callCtx context.Context, index int, input repositories.CreateRepoRequest, out chan repositories.CreateReposResult) {
+	result := repositories.CreateReposResult{
+		Index: index,
+		Name:  strings.TrimSpace(input.Name),
+	}
+
+	if ctx.Err() != nil {
+		result.Skipped = true
+		result.Error = errors.NewApiError(http.StatusRequestTimeout, "repository creation skipped, batch was cancelled")
+		out <- result
+		return
+	}
+
 	if err := input.Validate(); err != nil {
-		out <- repositories.CreateReposResult{Error: err}
+		result.Error = err
+		out <- result
 		return
 	}
 
-	res, err := s.CreateRepo(input)
+	res, err := s.CreateRepo(callCtx, input)
 
 	if err != nil {
-		out <- repositories.CreateReposResult{Error: err}
+		result.Error = err
+		out <- result
 		return
 	}
 
-	out <- repositories.CreateReposResult{Response: res}
+	result.Response = res
+	out <- result
+}
+
+func newApiErrorFromGithub(err *github.GithubErrorResponse) errors.ApiError {
+	if err.StatusCode == http.StatusTooManyRequests {
+		return errors.NewTooManyRequestsError(err.Message, err.RateLimitReset)
+	}
 
+	return errors.NewApiError(err.StatusCode, err.Message)
 }
@@ -1,16 +1,22 @@
 package services
 
 import (
+	"context"
 	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/config"
 	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/stores/blueprint_store"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
 	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -21,7 +27,7 @@ func TestMain(m *testing.M) {
 func TestReposService_CreateRepo_InvalidInputName(t *testing.T) {
 	req := repositories.CreateRepoRequest{}
 
-	res, err := RepositoryService.CreateRepo(req)
+	res, err := RepositoryService.CreateRepo(context.Background(), req)
 
 	assert.Nil(t, res)
 	assert.NotNil(t, err)
@@ -48,7 +54,7 @@ func TestReposService_CreateRepo_HandleErrorFromGH(t *testing.T) {
 	}
 
 	// execute
-	res, err := RepositoryService.CreateRepo(req)
+	res, err := RepositoryService.CreateRepo(context.Background(), req)
 
 	assert.Nil(t, res)
 	assert.NotNil(t, err)
@@ -75,7 +81,7 @@ func TestReposService_CreateRepo_GoGood(t *testing.T) {
 	}
 
 	// execute
-	res, err := RepositoryService.CreateRepo(req)
+	res, err := RepositoryService.CreateRepo(context.Background(), req)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -84,12 +90,101 @@ func TestReposService_CreateRepo_GoGood(t *testing.T) {
 	assert.EqualValues(t, "dmolina79", res.Owner)
 }
 
+func TestReposService_CreateRepo_EchoesSettings(t *testing.T) {
+	// setup
+	restclient.FlushMockups()
+	enabled := true
+
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "github-repo", "private": true, "visibility": "private", "has_issues": true, "allow_squash_merge": true, "delete_branch_on_merge": true, "owner": { "login": "dmolina79" } }`)),
+		},
+	})
+
+	req := repositories.CreateRepoRequest{
+		Name:       "github-repo",
+		Visibility: "private",
+		HasIssues:  &enabled,
+	}
+
+	// execute
+	res, err := RepositoryService.CreateRepo(context.Background(), req)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, res.Private)
+	assert.EqualValues(t, "private", res.Visibility)
+	assert.True(t, res.HasIssues)
+	assert.True(t, res.AllowSquashMerge)
+	assert.False(t, res.AllowMergeCommit)
+	assert.True(t, res.DeleteBranchOnMerge)
+}
+
+func TestReposService_CreateRepo_InOrg(t *testing.T) {
+	// setup
+	restclient.FlushMockups()
+
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "github-repo", "owner": { "login": "my-org" } }`)),
+		},
+	})
+
+	req := repositories.CreateRepoRequest{
+		Org:  "my-org",
+		Name: "github-repo",
+	}
+
+	// execute
+	res, err := RepositoryService.CreateRepo(context.Background(), req)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, "my-org", res.Owner)
+}
+
+func TestReposService_CreateRepo_RateLimited(t *testing.T) {
+	// setup
+	restclient.FlushMockups()
+
+	headers := http.Header{}
+	headers.Set("X-RateLimit-Remaining", "0")
+	headers.Set("X-RateLimit-Reset", "946684800")
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     headers,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"API rate limit exceeded"}`)),
+		},
+	})
+
+	req := repositories.CreateRepoRequest{
+		Name: "github-repo",
+	}
+
+	// execute, the reset is already in the past so other tests are not paused
+	res, err := RepositoryService.CreateRepo(context.Background(), req)
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, "API rate limit exceeded, resets at 2000-01-01T00:00:00Z", err.Message())
+}
+
 func TestReposService_CreateRepoConcurrent_InvalidRequest(t *testing.T) {
 	request := repositories.CreateRepoRequest{}
 	output := make(chan repositories.CreateReposResult)
 	service := reposService{}
 
-	go service.createRepoConcurrent(request, output)
+	go service.createRepoConcurrent(context.Background(), context.Background(), 0, request, output)
 
 	result := <-output
 	assert.NotNil(t, result)
@@ -115,7 +210,7 @@ func TestReposService_CreateRepoConcurrent_ErrorFromGH(t *testing.T) {
 	output := make(chan repositories.CreateReposResult)
 	service := reposService{}
 
-	go service.createRepoConcurrent(request, output)
+	go service.createRepoConcurrent(context.Background(), context.Background(), 0, request, output)
 
 	result := <-output
 	assert.NotNil(t, result)
@@ -141,7 +236,7 @@ func TestReposService_CreateRepoConcurrent_GoGood(t *testing.T) {
 	output := make(chan repositories.CreateReposResult)
 	service := reposService{}
 
-	go service.createRepoConcurrent(request, output)
+	go service.createRepoConcurrent(context.Background(), context.Background(), 0, request, output)
 
 	result := <-output
 	assert.NotNil(t, result)
@@ -158,7 +253,10 @@ func TestReposService_HandleRepoResults(t *testing.T) {
 	var wg sync.WaitGroup
 
 	service := reposService{}
-	go service.handleRepoResults(&wg, input, output)
+	var progress []repositories.CreateReposResult
+	go service.handleRepoResults(&wg, input, output, func(result repositories.CreateReposResult) {
+		progress = append(progress, result)
+	})
 
 	wg.Add(1)
 	go func() {
@@ -177,6 +275,46 @@ func TestReposService_HandleRepoResults(t *testing.T) {
 	assert.NotNil(t, result.Results[0].Error)
 	assert.EqualValues(t, http.StatusBadRequest, result.Results[0].Error.Status())
 	assert.EqualValues(t, "invalid repository name", result.Results[0].Error.Message())
+	assert.EqualValues(t, 1, len(progress))
+}
+
+func TestReposService_CreateRepoWorker(t *testing.T) {
+	jobs := make(chan createRepoJob)
+	output := make(chan repositories.CreateReposResult)
+	service := reposService{}
+
+	go service.createRepoWorker(context.Background(), context.Background(), time.Millisecond, jobs, output)
+
+	go func() {
+		jobs <- createRepoJob{index: 0, request: repositories.CreateRepoRequest{}}
+		jobs <- createRepoJob{index: 1, request: repositories.CreateRepoRequest{Name: " "}}
+		close(jobs)
+	}()
+
+	for i := 0; i < 2; i++ {
+		result := <-output
+		assert.Nil(t, result.Response)
+		assert.EqualValues(t, "Invalid repository name", result.Error.Message())
+	}
+}
+
+func TestReposService_CreateRepos_EmptyBatch(t *testing.T) {
+	res, err := RepositoryService.CreateRepos(context.Background(), []repositories.CreateRepoRequest{})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "No repositories to create", err.Message())
+	assert.EqualValues(t, 0, len(res.Results))
+}
+
+func TestReposService_CreateRepos_MoreRequestsThanWorkers(t *testing.T) {
+	badRequests := make([]repositories.CreateRepoRequest, config.GetBatchMaxConcurrency()*3)
+
+	res, err := RepositoryService.CreateRepos(context.Background(), badRequests)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, len(badRequests), len(res.Results))
+	assert.EqualValues(t, http.StatusBadRequest, res.StatusCode)
 }
 
 func TestReposService_CreateRepos_InvalidRequests(t *testing.T) {
@@ -185,7 +323,7 @@ func TestReposService_CreateRepos_InvalidRequests(t *testing.T) {
 		{Name: "  "},
 	}
 
-	res, err := RepositoryService.CreateRepos(badRequests)
+	res, err := RepositoryService.CreateRepos(context.Background(), badRequests)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -218,7 +356,7 @@ func TestReposService_CreateRepos_PartialSuccess(t *testing.T) {
 		{Name: "my-github-repo"},
 	}
 
-	res, err := RepositoryService.CreateRepos(requests)
+	res, err := RepositoryService.CreateRepos(context.Background(), requests)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -238,6 +376,170 @@ func TestReposService_CreateRepos_PartialSuccess(t *testing.T) {
 	}
 }
 
+func TestReposService_CreateRepos_KeepsInputOrder(t *testing.T) {
+	// setup
+	restclient.FlushMockups()
+
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-github-repo", "owner": { "login": "dmolina79" } }`)),
+		},
+	})
+	requests := []repositories.CreateRepoRequest{
+		{Name: "my-github-repo"},
+		{Name: "bad name"},
+		{},
+		{Name: " other bad name "},
+	}
+
+	res, err := RepositoryService.CreateRepos(context.Background(), requests)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, res.StatusCode)
+	assert.EqualValues(t, repositories.CreateReposSummary{Total: 4, Succeeded: 1, Failed: 3}, res.Summary)
+	for index, result := range res.Results {
+		assert.EqualValues(t, index, result.Index)
+	}
+	assert.NotNil(t, res.Results[0].Response)
+	assert.EqualValues(t, "bad name", res.Results[1].Name)
+	assert.EqualValues(t, "", res.Results[2].Name)
+	assert.EqualValues(t, "other bad name", res.Results[3].Name)
+}
+
+func TestReposService_CreateRepos_CancelledSkipsItems(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err := RepositoryService.CreateRepos(ctx, []repositories.CreateRepoRequest{{Name: "my-github-repo"}})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.CreateReposSummary{Total: 1, Skipped: 1}, res.Summary)
+	assert.True(t, res.Results[0].Skipped)
+	assert.EqualValues(t, http.StatusRequestTimeout, res.StatusCode)
+}
+
+// cancellingBody cancels the batch as soon as the response of the create call is read.
+type cancellingBody struct {
+	io.Reader
+	cancel context.CancelFunc
+}
+
+func (b *cancellingBody) Read(p []byte) (int, error) {
+	b.cancel()
+	return b.Reader.Read(p)
+}
+
+func (b *cancellingBody) Close() error {
+	return nil
+}
+
+func TestReposService_CreateRepos_CancelledWhileCreating(t *testing.T) {
+	restclient.FlushMockups()
+	ctx, cancel := context.WithCancel(context.Background())
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/orgs/my-org/repos", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+		Body: &cancellingBody{Reader: strings.NewReader(`{"id": 123, "name": "my-service", "owner": {"login": "my-org"}}`), cancel: cancel}}})
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-service/topics", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"names": ["go"]}`))}})
+
+	res, err := RepositoryService.CreateRepos(ctx, []repositories.CreateRepoRequest{{Org: "my-org", Name: "my-service", Topics: []string{"go"}}})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, ctx.Err())
+	assert.EqualValues(t, repositories.CreateReposSummary{Total: 1, Succeeded: 1}, res.Summary)
+	assert.NotNil(t, res.Results[0].Response)
+	assert.EqualValues(t, []repositories.StepResult{{Step: repositories.StepTopics, Applied: true}}, res.Results[0].Response.Steps)
+}
+
+func TestReposService_Rollback(t *testing.T) {
+	// setup
+	restclient.FlushMockups()
+
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/repo-one",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+			Body:       ioutil.NopCloser(strings.NewReader(``)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/repo-two",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Must have admin rights to Repository."}`)),
+		},
+	})
+	result := repositories.CreateReposResponse{Results: []repositories.CreateReposResult{
+		{Index: 0, Response: &repositories.CreateRepoResponse{Repository: repositories.Repository{Owner: "dmolina79", Name: "repo-one"}}},
+		{Index: 1, Response: &repositories.CreateRepoResponse{Repository: repositories.Repository{Owner: "dmolina79", Name: "repo-two"}}},
+		{Index: 2, Error: errors.NewBadRequestError("Invalid repository name")},
+	}}
+	service := reposService{}
+
+	service.rollback(&result)
+	result.Summarize()
+
+	assert.True(t, result.Results[0].Compensation.RolledBack)
+	assert.Nil(t, result.Results[0].Compensation.Error)
+	assert.False(t, result.Results[1].Compensation.RolledBack)
+	assert.EqualValues(t, http.StatusForbidden, result.Results[1].Compensation.Error.Status())
+	assert.Nil(t, result.Results[2].Compensation)
+	assert.EqualValues(t, repositories.CreateReposSummary{Total: 3, Failed: 1, RolledBack: 1, RollbackFailed: 1}, result.Summary)
+	assert.EqualValues(t, http.StatusForbidden, result.StatusCode)
+}
+
+func TestReposService_Rollback_DeleteFails(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/repo-one",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"Server Error"}`)),
+		},
+	})
+	result := repositories.CreateReposResponse{Results: []repositories.CreateReposResult{
+		{Index: 0, Response: &repositories.CreateRepoResponse{Repository: repositories.Repository{Owner: "dmolina79", Name: "repo-one"}}},
+		{Index: 1, Error: errors.NewBadRequestError("Invalid repository name")},
+	}}
+	service := reposService{}
+
+	service.rollback(&result)
+	result.Summarize()
+
+	assert.False(t, result.Results[0].Compensation.RolledBack)
+	assert.EqualValues(t, http.StatusInternalServerError, result.Results[0].Compensation.Error.Status())
+	assert.EqualValues(t, repositories.CreateReposSummary{Total: 2, Failed: 1, RollbackFailed: 1}, result.Summary)
+	assert.EqualValues(t, http.StatusInternalServerError, result.StatusCode)
+}
+
+func TestReposService_Rollback_NothingToUndo(t *testing.T) {
+	restclient.FlushMockups()
+	result := repositories.CreateReposResponse{Results: []repositories.CreateReposResult{
+		{Index: 0, Response: &repositories.CreateRepoResponse{Repository: repositories.Repository{Owner: "dmolina79", Name: "repo-one"}}},
+	}}
+	service := reposService{}
+
+	service.rollback(&result)
+
+	assert.Nil(t, result.Results[0].Compensation)
+}
+
+func TestReposService_CreateRepos_AtomicAllFailed(t *testing.T) {
+	requests := []repositories.CreateRepoRequest{{}, {Name: "bad name"}}
+
+	res, err := RepositoryService.CreateReposWithOptions(context.Background(), requests, CreateReposOptions{Atomic: true})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, res.StatusCode)
+	assert.EqualValues(t, 0, res.Summary.Succeeded)
+	assert.EqualValues(t, 0, res.Summary.RolledBack)
+}
+
 // TODO: fix this test to refactor mocking
 func TestReposService_CreateRepos_AllGood(t *testing.T) {
 	// setup
@@ -256,7 +558,7 @@ func TestReposService_CreateRepos_AllGood(t *testing.T) {
 		{Name: "my-github-repo"},
 	}
 
-	res, err := RepositoryService.CreateRepos(requests)
+	res, err := RepositoryService.CreateRepos(context.Background(), requests)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
@@ -270,3 +572,544 @@ func TestReposService_CreateRepos_AllGood(t *testing.T) {
 		assert.EqualValues(t, "dmolina79", result.Response.Owner)
 	}*/
 }
+
+func TestReposService_GetRepo_InvalidOwner(t *testing.T) {
+	res, err := RepositoryService.GetRepo(context.Background(), "-invalid-", "my-repo")
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestReposService_GetRepo_Success(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-repo", "owner": {"login": "dmolina79"}}`)),
+		},
+	})
+
+	res, err := RepositoryService.GetRepo(context.Background(), "dmolina79", "my-repo")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 123, res.Id)
+	assert.EqualValues(t, "dmolina79", res.Owner)
+}
+
+func TestReposService_UpdateRepo_InvalidInput(t *testing.T) {
+	homepage := "not a url"
+
+	res, err := RepositoryService.UpdateRepo(context.Background(), "dmolina79", "my-repo", repositories.UpdateRepoRequest{Homepage: &homepage})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestReposService_DeleteRepo_HandleErrorFromGH(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	err := RepositoryService.DeleteRepo(context.Background(), "dmolina79", "my-repo")
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestReposService_ListRepos_InvalidInput(t *testing.T) {
+	res, err := RepositoryService.ListRepos(context.Background(), repositories.ListReposRequest{Sort: "stars"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestReposService_ListRepos_NextCursor(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos?page=2",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/user/repos?page=3>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 123, "name": "my-repo", "owner": {"login": "dmolina79"}}]`)),
+		},
+	})
+
+	res, err := RepositoryService.ListRepos(context.Background(), repositories.ListReposRequest{
+		Cursor: repositories.NewCursor("https://api.github.com/user/repos?page=2"),
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 1, len(res.Repos))
+	assert.EqualValues(t, "dmolina79", res.Repos[0].Owner)
+	assert.EqualValues(t, repositories.NewCursor("https://api.github.com/user/repos?page=3"), res.NextCursor)
+}
+
+func TestReposService_ListAllRepos_FollowsPages(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/orgs/my-org/repos",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/organizations/1/repos?page=2>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 1, "name": "one"}, {"id": 2, "name": "two"}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/organizations/1/repos?page=2",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 3, "name": "three"}]`)),
+		},
+	})
+
+	var pages [][]repositories.Repository
+	err := RepositoryService.ListAllRepos(context.Background(), repositories.ListReposRequest{Org: "my-org"}, func(page []repositories.Repository) {
+		pages = append(pages, page)
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, len(pages))
+	assert.EqualValues(t, 2, len(pages[0]))
+	assert.EqualValues(t, "three", pages[1][0].Name)
+}
+
+func TestReposService_ListAllRepos_HandleErrorFromGH(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Link": []string{`<https://api.github.com/user/repos?page=2>; rel="next"`}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"id": 1, "name": "one"}]`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos?page=2",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Bad credentials"}`)),
+		},
+	})
+
+	pages := 0
+	err := RepositoryService.ListAllRepos(context.Background(), repositories.ListReposRequest{}, func(page []repositories.Repository) {
+		pages++
+	})
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+	assert.EqualValues(t, 1, pages)
+}
+
+func TestReposService_CreateRepo_SourceIsNotTemplate(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/service-template",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 1, "name": "service-template", "is_template": false}`)),
+		},
+	})
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:     "my-service",
+		Template: &repositories.TemplateSource{Owner: "my-org", Name: "service-template"},
+	})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "Repository my-org/service-template is not a template", err.Message())
+}
+
+func TestReposService_CreateRepo_TemplateNotFound(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/service-template",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+		},
+	})
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:     "my-service",
+		Template: &repositories.TemplateSource{Owner: "my-org", Name: "service-template"},
+	})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestReposService_CreateRepo_FromTemplate(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/service-template",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 1, "name": "service-template", "is_template": true}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-org/service-template/generate",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 2, "name": "my-service", "owner": {"login": "my-org"}}`)),
+		},
+	})
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Org:      "my-org",
+		Name:     "my-service",
+		Template: &repositories.TemplateSource{Owner: "my-org", Name: "service-template", IncludeAllBranches: true},
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 2, res.Id)
+	assert.EqualValues(t, "my-org", res.Owner)
+}
+
+func TestReposService_ForkRepo_InvalidInput(t *testing.T) {
+	res, err := RepositoryService.ForkRepo(context.Background(), "dmolina79", "my-repo", repositories.ForkRepoRequest{Name: "bad name"}, false)
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestReposService_ForkRepo_NoWait(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo/forks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 2, "name": "my-fork", "owner": {"login": "my-org"}}`)),
+		},
+	})
+
+	res, err := RepositoryService.ForkRepo(context.Background(), "dmolina79", "my-repo", repositories.ForkRepoRequest{Org: "my-org", Name: "my-fork"}, false)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, "my-org", res.Owner)
+	assert.False(t, res.Ready)
+}
+
+func TestReposService_ForkRepo_WaitUntilReady(t *testing.T) {
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo/forks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 2, "name": "my-repo", "owner": {"login": "my-user"}}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-user/my-repo/commits?per_page=1",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`[{"sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"}]`)),
+		},
+	})
+
+	res, err := RepositoryService.ForkRepo(context.Background(), "dmolina79", "my-repo", repositories.ForkRepoRequest{}, true)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, res.Ready)
+}
+
+func TestReposService_ForkRepo_WaitTimeout(t *testing.T) {
+	defer func(interval time.Duration) { forkPollInterval = interval }(forkPollInterval)
+	forkPollInterval = time.Second
+
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo/forks",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 2, "name": "my-repo", "owner": {"login": "my-user"}}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/my-user/my-repo/commits?per_page=1",
+		HttpMethod: http.MethodGet,
+		Response: &http.Response{
+			StatusCode: http.StatusConflict,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Git Repository is empty."}`)),
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	res, err := RepositoryService.ForkRepo(ctx, "dmolina79", "my-repo", repositories.ForkRepoRequest{}, true)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.False(t, res.Ready)
+}
+
+func TestReposService_CreateRepo_AppliesProtectionPolicy(t *testing.T) {
+	enabled := true
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-repo", "default_branch": "trunk", "owner": {"login": "dmolina79"}}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo/branches/trunk/protection",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"enforce_admins": {"enabled": true}}`)),
+		},
+	})
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", AutoInit: &enabled, ProtectionPolicy: "strict"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 1, len(res.Steps))
+	assert.EqualValues(t, repositories.StepBranchProtection, res.Steps[0].Step)
+	assert.True(t, res.Steps[0].Applied)
+	assert.EqualValues(t, "trunk", res.Steps[0].Target)
+}
+
+func TestReposService_CreateRepo_ProtectionPolicyFails(t *testing.T) {
+	enabled := true
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-repo", "owner": {"login": "dmolina79"}}`)),
+		},
+	})
+	restclient.AddMockUp(restclient.Mock{
+		Url:        "https://api.github.com/repos/dmolina79/my-repo/branches/main/protection",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Upgrade to GitHub Pro or make this repository public to enable this feature."}`)),
+		},
+	})
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", AutoInit: &enabled, ProtectionPolicy: "reviewed"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, 123, res.Id)
+	assert.EqualValues(t, 1, len(res.Steps))
+	assert.False(t, res.Steps[0].Applied)
+	assert.EqualValues(t, http.StatusForbidden, res.Steps[0].Error.Status())
+}
+
+func TestToBranchProtectionRequest(t *testing.T) {
+	request := toBranchProtectionRequest(repositories.ProtectionPolicy{RequiredReviews: 2, StatusChecks: []string{"ci"}, LinearHistory: true})
+
+	assert.NotNil(t, request.RequiredPullRequestReviews)
+	assert.EqualValues(t, 2, request.RequiredPullRequestReviews.RequiredApprovingReviewCount)
+	assert.NotNil(t, request.RequiredStatusChecks)
+	assert.EqualValues(t, []string{"ci"}, request.RequiredStatusChecks.Contexts)
+	assert.True(t, request.RequiredLinearHistory)
+	assert.Nil(t, request.Restrictions)
+}
+
+func TestReposService_CreateRepo_UnknownBlueprint(t *testing.T) {
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-service", Blueprint: "go-service"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "Unknown blueprint go-service", err.Message())
+}
+
+func TestReposService_CreateRepo_BlueprintTeamsNeedOrg(t *testing.T) {
+	service := NewRepositoryService(blueprint_store.NewMemoryBlueprintStore(map[string]repositories.Blueprint{
+		"go-service": {Teams: []repositories.TeamAccess{{Team: "platform", Permission: "push"}}},
+	}), managed_store.NewMemoryManagedRepoStore())
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-service", Blueprint: "go-service"})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Blueprint go-service grants team access, it needs an organization repository", err.Message())
+}
+
+func TestReposService_CreateRepo_FromBlueprint(t *testing.T) {
+	managed := managed_store.NewMemoryManagedRepoStore()
+	service := NewRepositoryService(blueprint_store.NewMemoryBlueprintStore(map[string]repositories.Blueprint{
+		"go-service": {
+			Visibility:       repositories.VisibilityPrivate,
+			AutoInit:         true,
+			ProtectionPolicy: "reviewed",
+			Topics:           []string{"go"},
+			Labels:           []repositories.Label{{Name: "bug", Color: "d73a4a"}},
+			Teams:            []repositories.TeamAccess{{Team: "platform", Permission: "maintain"}},
+			Files:            []repositories.SeedFile{{Path: "CODEOWNERS", Content: "* @my-org/platform"}},
+		},
+	}), managed)
+
+	restclient.FlushMockups()
+	for _, mock := range []restclient.Mock{
+		{Url: "https://api.github.com/orgs/my-org/repos", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-service", "private": true, "default_branch": "main", "owner": {"login": "my-org"}}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/topics", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"names": ["go"]}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/labels", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusUnprocessableEntity,
+			Body: ioutil.NopCloser(strings.NewReader(`{"message": "Validation Failed"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/labels/bug", HttpMethod: http.MethodPatch, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"name": "bug", "color": "d73a4a"}`))}},
+		{Url: "https://api.github.com/orgs/my-org/teams/platform/repos/my-org/my-service", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusNoContent,
+			Body: ioutil.NopCloser(strings.NewReader(``))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/git/ref/heads/main", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"ref": "refs/heads/main", "object": {"sha": "head-sha", "type": "commit"}}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/git/commits/head-sha", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"sha": "head-sha", "tree": {"sha": "base-tree-sha"}}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/git/blobs", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusForbidden,
+			Body: ioutil.NopCloser(strings.NewReader(`{"message": "Resource not accessible by integration"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/branches/main/protection", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{}`))}},
+	} {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := service.CreateRepo(context.Background(), repositories.CreateRepoRequest{Org: "my-org", Name: "my-service", Blueprint: "go-service"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, res.Private)
+	assert.EqualValues(t, 5, len(res.Steps))
+	steps := []string{repositories.StepSeed, repositories.StepTopics, repositories.StepLabel, repositories.StepTeam, repositories.StepBranchProtection}
+	for i, step := range steps {
+		assert.EqualValues(t, step, res.Steps[i].Step)
+	}
+	assert.False(t, res.Steps[0].Applied)
+	assert.EqualValues(t, http.StatusForbidden, res.Steps[0].Error.Status())
+	assert.True(t, res.Steps[2].Applied)
+	assert.True(t, res.Steps[4].Applied)
+
+	recorded, err := managed.Get("my-org", "my-service")
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.VisibilityPrivate, recorded.Visibility)
+	assert.EqualValues(t, "main", recorded.DefaultBranch)
+	assert.EqualValues(t, []string{"go"}, recorded.Topics)
+	assert.EqualValues(t, "reviewed", recorded.ProtectionPolicy)
+}
+
+func TestReposService_CreateRepo_InitialAccess(t *testing.T) {
+	restclient.FlushMockups()
+	for _, mock := range []restclient.Mock{
+		{Url: "https://api.github.com/orgs/my-org/repos", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-service", "owner": {"login": "my-org"}}`))}},
+		{Url: "https://api.github.com/orgs/my-org/teams/platform/repos/my-org/my-service", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusNoContent,
+			Body: ioutil.NopCloser(strings.NewReader(``))}},
+		{Url: "https://api.github.com/repos/my-org/my-service/collaborators/octocat", HttpMethod: http.MethodPut, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"id": 7, "invitee": {"login": "octocat"}}`))}},
+	} {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{Org: "my-org", Name: "my-service", Access: []repositories.AccessGrant{
+		{User: "octocat", Permission: "pull"},
+		{Team: "platform", Permission: "maintain"},
+	}})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, []repositories.StepResult{
+		{Step: repositories.StepTeam, Target: "platform", Applied: true},
+		{Step: repositories.StepCollaborator, Target: "octocat", Applied: true},
+	}, res.Steps)
+}
+
+func TestReposService_CreateRepo_PruneLabelsAndMilestones(t *testing.T) {
+	restclient.FlushMockups()
+	for _, mock := range []restclient.Mock{
+		{Url: "https://api.github.com/user/repos", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-service", "owner": {"login": "dmolina79"}}`))}},
+		{Url: "https://api.github.com/repos/dmolina79/my-service/labels?per_page=100", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`[{"name": "bug", "color": "d73a4a"}, {"name": "question", "color": "d876e3"}]`))}},
+		{Url: "https://api.github.com/repos/dmolina79/my-service/labels/question", HttpMethod: http.MethodDelete, Response: &http.Response{StatusCode: http.StatusNoContent,
+			Body: ioutil.NopCloser(strings.NewReader(``))}},
+		{Url: "https://api.github.com/repos/dmolina79/my-service/milestones", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"number": 1, "title": "v1"}`))}},
+	} {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{
+		Name:        "my-service",
+		Labels:      []repositories.Label{{Name: "bug", Color: "d73a4a"}},
+		PruneLabels: true,
+		Milestones:  []repositories.Milestone{{Title: "v1"}},
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, []repositories.StepResult{
+		{Step: repositories.StepLabelDelete, Target: "question", Applied: true},
+		{Step: repositories.StepMilestone, Target: "v1", Applied: true},
+	}, res.Steps)
+}
+
+func TestReposService_CreateRepo_Seed(t *testing.T) {
+	enabled := true
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/orgs/my-org/repos", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+		Body: ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-repo", "owner": {"login": "my-org"}, "default_branch": "main"}`))}})
+	for _, mock := range seedMocks("main") {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{Org: "my-org", Name: "my-repo", AutoInit: &enabled, Seed: &repositories.SeedRequest{
+		Files: []repositories.SeedFile{{Path: "README.md", Content: "# my-repo"}},
+	}})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, []repositories.StepResult{{Step: repositories.StepSeed, Target: "main", Applied: true}}, res.Steps)
+}
+
+func TestReposService_CreateRepo_CiWebhookNotConfigured(t *testing.T) {
+	enabled := true
+	res, err := RepositoryService.CreateRepo(context.Background(), repositories.CreateRepoRequest{Name: "my-repo", CiWebhook: &enabled})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "No CI webhook is configured", err.Message())
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// repoSetup is everything applied to a repository once github created it.
+type repoSetup struct {
+	protectionPolicy string
+	topics           []string
+	labels           []repositories.Label
+	pruneLabels      bool
+	milestones       []repositories.Milestone
+	teams            []repositories.TeamAccess
+	collaborators    []repositories.AccessGrant
+	seed             *repositories.SeedRequest
+	ciHook           *github.CreateHookRequest
+}
+
+// expandBlueprint completes input with the blueprint it names, if any.
+func (s *reposService) expandBlueprint(input repositories.CreateRepoRequest) (repositories.CreateRepoRequest, *repositories.Blueprint, errors.ApiError) {
+	input.Blueprint = strings.TrimSpace(input.Blueprint)
+	if input.Blueprint == "" {
+		return input, nil, nil
+	}
+	if s.blueprints == nil {
+		return input, nil, errors.NewBadRequestError(fmt.Sprintf("Unknown blueprint %s", input.Blueprint))
+	}
+
+	blueprint, err := s.blueprints.Get(input.Blueprint)
+	if err != nil {
+		return input, nil, err
+	}
+	if len(blueprint.Teams) > 0 && strings.TrimSpace(input.Org) == "" {
+		return input, nil, errors.NewBadRequestError(fmt.Sprintf("Blueprint %s grants team access, it needs an organization repository", input.Blueprint))
+	}
+
+	return blueprint.Apply(input), blueprint, nil
+}
+
+func newRepoSetup(input repositories.CreateRepoRequest, blueprint *repositories.Blueprint) repoSetup {
+	setup := repoSetup{
+		protectionPolicy: input.ProtectionPolicy,
+		topics:           input.Topics,
+		labels:           input.Labels,
+		pruneLabels:      input.PruneLabels,
+		milestones:       input.Milestones,
+		seed:             input.Seed,
+		ciHook:           ciHookRequest(input),
+	}
+	if blueprint != nil {
+		setup.teams = blueprint.Teams
+	}
+	for _, access := range input.Access {
+		if access.Team != "" {
+			setup.teams = append(setup.teams, repositories.TeamAccess{Team: access.Team, Permission: access.Permission})
+		} else {
+			setup.collaborators = append(setup.collaborators, access)
+		}
+	}
+	return setup
+}
+
+// setupRepo applies every step of setup to the repository github created, in an order
+// that keeps the branch protection from rejecting the initial files. Step failures are
+// reported in the response since the repository exists anyway.
+func (s *reposService) setupRepo(ctx context.Context, res *github.Repository, setup repoSetup) *repositories.CreateRepoResponse {
+	response := &repositories.CreateRepoResponse{Repository: *toRepository(res)}
+	owner, name := response.Owner, response.Name
+	accessToken := config.GetGithubAccessToken()
+
+	if setup.seed != nil {
+		response.Steps = append(response.Steps, setupSeed(ctx, owner, name, response.DefaultBranch, *setup.seed))
+	}
+	if len(setup.topics) > 0 {
+		_, err := github_provider.ReplaceTopics(ctx, accessToken, owner, name, setup.topics)
+		response.Steps = append(response.Steps, newStepResult(repositories.StepTopics, "", err))
+	}
+	response.Steps = append(response.Steps, setupLabels(ctx, accessToken, owner, name, setup)...)
+	for _, milestone := range setup.milestones {
+		_, err := createMilestone(ctx, accessToken, owner, name, milestone)
+		response.Steps = append(response.Steps, newStepResult(repositories.StepMilestone, milestone.Title, err))
+	}
+	for _, access := range setup.teams {
+		err := github_provider.AddTeamRepo(ctx, accessToken, owner, access.Team, owner, name, access.Permission)
+		response.Steps = append(response.Steps, newStepResult(repositories.StepTeam, access.Team, err))
+	}
+	for _, access := range setup.collaborators {
+		_, err := github_provider.AddCollaborator(ctx, accessToken, owner, name, access.User, github.CollaboratorRequest{Permission: access.Permission})
+		response.Steps = append(response.Steps, newStepResult(repositories.StepCollaborator, access.User, err))
+	}
+	if setup.protectionPolicy != "" {
+		response.Steps = append(response.Steps, s.applyProtectionPolicy(ctx, owner, name, response.DefaultBranch, setup.protectionPolicy))
+	}
+	// the CI webhook comes last, the commits of the setup are not worth a build
+	if setup.ciHook != nil {
+		_, err := github_provider.CreateHook(ctx, accessToken, owner, name, *setup.ciHook)
+		response.Steps = append(response.Steps, newStepResult(repositories.StepWebhook, repositories.StepWebhookCi, err))
+	}
+
+	for _, step := range response.Steps {
+		if step.Error != nil {
+			log.Error("error when trying to set up repository", step.Error, fmt.Sprintf("name:%s", name), fmt.Sprintf("step:%s", step.Step), fmt.Sprintf("target:%s", step.Target))
+		}
+	}
+	s.recordManaged(response.Repository, setup)
+	return response
+}
+
+// recordManaged keeps the desired state of a repository this api just created. A failed
+// step is still recorded as desired, drift detection then reports it.
+func (s *reposService) recordManaged(repo repositories.Repository, setup repoSetup) {
+	if s.managed == nil {
+		return
+	}
+	managed := &drift.ManagedRepo{
+		Owner:            repo.Owner,
+		Name:             repo.Name,
+		Visibility:       repo.GetVisibility(),
+		DefaultBranch:    repo.DefaultBranch,
+		HasIssues:        repo.HasIssues,
+		HasProjects:      repo.HasProjects,
+		HasWiki:          repo.HasWiki,
+		Topics:           setup.topics,
+		ProtectionPolicy: setup.protectionPolicy,
+		RecordedAt:       time.Now().UTC(),
+	}
+	if err := s.managed.Save(managed); err != nil {
+		log.Error("error when trying to record managed repository", err, fmt.Sprintf("owner:%s", repo.Owner), fmt.Sprintf("name:%s", repo.Name))
+	}
+}
+
+// updateManaged records the settings changed through this api, so they are not
+// reported as drift. Repositories this api did not create are left unmanaged.
+func (s *reposService) updateManaged(owner string, name string, update repositories.UpdateRepoRequest) {
+	if s.managed == nil {
+		return
+	}
+	managed, err := s.managed.Get(owner, name)
+	if err != nil {
+		return
+	}
+
+	managed.Apply(update)
+	if update.Name != nil {
+		s.forgetManaged(owner, name)
+	}
+	if err := s.managed.Save(managed); err != nil {
+		log.Error("error when trying to record managed repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+	}
+}
+
+func (s *reposService) forgetManaged(owner string, name string) {
+	if s.managed == nil {
+		return
+	}
+	if err := s.managed.Delete(owner, name); err != nil {
+		log.Error("error when trying to forget managed repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+	}
+}
+
+func newStepResult(step string, target string, err *github.GithubErrorResponse) repositories.StepResult {
+	result := repositories.StepResult{Step: step, Target: target, Applied: err == nil}
+	if err != nil {
+		result.Error = newApiErrorFromGithub(err)
+	}
+	return result
+}
+
+// setupSeed commits the seed as the only commit of the new repository, the files
+// github created on auto init are kept unless the seed overrides them.
+func setupSeed(ctx context.Context, owner string, name string, branch string, seed repositories.SeedRequest) repositories.StepResult {
+	seed.Replace = true
+	seed.Branch = branch
+	if seed.Branch == "" {
+		seed.Branch = defaultBranch
+	}
+
+	result := repositories.StepResult{Step: repositories.StepSeed, Target: seed.Branch, Applied: true}
+	if _, err := SeedService.SeedRepo(ctx, owner, name, seed); err != nil {
+		result.Applied = false
+		result.Error = err
+	}
+	return result
+}
+
+// setupLabels applies the labels of setup. Pruning needs the labels github created by
+// default to be listed first, otherwise each label is just created or updated.
+func setupLabels(ctx context.Context, accessToken string, owner string, name string, setup repoSetup) []repositories.StepResult {
+	var steps []repositories.StepResult
+	if !setup.pruneLabels {
+		for _, label := range setup.labels {
+			steps = append(steps, newStepResult(repositories.StepLabel, label.Name, applyLabel(ctx, accessToken, owner, name, label)))
+		}
+		return steps
+	}
+
+	results, err := syncLabels(ctx, accessToken, owner, name, setup.labels, true)
+	if err != nil {
+		return append(steps, newStepResult(repositories.StepLabel, "", err))
+	}
+	for _, result := range results {
+		if result.Action == repositories.LabelUnchanged {
+			continue
+		}
+		step := repositories.StepResult{Step: repositories.StepLabel, Target: result.Name, Applied: result.Applied, Error: result.Error}
+		if result.Action == repositories.LabelDeleted {
+			step.Step = repositories.StepLabelDelete
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// applyLabel creates label, or updates it when github already created one with that name.
+func applyLabel(ctx context.Context, accessToken string, owner string, name string, label repositories.Label) *github.GithubErrorResponse {
+	request := github.Label{Name: label.Name, Color: label.Color, Description: label.Description}
+	_, err := github_provider.CreateLabel(ctx, accessToken, owner, name, request)
+	if err != nil && err.StatusCode == http.StatusUnprocessableEntity {
+		_, err = github_provider.UpdateLabel(ctx, accessToken, owner, name, label.Name, request)
+	}
+	return err
+}
+
+func (s *reposService) applyProtectionPolicy(ctx context.Context, owner string, name string, branch string, policyName string) repositories.StepResult {
+	if branch == "" {
+		branch = defaultBranch
+	}
+	result := repositories.StepResult{Step: repositories.StepBranchProtection, Target: branch}
+
+	policy, apiErr := repositories.GetProtectionPolicy(policyName)
+	if apiErr != nil {
+		result.Error = apiErr
+		return result
+	}
+
+	_, err := github_provider.ProtectBranch(ctx, config.GetGithubAccessToken(), owner, name, branch, toBranchProtectionRequest(*policy))
+	return newStepResult(repositories.StepBranchProtection, branch, err)
+}
+
+func toBranchProtectionRequest(policy repositories.ProtectionPolicy) github.BranchProtectionRequest {
+	request := github.BranchProtectionRequest{
+		EnforceAdmins:         policy.EnforceAdmins,
+		RequiredLinearHistory: policy.LinearHistory,
+	}
+	if policy.RequiredReviews > 0 {
+		request.RequiredPullRequestReviews = &github.RequiredPullRequestReviews{
+			DismissStaleReviews:          policy.DismissStaleReviews,
+			RequireCodeOwnerReviews:      policy.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: policy.RequiredReviews,
+		}
+	}
+	if len(policy.StatusChecks) > 0 || policy.StrictStatusChecks {
+		request.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   policy.StrictStatusChecks,
+			Contexts: append([]string{}, policy.StatusChecks...),
+		}
+	}
+
+	return request
+}
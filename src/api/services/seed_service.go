@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/providers/github_provider"
+	"github.com/dmolina79/golang-github-api/src/api/stores/seed_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/http"
+)
+
+const (
+	// seedPlaceholder is committed through the contents api to give an empty repository
+	// its first commit, the git data api refusing to work without one. The seed commit
+	// then replaces it.
+	seedPlaceholder = ".seed"
+)
+
+type seedService struct {
+	templates seed_store.SeedTemplateStore
+}
+
+type seedServiceInterface interface {
+	SeedRepo(ctx context.Context, owner string, name string, request repositories.SeedRequest) (*repositories.SeedResponse, errors.ApiError)
+}
+
+var (
+	SeedService seedServiceInterface
+)
+
+func init() {
+	SeedService = NewSeedService(seed_store.NewDirSeedTemplateStore(config.GetSeedTemplatesDir()))
+}
+
+func NewSeedService(templates seed_store.SeedTemplateStore) seedServiceInterface {
+	return &seedService{templates: templates}
+}
+
+// SeedRepo commits every file of the request at once: the branch only moves once the
+// whole commit exists, so a failure leaves the repository untouched.
+func (s *seedService) SeedRepo(ctx context.Context, owner string, name string, input repositories.SeedRequest) (*repositories.SeedResponse, errors.ApiError) {
+	if err := validateRepoPath(owner, name); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	accessToken := config.GetGithubAccessToken()
+	if input.Branch == "" {
+		repo, err := github_provider.GetRepo(ctx, accessToken, owner, name)
+		if err != nil {
+			log.Error("error when trying to get repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name))
+			return nil, newApiErrorFromGithub(err)
+		}
+		input.Branch = repo.DefaultBranch
+		if input.Branch == "" {
+			input.Branch = defaultBranch
+		}
+	}
+
+	files, apiErr := s.seedFiles(owner, name, input)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	commit, err := commitSeed(ctx, accessToken, owner, name, input, files)
+	if err != nil {
+		log.Error("error when trying to seed repository", err, fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("branch:%s", input.Branch))
+		return nil, newApiErrorFromGithub(err)
+	}
+
+	response := &repositories.SeedResponse{Branch: input.Branch, Commit: commit.Sha, HtmlUrl: commit.HtmlUrl, Files: make([]string, 0, len(files))}
+	for _, file := range files {
+		response.Files = append(response.Files, file.Path)
+	}
+	log.Info("repository seeded", fmt.Sprintf("owner:%s", owner), fmt.Sprintf("name:%s", name), fmt.Sprintf("files:%d", len(files)))
+	return response, nil
+}
+
+// seedFiles renders the template of input, if any, and lays the inline files over it.
+func (s *seedService) seedFiles(owner string, name string, input repositories.SeedRequest) ([]repositories.SeedFile, errors.ApiError) {
+	if input.Template == "" {
+		return input.Files, nil
+	}
+
+	templateFiles, err := s.templates.Get(input.Template)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := repositories.RenderSeedFiles(templateFiles, repositories.SeedData{
+		Owner:  owner,
+		Name:   name,
+		Branch: input.Branch,
+		Vars:   input.Variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repositories.MergeSeedFiles(rendered, input.Files), nil
+}
+
+// commitSeed builds a commit holding files on top of the tree of the branch head. The
+// commit follows the head, or replaces it when input.Replace is set or the repository
+// was empty.
+func commitSeed(ctx context.Context, accessToken string, owner string, name string, input repositories.SeedRequest, files []repositories.SeedFile) (*github.Commit, *github.GithubErrorResponse) {
+	// baseless is set for an empty repository, whose placeholder commit must not
+	// end up in the seed commit.
+	baseless := false
+	ref, err := github_provider.GetBranchRef(ctx, accessToken, owner, name, input.Branch)
+	if err != nil && err.StatusCode == http.StatusConflict {
+		baseless = true
+		ref, err = createPlaceholderCommit(ctx, accessToken, owner, name, input.Branch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	replace := input.Replace || baseless
+
+	tree := github.CreateTreeRequest{Tree: make([]github.TreeEntry, 0, len(files))}
+	if !baseless {
+		head, err := github_provider.GetCommit(ctx, accessToken, owner, name, ref.Object.Sha)
+		if err != nil {
+			return nil, err
+		}
+		tree.BaseTree = head.Tree.Sha
+	}
+
+	for _, file := range files {
+		blob, err := github_provider.CreateBlob(ctx, accessToken, owner, name, base64.StdEncoding.EncodeToString([]byte(file.Content)))
+		if err != nil {
+			return nil, err
+		}
+		mode := github.TreeModeFile
+		if file.Executable {
+			mode = github.TreeModeExecutable
+		}
+		tree.Tree = append(tree.Tree, github.TreeEntry{Path: file.Path, Mode: mode, Type: github.TreeTypeBlob, Sha: blob.Sha})
+	}
+
+	newTree, err := github_provider.CreateTree(ctx, accessToken, owner, name, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	request := github.CreateCommitRequest{Message: input.Message, Tree: newTree.Sha, Parents: []string{}}
+	if !replace {
+		request.Parents = append(request.Parents, ref.Object.Sha)
+	}
+	commit, err := github_provider.CreateCommit(ctx, accessToken, owner, name, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := github_provider.UpdateBranchRef(ctx, accessToken, owner, name, input.Branch, github.UpdateRefRequest{Sha: commit.Sha, Force: replace}); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+func createPlaceholderCommit(ctx context.Context, accessToken string, owner string, name string, branch string) (*github.Ref, *github.GithubErrorResponse) {
+	err := github_provider.CreateFile(ctx, accessToken, owner, name, seedPlaceholder, github.CreateFileRequest{
+		Message: "Prepare initial commit",
+		Branch:  branch,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return github_provider.GetBranchRef(ctx, accessToken, owner, name, branch)
+}
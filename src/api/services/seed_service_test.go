@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"github.com/dmolina79/golang-github-api/src/api/client/restclient"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/stores/seed_store"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// seedMocks answers the git data calls of a seed commit on branch of my-org/my-repo.
+func seedMocks(branch string) []restclient.Mock {
+	return []restclient.Mock{
+		{Url: "https://api.github.com/repos/my-org/my-repo/git/ref/heads/" + branch, HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"ref": "refs/heads/` + branch + `", "object": {"sha": "head-sha", "type": "commit"}}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/git/commits/head-sha", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"sha": "head-sha", "tree": {"sha": "base-tree-sha"}}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/git/blobs", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"sha": "blob-sha"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/git/trees", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"sha": "tree-sha"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/git/commits", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusCreated,
+			Body: ioutil.NopCloser(strings.NewReader(`{"sha": "seed-sha", "html_url": "https://github.com/my-org/my-repo/commit/seed-sha"}`))}},
+		{Url: "https://api.github.com/repos/my-org/my-repo/git/refs/heads/" + branch, HttpMethod: http.MethodPatch, Response: &http.Response{StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(strings.NewReader(`{"ref": "refs/heads/` + branch + `", "object": {"sha": "seed-sha", "type": "commit"}}`))}},
+	}
+}
+
+func TestSeedService_SeedRepo_InvalidRequest(t *testing.T) {
+	res, err := SeedService.SeedRepo(context.Background(), "my-org", "my-repo", repositories.SeedRequest{})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestSeedService_SeedRepo_InlineFiles(t *testing.T) {
+	restclient.FlushMockups()
+	for _, mock := range seedMocks("develop") {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := SeedService.SeedRepo(context.Background(), "my-org", "my-repo", repositories.SeedRequest{
+		Branch: "develop",
+		Files:  []repositories.SeedFile{{Path: "README.md", Content: "# my-repo"}},
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, &repositories.SeedResponse{
+		Branch:  "develop",
+		Commit:  "seed-sha",
+		HtmlUrl: "https://github.com/my-org/my-repo/commit/seed-sha",
+		Files:   []string{"README.md"},
+	}, res)
+}
+
+func TestSeedService_SeedRepo_FromTemplate(t *testing.T) {
+	dir, tempErr := ioutil.TempDir("", "seeds")
+	assert.Nil(t, tempErr)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "go-service"), 0700))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go-service", "CODEOWNERS.tmpl"), []byte("* @{{.Owner}}/{{.Vars.team}}\n"), 0600))
+
+	restclient.FlushMockups()
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo", HttpMethod: http.MethodGet, Response: &http.Response{StatusCode: http.StatusOK,
+		Body: ioutil.NopCloser(strings.NewReader(`{"id": 123, "name": "my-repo", "owner": {"login": "my-org"}, "default_branch": "trunk"}`))}})
+	for _, mock := range seedMocks("trunk") {
+		restclient.AddMockUp(mock)
+	}
+
+	res, err := NewSeedService(seed_store.NewDirSeedTemplateStore(dir)).SeedRepo(context.Background(), "my-org", "my-repo", repositories.SeedRequest{
+		Template:  "go-service",
+		Variables: map[string]string{"team": "payments"},
+	})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.EqualValues(t, "trunk", res.Branch)
+	assert.EqualValues(t, []string{"CODEOWNERS"}, res.Files)
+}
+
+func TestSeedService_SeedRepo_MissingVariable(t *testing.T) {
+	dir, tempErr := ioutil.TempDir("", "seeds")
+	assert.Nil(t, tempErr)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "go-service"), 0700))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go-service", "CODEOWNERS.tmpl"), []byte("* @{{.Owner}}/{{.Vars.team}}\n"), 0600))
+	restclient.FlushMockups()
+
+	res, err := NewSeedService(seed_store.NewDirSeedTemplateStore(dir)).SeedRepo(context.Background(), "my-org", "my-repo", repositories.SeedRequest{
+		Template: "go-service",
+		Branch:   "main",
+	})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestSeedService_SeedRepo_CommitFails(t *testing.T) {
+	restclient.FlushMockups()
+	for _, mock := range seedMocks("main")[:4] {
+		restclient.AddMockUp(mock)
+	}
+	restclient.AddMockUp(restclient.Mock{Url: "https://api.github.com/repos/my-org/my-repo/git/commits", HttpMethod: http.MethodPost, Response: &http.Response{StatusCode: http.StatusUnprocessableEntity,
+		Body: ioutil.NopCloser(strings.NewReader(`{"message": "Tree SHA does not exist"}`))}})
+
+	res, err := SeedService.SeedRepo(context.Background(), "my-org", "my-repo", repositories.SeedRequest{
+		Branch: "main",
+		Files:  []repositories.SeedFile{{Path: "README.md", Content: "# my-repo"}},
+	})
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+	assert.EqualValues(t, "Tree SHA does not exist", err.Message())
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/stores/delivery_store"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	repositoryDeleted = "deleted"
+
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// InboundWebhook is a request github sent to /webhooks/github, Body being unread.
+// Headers are the ones logged along with the delivery.
+type InboundWebhook struct {
+	Event       string
+	DeliveryId  string
+	Signature   string
+	ContentType string
+	Headers     map[string]string
+	Body        []byte
+}
+
+// attemptPlan picks the handlers an attempt runs out of the delivery as logged when the
+// attempt starts, only the named ones unless nil. run false skips the attempt.
+type attemptPlan func(delivery *webhooks.Delivery) (only []string, run bool)
+
+type namedHandler struct {
+	name    string
+	handler webhooks.Handler
+}
+
+type webhookService struct {
+	secret     string
+	deliveries delivery_store.DeliveryStore
+	policy     webhooks.RetryPolicy
+	mutex      sync.RWMutex
+	handlers   map[string][]namedHandler
+	// running holds the deliveries being handled, for a retry and a replay of the same
+	// delivery not to run at once.
+	running map[string]bool
+}
+
+type webhookServiceInterface interface {
+	Register(event string, name string, handler webhooks.Handler)
+	Receive(ctx context.Context, request InboundWebhook) (*webhooks.DeliveryResult, errors.ApiError)
+	ListDeliveries(ctx context.Context, filter webhooks.DeliveryFilter) ([]webhooks.Delivery, errors.ApiError)
+	GetDelivery(ctx context.Context, id string) (*webhooks.Delivery, errors.ApiError)
+	ReplayDelivery(ctx context.Context, id string) (*webhooks.Delivery, errors.ApiError)
+	RetryDue(ctx context.Context) (int, errors.ApiError)
+	PruneDeliveries(ctx context.Context, retention time.Duration) (int, errors.ApiError)
+}
+
+var (
+	WebhookService webhookServiceInterface
+)
+
+func init() {
+	WebhookService = NewWebhookService(config.GetGithubWebhookSecret(), delivery_store.NewMemoryDeliveryStore(), webhooks.RetryPolicy{
+		MaxAttempts: config.GetWebhookMaxAttempts(),
+		Backoff:     config.GetWebhookRetryBackoff(),
+	})
+}
+
+func NewWebhookService(secret string, deliveries delivery_store.DeliveryStore, policy webhooks.RetryPolicy) webhookServiceInterface {
+	return &webhookService{
+		secret:     secret,
+		deliveries: deliveries,
+		policy:     policy,
+		handlers:   make(map[string][]namedHandler),
+		running:    make(map[string]bool),
+	}
+}
+
+// Register makes handler receive every delivery of event, in registration order. The
+// name identifies the handler in the delivery log and must be unique for the event.
+func (s *webhookService) Register(event string, name string, handler webhooks.Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers[event] = append(s.handlers[event], namedHandler{name: name, handler: handler})
+}
+
+// Receive checks the signature of the request before anything else, logs the delivery
+// and hands the typed event to the handlers of its type. A handler failing does not
+// fail the request, the delivery is retried later on. A delivery already received is
+// only handled again when it failed, its failed handlers being run once more, or when
+// it was never handled.
+func (s *webhookService) Receive(ctx context.Context, request InboundWebhook) (*webhooks.DeliveryResult, errors.ApiError) {
+	if s.secret == "" {
+		apiErr := errors.NewInternalServerError("Webhook secret is not configured")
+		log.Error("error when trying to receive webhook", apiErr, fmt.Sprintf("delivery:%s", request.DeliveryId))
+		return nil, apiErr
+	}
+	if !webhooks.VerifySignature(s.secret, request.Signature, request.Body) {
+		log.Info("webhook with an invalid signature rejected", fmt.Sprintf("delivery:%s", request.DeliveryId))
+		return nil, errors.NewApiError(http.StatusUnauthorized, "Invalid signature")
+	}
+
+	request.Event = strings.TrimSpace(request.Event)
+	request.DeliveryId = strings.TrimSpace(request.DeliveryId)
+	if request.Event == "" || request.DeliveryId == "" {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Missing %s or %s header", webhooks.HeaderEvent, webhooks.HeaderDelivery))
+	}
+	payload, apiErr := webhooks.DecodePayload(request.ContentType, request.Body)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	delivery, apiErr := webhooks.NewDelivery(request.DeliveryId, request.Event, payload)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if _, apiErr := webhooks.ParseEvent(request.Event, payload); apiErr != nil {
+		return nil, apiErr
+	}
+	delivery.Headers = request.Headers
+	delivery.ScheduleRecovery(s.policy)
+
+	created, apiErr := s.deliveries.Create(delivery)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	trigger, plan := webhooks.TriggerDelivery, runAll
+	if !created {
+		trigger, plan = webhooks.TriggerRedelivery, rerunFailed
+	}
+
+	delivery, attempt, apiErr := s.attempt(ctx, request.DeliveryId, trigger, plan)
+	if apiErr != nil && !created && apiErr.Status() == http.StatusConflict {
+		// redelivered while still being handled, there is nothing to run again yet
+		delivery, apiErr = s.deliveries.Get(request.DeliveryId)
+	}
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if attempt == nil {
+		log.Info("duplicate webhook delivery ignored", fmt.Sprintf("delivery:%s", delivery.Id), fmt.Sprintf("event:%s", delivery.Event))
+		return &webhooks.DeliveryResult{DeliveryId: delivery.Id, Event: delivery.Event, Duplicate: true, Status: delivery.Status}, nil
+	}
+	return &webhooks.DeliveryResult{DeliveryId: delivery.Id, Event: delivery.Event, Duplicate: !created, Status: delivery.Status, Handled: len(attempt.Handlers)}, nil
+}
+
+// ListDeliveries returns the logged deliveries without their payload, the latest first.
+func (s *webhookService) ListDeliveries(ctx context.Context, filter webhooks.DeliveryFilter) ([]webhooks.Delivery, errors.ApiError) {
+	switch filter.Status {
+	case "", webhooks.StatusReceived, webhooks.StatusSucceeded, webhooks.StatusFailed, webhooks.StatusDeadLetter:
+	default:
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid status %s", filter.Status))
+	}
+	if filter.Limit < 0 || filter.Limit > maxDeliveriesLimit {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid limit, it must be between 1 and %d", maxDeliveriesLimit))
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDeliveriesLimit
+	}
+
+	deliveries, err := s.deliveries.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		deliveries[i].Payload = nil
+	}
+	if deliveries == nil {
+		deliveries = []webhooks.Delivery{}
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, id string) (*webhooks.Delivery, errors.ApiError) {
+	return s.deliveries.Get(strings.TrimSpace(id))
+}
+
+// ReplayDelivery runs every handler of the delivery again, whatever its status. A
+// failing replay starts a new run of retries.
+func (s *webhookService) ReplayDelivery(ctx context.Context, id string) (*webhooks.Delivery, errors.ApiError) {
+	delivery, _, err := s.attempt(ctx, strings.TrimSpace(id), webhooks.TriggerReplay, runAll)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("webhook delivery replayed", fmt.Sprintf("delivery:%s", delivery.Id), fmt.Sprintf("status:%s", delivery.Status))
+	return delivery, nil
+}
+
+// RetryDue retries the failed handlers of every delivery whose retry is due, returning
+// how many deliveries were retried. A delivery replayed or redelivered meanwhile is
+// left alone unless still due.
+func (s *webhookService) RetryDue(ctx context.Context) (int, errors.ApiError) {
+	now := time.Now().UTC()
+	due, err := s.deliveries.Due(now)
+	if err != nil {
+		return 0, err
+	}
+
+	plan := func(delivery *webhooks.Delivery) ([]string, bool) {
+		return lastFailed(delivery), delivery.IsDue(now)
+	}
+	retried := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		delivery, attempt, err := s.attempt(ctx, due[i].Id, webhooks.TriggerRetry, plan)
+		if err != nil {
+			if err.Status() != http.StatusConflict {
+				log.Error("error when trying to retry webhook delivery", err, fmt.Sprintf("delivery:%s", due[i].Id))
+			}
+			continue
+		}
+		if attempt == nil {
+			continue
+		}
+		retried++
+		if delivery.Status == webhooks.StatusDeadLetter {
+			log.Info("webhook delivery dead lettered", fmt.Sprintf("delivery:%s", delivery.Id), fmt.Sprintf("attempts:%d", delivery.RunAttempts()))
+		}
+	}
+	return retried, nil
+}
+
+func (s *webhookService) PruneDeliveries(ctx context.Context, retention time.Duration) (int, errors.ApiError) {
+	return s.deliveries.Prune(time.Now().UTC().Add(-retention))
+}
+
+// StartWebhookRetries runs WebhookService.RetryDue every interval in the background,
+// forgetting the deliveries older than retention, until the returned function is called.
+func StartWebhookRetries(interval time.Duration, retention time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := WebhookService.RetryDue(ctx); err != nil {
+					log.Error("error when trying to retry webhook deliveries", err)
+				}
+				if _, err := WebhookService.PruneDeliveries(ctx, retention); err != nil {
+					log.Error("error when trying to prune webhook deliveries", err)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// attempt runs the handlers plan picks for delivery id and logs the attempt along with
+// the new status of the delivery. The delivery is read once no other attempt runs for
+// it, so that it is never saved over a newer one. The attempt is nil when plan skipped it.
+func (s *webhookService) attempt(ctx context.Context, id string, trigger string, plan attemptPlan) (*webhooks.Delivery, *webhooks.Attempt, errors.ApiError) {
+	if !s.start(id) {
+		return nil, nil, errors.NewApiError(http.StatusConflict, fmt.Sprintf("Delivery %s is being handled", id))
+	}
+	defer s.finish(id)
+
+	delivery, apiErr := s.deliveries.Get(id)
+	if apiErr != nil {
+		return nil, nil, apiErr
+	}
+	only, run := plan(delivery)
+	if !run {
+		return delivery, nil, nil
+	}
+	event, apiErr := webhooks.ParseEvent(delivery.Event, delivery.Payload)
+	if apiErr != nil {
+		return nil, nil, apiErr
+	}
+
+	attempt := webhooks.Attempt{Trigger: trigger, StartedAt: time.Now().UTC(), Handlers: []webhooks.HandlerOutcome{}}
+	for _, handler := range s.handlersOf(delivery.Event, only) {
+		started := time.Now()
+		outcome := webhooks.HandlerOutcome{Name: handler.name, Succeeded: true}
+		if err := handler.handler.Handle(ctx, *delivery, event); err != nil {
+			log.Error("error when trying to handle webhook delivery", err, fmt.Sprintf("delivery:%s", delivery.Id), fmt.Sprintf("event:%s", delivery.Event), fmt.Sprintf("handler:%s", handler.name))
+			outcome.Succeeded = false
+			outcome.Error = err.Error()
+		}
+		outcome.DurationMs = time.Since(started).Milliseconds()
+		attempt.Handlers = append(attempt.Handlers, outcome)
+	}
+	attempt.DurationMs = time.Since(attempt.StartedAt).Milliseconds()
+
+	delivery.AddAttempt(attempt, s.policy)
+	if err := s.deliveries.Save(delivery); err != nil {
+		return nil, nil, err
+	}
+	log.Info("webhook delivery handled", fmt.Sprintf("delivery:%s", delivery.Id), fmt.Sprintf("event:%s", delivery.Event), fmt.Sprintf("trigger:%s", trigger), fmt.Sprintf("status:%s", delivery.Status))
+	return delivery, &attempt, nil
+}
+
+func (s *webhookService) start(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running[id] {
+		return false
+	}
+	s.running[id] = true
+	return true
+}
+
+func (s *webhookService) finish(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.running, id)
+}
+
+// handlersOf returns the handlers of event, only the ones named in only when it is
+// not nil.
+func (s *webhookService) handlersOf(event string, only []string) []namedHandler {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if only == nil {
+		return s.handlers[event]
+	}
+	var handlers []namedHandler
+	for _, handler := range s.handlers[event] {
+		for _, name := range only {
+			if handler.name == name {
+				handlers = append(handlers, handler)
+				break
+			}
+		}
+	}
+	return handlers
+}
+
+func runAll(delivery *webhooks.Delivery) ([]string, bool) {
+	return nil, true
+}
+
+// rerunFailed runs again the handlers that failed last, for a delivery that did fail,
+// and every handler of a delivery whose handling got interrupted.
+func rerunFailed(delivery *webhooks.Delivery) ([]string, bool) {
+	switch delivery.Status {
+	case webhooks.StatusFailed, webhooks.StatusDeadLetter, webhooks.StatusReceived:
+		return lastFailed(delivery), true
+	}
+	return nil, false
+}
+
+// lastFailed names the handlers that failed in the last attempt of delivery.
+func lastFailed(delivery *webhooks.Delivery) []string {
+	if len(delivery.Attempts) == 0 {
+		return nil
+	}
+	failed := delivery.Attempts[len(delivery.Attempts)-1].Failed()
+	if failed == nil {
+		failed = []string{}
+	}
+	return failed
+}
+
+// NewManagedRepoHandler stops tracking the managed repositories deleted on github, drift
+// detection would otherwise report them as failed forever.
+func NewManagedRepoHandler(managed managed_store.ManagedRepoStore) webhooks.Handler {
+	return webhooks.OnRepository(func(ctx context.Context, delivery webhooks.Delivery, event *github.RepositoryEvent) error {
+		if event.Action != repositoryDeleted {
+			return nil
+		}
+		if err := managed.Delete(event.Repository.Owner.Login, event.Repository.Name); err != nil {
+			return err
+		}
+		log.Info("deleted repository no longer managed", fmt.Sprintf("owner:%s", event.Repository.Owner.Login), fmt.Sprintf("name:%s", event.Repository.Name))
+		return nil
+	})
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/domain/github"
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
+	"github.com/dmolina79/golang-github-api/src/api/stores/delivery_store"
+	"github.com/dmolina79/golang-github-api/src/api/stores/managed_store"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "s3cr3t"
+
+func signedWebhook(event string, deliveryId string, body string) InboundWebhook {
+	return InboundWebhook{
+		Event:       event,
+		DeliveryId:  deliveryId,
+		Signature:   webhooks.Sign(testWebhookSecret, []byte(body)),
+		ContentType: "application/json",
+		Headers:     map[string]string{"X-Github-Event": event},
+		Body:        []byte(body),
+	}
+}
+
+// newTestWebhookService retries right away, dead lettering on the third failed attempt.
+func newTestWebhookService(secret string) webhookServiceInterface {
+	return NewWebhookService(secret, delivery_store.NewMemoryDeliveryStore(), webhooks.RetryPolicy{MaxAttempts: 3})
+}
+
+// staleDueStore answers Due with the deliveries due when snapshot was called.
+type staleDueStore struct {
+	delivery_store.DeliveryStore
+	due []webhooks.Delivery
+}
+
+func (s *staleDueStore) snapshot() {
+	s.due, _ = s.DeliveryStore.Due(time.Now().UTC())
+}
+
+func (s *staleDueStore) Due(now time.Time) ([]webhooks.Delivery, errors.ApiError) {
+	return s.due, nil
+}
+
+// flakyHandler fails the first failures times it is called.
+func flakyHandler(failures int, calls *int) webhooks.Handler {
+	return webhooks.HandlerFunc(func(ctx context.Context, delivery webhooks.Delivery, event interface{}) error {
+		*calls++
+		if *calls <= failures {
+			return fmt.Errorf("ci is down")
+		}
+		return nil
+	})
+}
+
+func TestWebhookService_Receive_NoSecret(t *testing.T) {
+	service := newTestWebhookService("")
+
+	res, err := service.Receive(context.Background(), signedWebhook(github.EventPing, "1", `{}`))
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+}
+
+func TestWebhookService_Receive_InvalidSignature(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+	request := signedWebhook(github.EventPing, "1", `{}`)
+	request.Body = []byte(`{"zen": "tampered"}`)
+
+	res, err := service.Receive(context.Background(), request)
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+}
+
+func TestWebhookService_Receive_MissingHeaders(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+
+	res, err := service.Receive(context.Background(), signedWebhook(github.EventPing, "", `{}`))
+
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestWebhookService_Receive_DispatchesOnce(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+	var pushes []string
+	service.Register(github.EventPush, "pushes", webhooks.OnPush(func(ctx context.Context, delivery webhooks.Delivery, event *github.PushEvent) error {
+		pushes = append(pushes, delivery.Id+" "+event.Ref)
+		return nil
+	}))
+	request := signedWebhook(github.EventPush, "72d3162e", `{"ref": "refs/heads/main", "repository": {"full_name": "my-org/my-repo"}}`)
+
+	res, err := service.Receive(context.Background(), request)
+	assert.Nil(t, err)
+	assert.EqualValues(t, &webhooks.DeliveryResult{DeliveryId: "72d3162e", Event: github.EventPush, Status: webhooks.StatusSucceeded, Handled: 1}, res)
+
+	res, err = service.Receive(context.Background(), request)
+	assert.Nil(t, err)
+	assert.True(t, res.Duplicate)
+	assert.EqualValues(t, []string{"72d3162e refs/heads/main"}, pushes)
+
+	delivery, err := service.GetDelivery(context.Background(), "72d3162e")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "my-org/my-repo", delivery.Repository)
+	assert.EqualValues(t, map[string]string{"X-Github-Event": github.EventPush}, delivery.Headers)
+	assert.EqualValues(t, 1, len(delivery.Attempts))
+	assert.EqualValues(t, webhooks.TriggerDelivery, delivery.Attempts[0].Trigger)
+}
+
+func TestWebhookService_RetryDue_OnlyFailedHandlers(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+	ciCalls, chatCalls := 0, 0
+	service.Register(github.EventPullRequest, "ci", flakyHandler(0, &ciCalls))
+	service.Register(github.EventPullRequest, "chat", flakyHandler(1, &chatCalls))
+
+	res, err := service.Receive(context.Background(), signedWebhook(github.EventPullRequest, "72d3162e", `{"action": "opened", "number": 7}`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, webhooks.StatusFailed, res.Status)
+
+	retried, err := service.RetryDue(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, retried)
+	assert.EqualValues(t, 1, ciCalls)
+	assert.EqualValues(t, 2, chatCalls)
+
+	delivery, _ := service.GetDelivery(context.Background(), "72d3162e")
+	assert.EqualValues(t, webhooks.StatusSucceeded, delivery.Status)
+	assert.EqualValues(t, []webhooks.HandlerOutcome{{Name: "chat", Succeeded: true}}, withoutDurations(delivery.Attempts[1].Handlers))
+}
+
+func TestWebhookService_RetryDue_SkipsDeliveriesHandledMeanwhile(t *testing.T) {
+	store := &staleDueStore{DeliveryStore: delivery_store.NewMemoryDeliveryStore()}
+	service := NewWebhookService(testWebhookSecret, store, webhooks.RetryPolicy{MaxAttempts: 3})
+	calls := 0
+	service.Register(github.EventPush, "ci", flakyHandler(1, &calls))
+	_, err := service.Receive(context.Background(), signedWebhook(github.EventPush, "72d3162e", `{"ref": "refs/heads/main"}`))
+	assert.Nil(t, err)
+	store.snapshot()
+	_, err = service.ReplayDelivery(context.Background(), "72d3162e")
+	assert.Nil(t, err)
+
+	retried, err := service.RetryDue(context.Background())
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, retried)
+	assert.EqualValues(t, 2, calls)
+	delivery, _ := service.GetDelivery(context.Background(), "72d3162e")
+	assert.EqualValues(t, webhooks.StatusSucceeded, delivery.Status)
+	assert.EqualValues(t, 2, len(delivery.Attempts))
+}
+
+func TestWebhookService_Receive_RedeliveryWhileHandled(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+	request := signedWebhook(github.EventPush, "72d3162e", `{"ref": "refs/heads/main"}`)
+	var redelivery *webhooks.DeliveryResult
+	service.Register(github.EventPush, "ci", webhooks.HandlerFunc(func(ctx context.Context, delivery webhooks.Delivery, event interface{}) error {
+		if redelivery == nil {
+			var err error
+			redelivery, err = service.Receive(ctx, request)
+			assert.Nil(t, err)
+		}
+		return nil
+	}))
+
+	res, err := service.Receive(context.Background(), request)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, webhooks.StatusSucceeded, res.Status)
+	assert.True(t, redelivery.Duplicate)
+	assert.EqualValues(t, webhooks.StatusReceived, redelivery.Status)
+}
+
+func TestWebhookService_RecoversInterruptedDeliveries(t *testing.T) {
+	store := delivery_store.NewMemoryDeliveryStore()
+	service := NewWebhookService(testWebhookSecret, store, webhooks.RetryPolicy{MaxAttempts: 3})
+	calls := 0
+	service.Register(github.EventPush, "ci", flakyHandler(0, &calls))
+	// logged by a process that stopped before running the handlers
+	for _, id := range []string{"72d3162e", "8a1b2c3d"} {
+		delivery, err := webhooks.NewDelivery(id, github.EventPush, []byte(`{"ref": "refs/heads/main"}`))
+		assert.Nil(t, err)
+		delivery.ScheduleRecovery(webhooks.RetryPolicy{})
+		_, err = store.Create(delivery)
+		assert.Nil(t, err)
+	}
+
+	res, err := service.Receive(context.Background(), signedWebhook(github.EventPush, "8a1b2c3d", `{"ref": "refs/heads/main"}`))
+	assert.Nil(t, err)
+	assert.True(t, res.Duplicate)
+	assert.EqualValues(t, 1, res.Handled)
+
+	retried, err := service.RetryDue(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, retried)
+	assert.EqualValues(t, 2, calls)
+	delivery, _ := service.GetDelivery(context.Background(), "72d3162e")
+	assert.EqualValues(t, webhooks.StatusSucceeded, delivery.Status)
+	assert.EqualValues(t, webhooks.TriggerRetry, delivery.Attempts[0].Trigger)
+}
+
+func TestWebhookService_RetryDue_DeadLetters(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+	calls := 0
+	service.Register(github.EventPush, "ci", flakyHandler(10, &calls))
+	_, err := service.Receive(context.Background(), signedWebhook(github.EventPush, "72d3162e", `{"ref": "refs/heads/main"}`))
+	assert.Nil(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := service.RetryDue(context.Background())
+		assert.Nil(t, err)
+	}
+
+	assert.EqualValues(t, 3, calls)
+	dead, err := service.ListDeliveries(context.Background(), webhooks.DeliveryFilter{Status: webhooks.StatusDeadLetter})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(dead))
+	assert.Nil(t, dead[0].Payload)
+	assert.Nil(t, dead[0].NextAttemptAt)
+}
+
+func TestWebhookService_Replay(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+	calls := 0
+	service.Register(github.EventPush, "ci", flakyHandler(0, &calls))
+	_, err := service.Receive(context.Background(), signedWebhook(github.EventPush, "72d3162e", `{"ref": "refs/heads/main"}`))
+	assert.Nil(t, err)
+
+	delivery, err := service.ReplayDelivery(context.Background(), "72d3162e")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, calls)
+	assert.EqualValues(t, webhooks.StatusSucceeded, delivery.Status)
+	assert.EqualValues(t, webhooks.TriggerReplay, delivery.Attempts[1].Trigger)
+
+	_, err = service.ReplayDelivery(context.Background(), "unknown")
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestWebhookService_Receive_RedeliveryOfFailed(t *testing.T) {
+	service := NewWebhookService(testWebhookSecret, delivery_store.NewMemoryDeliveryStore(), webhooks.RetryPolicy{MaxAttempts: 3, Backoff: time.Hour})
+	calls := 0
+	service.Register(github.EventPush, "ci", flakyHandler(1, &calls))
+	request := signedWebhook(github.EventPush, "72d3162e", `{"ref": "refs/heads/main"}`)
+	_, err := service.Receive(context.Background(), request)
+	assert.Nil(t, err)
+
+	retried, err := service.RetryDue(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, retried)
+
+	res, err := service.Receive(context.Background(), request)
+	assert.Nil(t, err)
+	assert.True(t, res.Duplicate)
+	assert.EqualValues(t, webhooks.StatusSucceeded, res.Status)
+	assert.EqualValues(t, 2, calls)
+}
+
+func TestWebhookService_ListDeliveries_InvalidFilter(t *testing.T) {
+	service := newTestWebhookService(testWebhookSecret)
+
+	for _, filter := range []webhooks.DeliveryFilter{{Status: "lost"}, {Limit: 1000}} {
+		res, err := service.ListDeliveries(context.Background(), filter)
+
+		assert.Nil(t, res)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	}
+}
+
+func TestManagedRepoHandler_ForgetsDeletedRepos(t *testing.T) {
+	managed := managed_store.NewMemoryManagedRepoStore()
+	assert.Nil(t, managed.Save(&drift.ManagedRepo{Owner: "my-org", Name: "my-repo"}))
+	handler := NewManagedRepoHandler(managed)
+	repo := github.EventRepo{Name: "my-repo", Owner: github.RepoOwner{Login: "my-org"}}
+
+	assert.Nil(t, handler.Handle(context.Background(), webhooks.Delivery{}, &github.RepositoryEvent{Action: "archived", Repository: repo}))
+	_, err := managed.Get("my-org", "my-repo")
+	assert.Nil(t, err)
+
+	assert.Nil(t, handler.Handle(context.Background(), webhooks.Delivery{}, &github.RepositoryEvent{Action: "deleted", Repository: repo}))
+	_, err = managed.Get("my-org", "my-repo")
+	assert.NotNil(t, err)
+}
+
+func withoutDurations(outcomes []webhooks.HandlerOutcome) []webhooks.HandlerOutcome {
+	result := make([]webhooks.HandlerOutcome, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		outcome.DurationMs = 0
+		result = append(result, outcome)
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package blueprint_store
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/config"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+)
+
+// BlueprintStore hands out the repository blueprints by name. Implementations must not
+// hand out the blueprints they hold.
+type BlueprintStore interface {
+	Get(name string) (*repositories.Blueprint, errors.ApiError)
+}
+
+type memoryBlueprintStore struct {
+	blueprints map[string]repositories.Blueprint
+}
+
+func NewMemoryBlueprintStore(blueprints map[string]repositories.Blueprint) BlueprintStore {
+	store := &memoryBlueprintStore{blueprints: make(map[string]repositories.Blueprint)}
+	for name, blueprint := range blueprints {
+		store.blueprints[name] = blueprint
+	}
+	return store
+}
+
+// LoadBlueprintStore reads the blueprints file at path, registering the protection
+// policies it declares before validating every blueprint.
+func LoadBlueprintStore(path string) (BlueprintStore, error) {
+	var file repositories.BlueprintsFile
+	if err := config.LoadFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	for name, policy := range file.ProtectionPolicies {
+		if err := repositories.RegisterProtectionPolicy(name, policy); err != nil {
+			return nil, fmt.Errorf("protection policy %s: %s", name, err.Message())
+		}
+	}
+	for name, blueprint := range file.Blueprints {
+		if err := blueprint.Validate(); err != nil {
+			return nil, fmt.Errorf("blueprint %s: %s", name, err.Message())
+		}
+	}
+
+	return NewMemoryBlueprintStore(file.Blueprints), nil
+}
+
+func (s *memoryBlueprintStore) Get(name string) (*repositories.Blueprint, errors.ApiError) {
+	blueprint, ok := s.blueprints[name]
+	if !ok {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Unknown blueprint %s", name))
+	}
+
+	blueprint = blueprint.Copy()
+	return &blueprint, nil
+}
@@ -0,0 +1,110 @@
+package blueprint_store
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, name string, content string) string {
+	dir, err := ioutil.TempDir("", "blueprints")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadBlueprintStore_Yaml(t *testing.T) {
+	path := writeFile(t, "blueprints.yaml", `
+protection_policies:
+  go-default:
+    required_reviews: 1
+    status_checks: [build]
+blueprints:
+  go-service:
+    visibility: private
+    auto_init: true
+    gitignore_template: Go
+    delete_branch_on_merge: true
+    protection_policy: go-default
+    topics: [go]
+    labels:
+      - name: tech-debt
+        color: fbca04
+    teams:
+      - team: platform
+        permission: maintain
+    files:
+      - path: .github/CODEOWNERS
+        content: "* @my-org/platform"
+`)
+
+	store, err := LoadBlueprintStore(path)
+
+	assert.Nil(t, err)
+	blueprint, apiErr := store.Get("go-service")
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, "Go", blueprint.GitignoreTemplate)
+	assert.True(t, *blueprint.DeleteBranchOnMerge)
+	assert.EqualValues(t, "maintain", blueprint.Teams[0].Permission)
+	assert.EqualValues(t, ".github/CODEOWNERS", blueprint.Files[0].Path)
+
+	policy, apiErr := repositories.GetProtectionPolicy("go-default")
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, []string{"build"}, policy.StatusChecks)
+}
+
+func TestLoadBlueprintStore_Json(t *testing.T) {
+	path := writeFile(t, "blueprints.json", `{"blueprints": {"docs": {"has_wiki": true, "topics": ["docs"]}}}`)
+
+	store, err := LoadBlueprintStore(path)
+
+	assert.Nil(t, err)
+	blueprint, apiErr := store.Get("docs")
+	assert.Nil(t, apiErr)
+	assert.True(t, blueprint.HasWiki)
+}
+
+func TestLoadBlueprintStore_InvalidBlueprint(t *testing.T) {
+	path := writeFile(t, "blueprints.yml", `
+blueprints:
+  broken:
+    topics: [Not-Valid]
+`)
+
+	store, err := LoadBlueprintStore(path)
+
+	assert.Nil(t, store)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "blueprint broken: Invalid topic Not-Valid", err.Error())
+}
+
+func TestLoadBlueprintStore_UnsupportedFormat(t *testing.T) {
+	path := writeFile(t, "blueprints.toml", ``)
+
+	store, err := LoadBlueprintStore(path)
+
+	assert.Nil(t, store)
+	assert.NotNil(t, err)
+}
+
+func TestMemoryBlueprintStore_Get(t *testing.T) {
+	store := NewMemoryBlueprintStore(map[string]repositories.Blueprint{"docs": {Topics: []string{"docs"}}})
+
+	blueprint, err := store.Get("docs")
+	assert.Nil(t, err)
+	blueprint.Topics[0] = "changed"
+
+	again, _ := store.Get("docs")
+	assert.EqualValues(t, "docs", again.Topics[0])
+
+	missing, err := store.Get("missing")
+	assert.Nil(t, missing)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
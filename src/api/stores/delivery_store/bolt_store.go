@@ -0,0 +1,273 @@
+package delivery_store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+const (
+	boltOpenTimeout = 5 * time.Second
+)
+
+var (
+	bucketDeliveries = []byte("deliveries")
+	bucketSummaries  = []byte("summaries")
+	bucketDue        = []byte("due")
+)
+
+// boltDeliveryStore keeps the deliveries in a bolt database file, as json keyed by
+// delivery id, so they survive restarts. Listing and pruning read the summaries bucket,
+// the deliveries without their payload, and the retries find the due deliveries through
+// the due bucket, keyed by next attempt time then id, so payloads are only decoded when
+// needed.
+type boltDeliveryStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltDeliveryStore opens, or creates, the database at path. It fails when another
+// process holds the file.
+func OpenBoltDeliveryStore(path string) (DeliveryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("error when trying to open delivery store %s: %s", path, err.Error())
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketDeliveries, bucketSummaries, bucketDue} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return buildIndexes(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error when trying to initialize delivery store %s: %s", path, err.Error())
+	}
+	return &boltDeliveryStore{db: db}, nil
+}
+
+// buildIndexes fills the summaries and due buckets of a database written before they
+// existed.
+func buildIndexes(tx *bolt.Tx) error {
+	if key, _ := tx.Bucket(bucketSummaries).Cursor().First(); key != nil {
+		return nil
+	}
+
+	var deliveries []webhooks.Delivery
+	err := tx.Bucket(bucketDeliveries).ForEach(func(key []byte, value []byte) error {
+		var delivery webhooks.Delivery
+		if err := json.Unmarshal(value, &delivery); err != nil {
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the bucket cannot change while ForEach runs
+	for i := range deliveries {
+		if err := put(tx, &deliveries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *boltDeliveryStore) Create(delivery *webhooks.Delivery) (bool, errors.ApiError) {
+	created := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketDeliveries).Get([]byte(delivery.Id)) != nil {
+			return nil
+		}
+		created = true
+		return put(tx, delivery)
+	})
+	if err != nil {
+		return false, storeError("create", err)
+	}
+	return created, nil
+}
+
+func (s *boltDeliveryStore) Save(delivery *webhooks.Delivery) errors.ApiError {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, delivery)
+	})
+	if err != nil {
+		return storeError("save", err)
+	}
+	return nil
+}
+
+func (s *boltDeliveryStore) Get(id string) (*webhooks.Delivery, errors.ApiError) {
+	var delivery *webhooks.Delivery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		delivery, err = get(tx, bucketDeliveries, []byte(id))
+		return err
+	})
+	if err != nil {
+		return nil, storeError("get", err)
+	}
+	if delivery == nil {
+		return nil, errors.NewNotFoundError("delivery not found")
+	}
+	return delivery, nil
+}
+
+// List returns the summaries, the payload of the deliveries being left out.
+func (s *boltDeliveryStore) List(filter webhooks.DeliveryFilter) ([]webhooks.Delivery, errors.ApiError) {
+	var result []webhooks.Delivery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSummaries).ForEach(func(key []byte, value []byte) error {
+			var delivery webhooks.Delivery
+			if err := json.Unmarshal(value, &delivery); err != nil {
+				return err
+			}
+			if filter.Matches(&delivery) {
+				result = append(result, delivery)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, storeError("list", err)
+	}
+	return latestFirst(result, filter.Limit), nil
+}
+
+func (s *boltDeliveryStore) Due(now time.Time) ([]webhooks.Delivery, errors.ApiError) {
+	var result []webhooks.Delivery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(bucketDue).Cursor()
+		for key, id := cursor.First(); key != nil && !timeOfDueKey(key).After(now); key, id = cursor.Next() {
+			delivery, err := get(tx, bucketDeliveries, id)
+			if err != nil {
+				return err
+			}
+			if delivery != nil && delivery.IsDue(now) {
+				result = append(result, *delivery)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, storeError("due", err)
+	}
+	return result, nil
+}
+
+func (s *boltDeliveryStore) Prune(before time.Time) (int, errors.ApiError) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var expired []webhooks.Delivery
+		err := tx.Bucket(bucketSummaries).ForEach(func(key []byte, value []byte) error {
+			var delivery webhooks.Delivery
+			if err := json.Unmarshal(value, &delivery); err != nil {
+				return err
+			}
+			if delivery.ReceivedAt.Before(before) {
+				expired = append(expired, delivery)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// the bucket cannot change while ForEach runs
+		for i := range expired {
+			if err := remove(tx, &expired[i]); err != nil {
+				return err
+			}
+		}
+		pruned = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, storeError("prune", err)
+	}
+	return pruned, nil
+}
+
+// put writes delivery along with its summary, moving its entry of the due bucket.
+func put(tx *bolt.Tx, delivery *webhooks.Delivery) error {
+	previous, err := get(tx, bucketSummaries, []byte(delivery.Id))
+	if err != nil {
+		return err
+	}
+	if previous != nil && previous.NextAttemptAt != nil {
+		if err := tx.Bucket(bucketDue).Delete(dueKey(previous)); err != nil {
+			return err
+		}
+	}
+
+	value, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketDeliveries).Put([]byte(delivery.Id), value); err != nil {
+		return err
+	}
+	summary := *delivery
+	summary.Payload = nil
+	if value, err = json.Marshal(summary); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketSummaries).Put([]byte(delivery.Id), value); err != nil {
+		return err
+	}
+	if delivery.NextAttemptAt == nil {
+		return nil
+	}
+	return tx.Bucket(bucketDue).Put(dueKey(delivery), []byte(delivery.Id))
+}
+
+func remove(tx *bolt.Tx, delivery *webhooks.Delivery) error {
+	if delivery.NextAttemptAt != nil {
+		if err := tx.Bucket(bucketDue).Delete(dueKey(delivery)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Bucket(bucketSummaries).Delete([]byte(delivery.Id)); err != nil {
+		return err
+	}
+	return tx.Bucket(bucketDeliveries).Delete([]byte(delivery.Id))
+}
+
+// get decodes the delivery stored under id in bucket, nil when there is none.
+func get(tx *bolt.Tx, bucket []byte, id []byte) (*webhooks.Delivery, error) {
+	value := tx.Bucket(bucket).Get(id)
+	if value == nil {
+		return nil, nil
+	}
+	var delivery webhooks.Delivery
+	if err := json.Unmarshal(value, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// dueKey sorts by next attempt time: the unix nanoseconds, big endian, then the id.
+func dueKey(delivery *webhooks.Delivery) []byte {
+	key := make([]byte, 8, 8+len(delivery.Id))
+	binary.BigEndian.PutUint64(key, uint64(delivery.NextAttemptAt.UnixNano()))
+	return append(key, delivery.Id...)
+}
+
+func timeOfDueKey(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8])))
+}
+
+func storeError(operation string, err error) errors.ApiError {
+	log.Error(fmt.Sprintf("error when trying to %s webhook delivery", operation), err)
+	return errors.NewInternalServerError("error when trying to access the delivery store")
+}
@@ -0,0 +1,119 @@
+package delivery_store
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeliveryStore is the log of the webhook deliveries received. Implementations must
+// not keep references to the deliveries they receive nor hand out the ones they hold.
+type DeliveryStore interface {
+	// Create records a new delivery, false telling one with the same id exists.
+	Create(delivery *webhooks.Delivery) (bool, errors.ApiError)
+	Save(delivery *webhooks.Delivery) errors.ApiError
+	Get(id string) (*webhooks.Delivery, errors.ApiError)
+	// List returns the deliveries matching filter, the latest received first. Their
+	// payload may be left out.
+	List(filter webhooks.DeliveryFilter) ([]webhooks.Delivery, errors.ApiError)
+	// Due returns the deliveries whose retry is due at now, see Delivery.IsDue.
+	Due(now time.Time) ([]webhooks.Delivery, errors.ApiError)
+	// Prune forgets the deliveries received before, returning how many were.
+	Prune(before time.Time) (int, errors.ApiError)
+}
+
+type memoryDeliveryStore struct {
+	mutex      sync.RWMutex
+	deliveries map[string]*webhooks.Delivery
+}
+
+func NewMemoryDeliveryStore() DeliveryStore {
+	return &memoryDeliveryStore{deliveries: make(map[string]*webhooks.Delivery)}
+}
+
+func (s *memoryDeliveryStore) Create(delivery *webhooks.Delivery) (bool, errors.ApiError) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.deliveries[delivery.Id]; exists {
+		return false, nil
+	}
+	s.deliveries[delivery.Id] = delivery.Copy()
+	return true, nil
+}
+
+func (s *memoryDeliveryStore) Save(delivery *webhooks.Delivery) errors.ApiError {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.deliveries[delivery.Id] = delivery.Copy()
+	return nil
+}
+
+func (s *memoryDeliveryStore) Get(id string) (*webhooks.Delivery, errors.ApiError) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	delivery := s.deliveries[id]
+	if delivery == nil {
+		return nil, errors.NewNotFoundError("delivery not found")
+	}
+	return delivery.Copy(), nil
+}
+
+func (s *memoryDeliveryStore) List(filter webhooks.DeliveryFilter) ([]webhooks.Delivery, errors.ApiError) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var result []webhooks.Delivery
+	for _, delivery := range s.deliveries {
+		if filter.Matches(delivery) {
+			result = append(result, *delivery.Copy())
+		}
+	}
+	return latestFirst(result, filter.Limit), nil
+}
+
+func (s *memoryDeliveryStore) Due(now time.Time) ([]webhooks.Delivery, errors.ApiError) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var result []webhooks.Delivery
+	for _, delivery := range s.deliveries {
+		if delivery.IsDue(now) {
+			result = append(result, *delivery.Copy())
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryDeliveryStore) Prune(before time.Time) (int, errors.ApiError) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pruned := 0
+	for id, delivery := range s.deliveries {
+		if delivery.ReceivedAt.Before(before) {
+			delete(s.deliveries, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// latestFirst sorts deliveries by reception, the latest first, keeping up to limit of
+// them when limit is positive.
+func latestFirst(deliveries []webhooks.Delivery, limit int) []webhooks.Delivery {
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].ReceivedAt.Equal(deliveries[j].ReceivedAt) {
+			return deliveries[i].Id < deliveries[j].Id
+		}
+		return deliveries[i].ReceivedAt.After(deliveries[j].ReceivedAt)
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries
+}
@@ -0,0 +1,181 @@
+package delivery_store
+
+import (
+	"encoding/json"
+	"github.com/dmolina79/golang-github-api/src/api/domain/webhooks"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// stores returns every implementation, the bolt one in a temporary file.
+func stores(t *testing.T) map[string]DeliveryStore {
+	dir, err := ioutil.TempDir("", "deliveries")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	boltStore, err := OpenBoltDeliveryStore(filepath.Join(dir, "deliveries.db"))
+	assert.Nil(t, err)
+	t.Cleanup(func() { boltStore.(*boltDeliveryStore).db.Close() })
+	return map[string]DeliveryStore{"memory": NewMemoryDeliveryStore(), "bolt": boltStore}
+}
+
+func TestDeliveryStore_CreateAndGet(t *testing.T) {
+	for name, store := range stores(t) {
+		delivery := &webhooks.Delivery{Id: "72d3162e", Event: "push", Status: webhooks.StatusReceived, Headers: map[string]string{"X-Github-Event": "push"}}
+
+		created, err := store.Create(delivery)
+		assert.Nil(t, err, name)
+		assert.True(t, created, name)
+		delivery.Headers["X-Github-Event"] = "changed"
+
+		created, err = store.Create(&webhooks.Delivery{Id: "72d3162e", Event: "ping"})
+		assert.Nil(t, err, name)
+		assert.False(t, created, name)
+
+		stored, err := store.Get("72d3162e")
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, "push", stored.Event, name)
+		assert.EqualValues(t, "push", stored.Headers["X-Github-Event"], name)
+
+		stored, err = store.Get("unknown")
+		assert.Nil(t, stored, name)
+		assert.EqualValues(t, http.StatusNotFound, err.Status(), name)
+	}
+}
+
+func TestDeliveryStore_ListDueAndPrune(t *testing.T) {
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+	for name, store := range stores(t) {
+		for _, delivery := range []*webhooks.Delivery{
+			{Id: "old", Event: "push", Status: webhooks.StatusSucceeded, ReceivedAt: now.Add(-48 * time.Hour)},
+			{Id: "due", Event: "push", Status: webhooks.StatusFailed, ReceivedAt: now.Add(-2 * time.Minute), NextAttemptAt: &past},
+			{Id: "later", Event: "pull_request", Status: webhooks.StatusFailed, ReceivedAt: now.Add(-time.Minute), NextAttemptAt: &future},
+			{Id: "dead", Event: "push", Status: webhooks.StatusDeadLetter, ReceivedAt: now},
+		} {
+			assert.Nil(t, store.Save(delivery), name)
+		}
+
+		listed, err := store.List(webhooks.DeliveryFilter{})
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, []string{"dead", "later", "due", "old"}, ids(listed), name)
+
+		listed, err = store.List(webhooks.DeliveryFilter{Status: webhooks.StatusFailed, Limit: 1})
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, []string{"later"}, ids(listed), name)
+
+		listed, err = store.List(webhooks.DeliveryFilter{Event: "push", Status: webhooks.StatusFailed})
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, []string{"due"}, ids(listed), name)
+
+		due, err := store.Due(now)
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, []string{"due"}, ids(due), name)
+
+		pruned, err := store.Prune(now.Add(-24 * time.Hour))
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, 1, pruned, name)
+		_, err = store.Get("old")
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestBoltDeliveryStore_SurvivesReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deliveries")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deliveries.db")
+	store, err := OpenBoltDeliveryStore(path)
+	assert.Nil(t, err)
+	_, apiErr := store.Create(&webhooks.Delivery{Id: "72d3162e", Event: "push", Payload: []byte(`{"ref":"refs/heads/main"}`)})
+	assert.Nil(t, apiErr)
+	assert.Nil(t, store.(*boltDeliveryStore).db.Close())
+
+	store, err = OpenBoltDeliveryStore(path)
+	assert.Nil(t, err)
+	defer store.(*boltDeliveryStore).db.Close()
+	delivery, apiErr := store.Get("72d3162e")
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, `{"ref":"refs/heads/main"}`, string(delivery.Payload))
+}
+
+func TestBoltDeliveryStore_DueFollowsSaves(t *testing.T) {
+	store := stores(t)["bolt"]
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	delivery := &webhooks.Delivery{Id: "72d3162e", Event: "push", Status: webhooks.StatusFailed, NextAttemptAt: &past, Payload: []byte(`{}`)}
+	assert.Nil(t, store.Save(delivery))
+
+	due, err := store.Due(now)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"72d3162e"}, ids(due))
+	assert.EqualValues(t, `{}`, string(due[0].Payload))
+
+	later := now.Add(time.Minute)
+	delivery.NextAttemptAt = &later
+	assert.Nil(t, store.Save(delivery))
+	due, err = store.Due(now)
+	assert.Nil(t, err)
+	assert.Empty(t, due)
+	due, err = store.Due(later)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"72d3162e"}, ids(due))
+
+	delivery.Status = webhooks.StatusSucceeded
+	delivery.NextAttemptAt = nil
+	assert.Nil(t, store.Save(delivery))
+	due, err = store.Due(later)
+	assert.Nil(t, err)
+	assert.Empty(t, due)
+
+	listed, err := store.List(webhooks.DeliveryFilter{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, webhooks.StatusSucceeded, listed[0].Status)
+	assert.Nil(t, listed[0].Payload)
+}
+
+func TestBoltDeliveryStore_IndexesExistingDeliveries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deliveries")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deliveries.db")
+	// a database written before the summaries and due buckets existed
+	db, err := bolt.Open(path, 0600, nil)
+	assert.Nil(t, err)
+	past := time.Now().UTC().Add(-time.Minute)
+	value, _ := json.Marshal(webhooks.Delivery{Id: "72d3162e", Event: "push", Status: webhooks.StatusFailed, NextAttemptAt: &past})
+	assert.Nil(t, db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketDeliveries)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte("72d3162e"), value)
+	}))
+	assert.Nil(t, db.Close())
+
+	store, err := OpenBoltDeliveryStore(path)
+	assert.Nil(t, err)
+	defer store.(*boltDeliveryStore).db.Close()
+
+	listed, apiErr := store.List(webhooks.DeliveryFilter{})
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, []string{"72d3162e"}, ids(listed))
+	due, apiErr := store.Due(time.Now().UTC())
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, []string{"72d3162e"}, ids(due))
+}
+
+func ids(deliveries []webhooks.Delivery) []string {
+	result := make([]string, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, delivery.Id)
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package job_store
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/jobs"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"sync"
+	"time"
+)
+
+// JobStore keeps batch jobs around while, and after, they run. Implementations must
+// not keep references to the jobs they receive nor hand out the ones they hold.
+type JobStore interface {
+	Save(job *jobs.Job) errors.ApiError
+	Get(id string) (*jobs.Job, errors.ApiError)
+	// Prune forgets the finished jobs last updated before, returning how many were.
+	Prune(before time.Time) (int, errors.ApiError)
+}
+
+type memoryJobStore struct {
+	mutex sync.RWMutex
+	jobs  map[string]*jobs.Job
+}
+
+func NewMemoryJobStore() JobStore {
+	return &memoryJobStore{jobs: make(map[string]*jobs.Job)}
+}
+
+func (s *memoryJobStore) Save(job *jobs.Job) errors.ApiError {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.jobs[job.Id] = job.Copy()
+	return nil
+}
+
+func (s *memoryJobStore) Get(id string) (*jobs.Job, errors.ApiError) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	job := s.jobs[id]
+	if job == nil {
+		return nil, errors.NewNotFoundError("job not found")
+	}
+	return job.Copy(), nil
+}
+
+func (s *memoryJobStore) Prune(before time.Time) (int, errors.ApiError) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pruned := 0
+	for id, job := range s.jobs {
+		if job.IsFinished() && job.UpdatedAt.Before(before) {
+			delete(s.jobs, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
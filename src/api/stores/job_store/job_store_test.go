@@ -0,0 +1,61 @@
+package job_store
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/jobs"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryJobStore_NotFound(t *testing.T) {
+	store := NewMemoryJobStore()
+
+	job, err := store.Get("missing")
+
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "job not found", err.Message())
+}
+
+func TestMemoryJobStore_SaveKeepsCopies(t *testing.T) {
+	store := NewMemoryJobStore()
+	job := &jobs.Job{Id: "abc", Status: jobs.StatusRunning, Total: 2}
+
+	assert.Nil(t, store.Save(job))
+	job.Status = jobs.StatusCompleted
+	job.AddResult(repositories.CreateReposResult{Index: 0})
+
+	stored, err := store.Get("abc")
+	assert.Nil(t, err)
+	assert.EqualValues(t, jobs.StatusRunning, stored.Status)
+	assert.EqualValues(t, 0, len(stored.Results))
+
+	stored.Status = jobs.StatusCancelled
+	again, _ := store.Get("abc")
+	assert.EqualValues(t, jobs.StatusRunning, again.Status)
+}
+
+func TestMemoryJobStore_PruneFinishedOnly(t *testing.T) {
+	store := NewMemoryJobStore()
+	now := time.Now().UTC()
+	store.Save(&jobs.Job{Id: "completed", Status: jobs.StatusCompleted, UpdatedAt: now.Add(-48 * time.Hour)})
+	store.Save(&jobs.Job{Id: "cancelled", Status: jobs.StatusCancelled, UpdatedAt: now.Add(-48 * time.Hour)})
+	store.Save(&jobs.Job{Id: "running", Status: jobs.StatusRunning, UpdatedAt: now.Add(-48 * time.Hour)})
+	store.Save(&jobs.Job{Id: "recent", Status: jobs.StatusCompleted, UpdatedAt: now})
+
+	pruned, err := store.Prune(now.Add(-24 * time.Hour))
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, pruned)
+	for _, id := range []string{"completed", "cancelled"} {
+		_, err := store.Get(id)
+		assert.EqualValues(t, http.StatusNotFound, err.Status(), id)
+	}
+	for _, id := range []string{"running", "recent"} {
+		_, err := store.Get(id)
+		assert.Nil(t, err, id)
+	}
+}
@@ -0,0 +1,113 @@
+package managed_store
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/log"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+const (
+	boltOpenTimeout = 5 * time.Second
+)
+
+var (
+	bucketManaged = []byte("managed_repos")
+)
+
+// boltManagedRepoStore keeps the managed repositories in a bolt database file, as json
+// keyed by lowercase owner/name, so drift detection survives restarts. Bolt iterates
+// the keys in order, List needs no sorting.
+type boltManagedRepoStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltManagedRepoStore opens, or creates, the database at path. It fails when
+// another process holds the file.
+func OpenBoltManagedRepoStore(path string) (ManagedRepoStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("error when trying to open managed repository store %s: %s", path, err.Error())
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketManaged)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error when trying to initialize managed repository store %s: %s", path, err.Error())
+	}
+	return &boltManagedRepoStore{db: db}, nil
+}
+
+func (s *boltManagedRepoStore) Save(repo *drift.ManagedRepo) errors.ApiError {
+	value, err := json.Marshal(repo.Copy())
+	if err != nil {
+		return storeError("save", err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketManaged).Put([]byte(repoKey(repo.Owner, repo.Name)), value)
+	})
+	if err != nil {
+		return storeError("save", err)
+	}
+	return nil
+}
+
+func (s *boltManagedRepoStore) Get(owner string, name string) (*drift.ManagedRepo, errors.ApiError) {
+	var repo *drift.ManagedRepo
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(bucketManaged).Get([]byte(repoKey(owner, name)))
+		if value == nil {
+			return nil
+		}
+		repo = &drift.ManagedRepo{}
+		return json.Unmarshal(value, repo)
+	})
+	if err != nil {
+		return nil, storeError("get", err)
+	}
+	if repo == nil {
+		return nil, errors.NewNotFoundError("managed repository not found")
+	}
+	return repo, nil
+}
+
+func (s *boltManagedRepoStore) Delete(owner string, name string) errors.ApiError {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketManaged).Delete([]byte(repoKey(owner, name)))
+	})
+	if err != nil {
+		return storeError("delete", err)
+	}
+	return nil
+}
+
+// List returns every managed repository sorted by owner and name.
+func (s *boltManagedRepoStore) List() ([]drift.ManagedRepo, errors.ApiError) {
+	repos := make([]drift.ManagedRepo, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketManaged).ForEach(func(key []byte, value []byte) error {
+			var repo drift.ManagedRepo
+			if err := json.Unmarshal(value, &repo); err != nil {
+				return err
+			}
+			repos = append(repos, repo)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, storeError("list", err)
+	}
+	return repos, nil
+}
+
+func storeError(operation string, err error) errors.ApiError {
+	log.Error(fmt.Sprintf("error when trying to %s managed repository", operation), err)
+	return errors.NewInternalServerError("error when trying to access the managed repository store")
+}
@@ -0,0 +1,75 @@
+package managed_store
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ManagedRepoStore keeps the desired state of the repositories managed through this api.
+// Owner and name are matched case insensitively, as github does. Implementations must
+// not keep references to the repositories they receive nor hand out the ones they hold.
+type ManagedRepoStore interface {
+	Save(repo *drift.ManagedRepo) errors.ApiError
+	Get(owner string, name string) (*drift.ManagedRepo, errors.ApiError)
+	Delete(owner string, name string) errors.ApiError
+	List() ([]drift.ManagedRepo, errors.ApiError)
+}
+
+type memoryManagedRepoStore struct {
+	mutex sync.RWMutex
+	repos map[string]*drift.ManagedRepo
+}
+
+func NewMemoryManagedRepoStore() ManagedRepoStore {
+	return &memoryManagedRepoStore{repos: make(map[string]*drift.ManagedRepo)}
+}
+
+func repoKey(owner string, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s", owner, name))
+}
+
+func (s *memoryManagedRepoStore) Save(repo *drift.ManagedRepo) errors.ApiError {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.repos[repoKey(repo.Owner, repo.Name)] = repo.Copy()
+	return nil
+}
+
+func (s *memoryManagedRepoStore) Get(owner string, name string) (*drift.ManagedRepo, errors.ApiError) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	repo := s.repos[repoKey(owner, name)]
+	if repo == nil {
+		return nil, errors.NewNotFoundError("managed repository not found")
+	}
+	return repo.Copy(), nil
+}
+
+func (s *memoryManagedRepoStore) Delete(owner string, name string) errors.ApiError {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.repos, repoKey(owner, name))
+	return nil
+}
+
+// List returns every managed repository sorted by owner and name.
+func (s *memoryManagedRepoStore) List() ([]drift.ManagedRepo, errors.ApiError) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	repos := make([]drift.ManagedRepo, 0, len(s.repos))
+	for _, repo := range s.repos {
+		repos = append(repos, *repo.Copy())
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return repoKey(repos[i].Owner, repos[i].Name) < repoKey(repos[j].Owner, repos[j].Name)
+	})
+	return repos, nil
+}
@@ -0,0 +1,90 @@
+package managed_store
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/drift"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stores returns every implementation, the bolt one in a temporary file.
+func stores(t *testing.T) map[string]ManagedRepoStore {
+	dir, err := ioutil.TempDir("", "managed")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	boltStore, err := OpenBoltManagedRepoStore(filepath.Join(dir, "managed.db"))
+	assert.Nil(t, err)
+	t.Cleanup(func() { boltStore.(*boltManagedRepoStore).db.Close() })
+	return map[string]ManagedRepoStore{"memory": NewMemoryManagedRepoStore(), "bolt": boltStore}
+}
+
+func TestManagedRepoStore_NotFound(t *testing.T) {
+	for name, store := range stores(t) {
+		repo, err := store.Get("dmolina79", "missing")
+
+		assert.Nil(t, repo, name)
+		assert.NotNil(t, err, name)
+		assert.EqualValues(t, http.StatusNotFound, err.Status(), name)
+	}
+}
+
+func TestManagedRepoStore_SaveKeepsCopies(t *testing.T) {
+	for name, store := range stores(t) {
+		repo := &drift.ManagedRepo{Owner: "dmolina79", Name: "My-Repo", Topics: []string{"go"}}
+
+		assert.Nil(t, store.Save(repo), name)
+		repo.Topics[0] = "changed"
+
+		stored, err := store.Get("DMOLINA79", "my-repo")
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, "My-Repo", stored.Name, name)
+		assert.EqualValues(t, []string{"go"}, stored.Topics, name)
+
+		stored.Topics[0] = "changed"
+		again, _ := store.Get("dmolina79", "My-Repo")
+		assert.EqualValues(t, []string{"go"}, again.Topics, name)
+	}
+}
+
+func TestManagedRepoStore_ListAndDelete(t *testing.T) {
+	for name, store := range stores(t) {
+		store.Save(&drift.ManagedRepo{Owner: "my-org", Name: "web"})
+		store.Save(&drift.ManagedRepo{Owner: "my-org", Name: "api"})
+
+		repos, err := store.List()
+		assert.Nil(t, err, name)
+		assert.EqualValues(t, 2, len(repos), name)
+		assert.EqualValues(t, "api", repos[0].Name, name)
+		assert.EqualValues(t, "web", repos[1].Name, name)
+		assert.EqualValues(t, []string{}, repos[0].Topics, name)
+
+		assert.Nil(t, store.Delete("my-org", "API"), name)
+		repos, _ = store.List()
+		assert.EqualValues(t, 1, len(repos), name)
+		assert.EqualValues(t, "web", repos[0].Name, name)
+	}
+}
+
+func TestBoltManagedRepoStore_SurvivesReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "managed")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "managed.db")
+	store, err := OpenBoltManagedRepoStore(path)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Save(&drift.ManagedRepo{Owner: "my-org", Name: "api", Visibility: "private", HasIssues: true, ProtectionPolicy: "reviewed"}))
+	assert.Nil(t, store.(*boltManagedRepoStore).db.Close())
+
+	store, err = OpenBoltManagedRepoStore(path)
+	assert.Nil(t, err)
+	defer store.(*boltManagedRepoStore).db.Close()
+	repo, apiErr := store.Get("my-org", "api")
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, "private", repo.Visibility)
+	assert.True(t, repo.HasIssues)
+	assert.EqualValues(t, "reviewed", repo.ProtectionPolicy)
+}
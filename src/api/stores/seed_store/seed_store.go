@@ -0,0 +1,66 @@
+package seed_store
+
+import (
+	"fmt"
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SeedTemplateStore hands out the files of the seed templates by name, unrendered.
+type SeedTemplateStore interface {
+	Get(name string) ([]repositories.SeedFile, errors.ApiError)
+}
+
+// dirSeedTemplateStore reads each template from a sub directory of dir, every file
+// found below it being part of the template.
+type dirSeedTemplateStore struct {
+	dir string
+}
+
+// NewDirSeedTemplateStore serves the templates in dir, none when dir is empty.
+func NewDirSeedTemplateStore(dir string) SeedTemplateStore {
+	return &dirSeedTemplateStore{dir: dir}
+}
+
+func (s *dirSeedTemplateStore) Get(name string) ([]repositories.SeedFile, errors.ApiError) {
+	if s.dir == "" {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Unknown seed template %s", name))
+	}
+
+	root := filepath.Join(s.dir, filepath.Base(name))
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Unknown seed template %s", name))
+	}
+
+	var files []repositories.SeedFile
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, repositories.SeedFile{
+			Path:       filepath.ToSlash(relative),
+			Content:    string(content),
+			Executable: info.Mode()&0111 != 0,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NewInternalServerError(fmt.Sprintf("error when trying to read seed template %s", name))
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
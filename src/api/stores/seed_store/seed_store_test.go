@@ -0,0 +1,54 @@
+package seed_store
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, files map[string]os.FileMode) string {
+	dir, err := ioutil.TempDir("", "seeds")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, mode := range files {
+		path := filepath.Join(dir, "go-service", filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(name), mode))
+	}
+	return dir
+}
+
+func TestDirSeedTemplateStore_Get(t *testing.T) {
+	dir := writeTemplate(t, map[string]os.FileMode{
+		"README.md":                 0600,
+		".github/workflows/ci.yaml": 0600,
+		"scripts/build.sh":          0700,
+	})
+
+	files, err := NewDirSeedTemplateStore(dir).Get("go-service")
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []repositories.SeedFile{
+		{Path: ".github/workflows/ci.yaml", Content: ".github/workflows/ci.yaml"},
+		{Path: "README.md", Content: "README.md"},
+		{Path: "scripts/build.sh", Content: "scripts/build.sh", Executable: true},
+	}, files)
+}
+
+func TestDirSeedTemplateStore_GetUnknown(t *testing.T) {
+	dir := writeTemplate(t, map[string]os.FileMode{"README.md": 0600})
+
+	for _, store := range []SeedTemplateStore{NewDirSeedTemplateStore(dir), NewDirSeedTemplateStore("")} {
+		files, err := store.Get("node-service")
+
+		assert.Nil(t, files)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "Unknown seed template node-service", err.Message())
+	}
+}
@@ -16,6 +16,7 @@ type apiError struct {
 	ErrStatus  int    `json:"status"`
 	ErrMessage string `json:"message"`
 	ErrError   string `json:"error,omitempty"`
+	ErrResetAt int64  `json:"reset_at,omitempty"`
 }
 
 func (a *apiError) Error() string {
@@ -63,3 +64,21 @@ func NewBadRequestError(m string) ApiError {
 		ErrMessage: m,
 	}
 }
+
+// ResetAt returns the unix time at which the client may try again, zero when err does
+// not tell.
+func ResetAt(err ApiError) int64 {
+	if apiErr, ok := err.(*apiError); ok {
+		return apiErr.ErrResetAt
+	}
+	return 0
+}
+
+// NewTooManyRequestsError carries the unix time at which the client may try again.
+func NewTooManyRequestsError(m string, resetAt int64) ApiError {
+	return &apiError{
+		ErrStatus:  http.StatusTooManyRequests,
+		ErrMessage: m,
+		ErrResetAt: resetAt,
+	}
+}
@@ -0,0 +1,25 @@
+package http_utils
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRetryAfter = "Retry-After"
+)
+
+// RespondError writes err as the json response. When err says when the client may try
+// again, the Retry-After header tells it too.
+func RespondError(c *gin.Context, err errors.ApiError) {
+	if resetAt := errors.ResetAt(err); resetAt > 0 {
+		seconds := resetAt - time.Now().Unix()
+		if seconds < 0 {
+			seconds = 0
+		}
+		c.Header(headerRetryAfter, strconv.FormatInt(seconds, 10))
+	}
+	c.JSON(err.Status(), err)
+}
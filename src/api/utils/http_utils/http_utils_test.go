@@ -0,0 +1,36 @@
+package http_utils
+
+import (
+	"github.com/dmolina79/golang-github-api/src/api/utils/errors"
+	"github.com/dmolina79/golang-github-api/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRespondError_RetryAfter(t *testing.T) {
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(httptest.NewRequest(http.MethodGet, "/rate_limit", nil), response)
+	resetAt := time.Now().Add(time.Minute).Unix()
+
+	RespondError(c, errors.NewTooManyRequestsError("github rate limit exhausted", resetAt))
+
+	assert.EqualValues(t, http.StatusTooManyRequests, response.Code)
+	seconds, err := strconv.Atoi(response.Header().Get("Retry-After"))
+	assert.Nil(t, err)
+	assert.InDelta(t, 60, seconds, 2)
+	assert.Contains(t, response.Body.String(), `"reset_at":`+strconv.FormatInt(resetAt, 10))
+}
+
+func TestRespondError_WithoutReset(t *testing.T) {
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockContext(httptest.NewRequest(http.MethodGet, "/rate_limit", nil), response)
+
+	RespondError(c, errors.NewBadRequestError("invalid json body"))
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, "", response.Header().Get("Retry-After"))
+}